@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sim "pandemica/internal/sim"
+)
+
+// hubStats summarizes outbound websocket traffic so operators can judge the
+// cost of the broadcast frequency.
+type hubStats struct {
+	Clients        int     `json:"clients"`
+	Observers      int     `json:"observers"`
+	BytesSent      uint64  `json:"bytes_sent"`
+	MessagesSent   uint64  `json:"messages_sent"`
+	Dropped        uint64  `json:"dropped"`
+	UptimeSeconds  float64 `json:"uptime_seconds"`
+	BytesPerSecond float64 `json:"bytes_per_second"`
+}
+
+func (h *controlHub) stats() hubStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	uptime := time.Since(h.started).Seconds()
+	stats := hubStats{
+		Clients:       len(h.clients),
+		BytesSent:     h.bytesSent,
+		MessagesSent:  h.messagesSent,
+		Dropped:       h.dropped,
+		UptimeSeconds: uptime,
+	}
+	for _, info := range h.clients {
+		if info.role == roleObserver {
+			stats.Observers++
+		}
+	}
+	if uptime > 0 {
+		stats.BytesPerSecond = float64(h.bytesSent) / uptime
+	}
+	return stats
+}
+
+// statsHandler serves GET /api/hub.
+func (h *controlHub) statsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, h.stats())
+	}
+}
+
+// stepHandler serves POST /api/step, advancing a paused simulation by exactly
+// one tick so test harnesses can drive the model deterministically. Like the
+// control socket, it needs the controller token when one is configured.
+func stepHandler(simulation *sim.Simulation, hub *controlHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !hub.requireController(w, r) {
+			return
+		}
+		if !simulation.Paused() {
+			http.Error(w, "simulation is free-running; pause it before stepping", http.StatusConflict)
+			return
+		}
+
+		state := simulation.Step()
+		hub.broadcastControl(state)
+		writeJSON(w, http.StatusOK, state)
+	}
+}
+
+// streamHandler serves GET /api/stream as Server-Sent Events: the current
+// snapshot first, then one JSON event per tick until the client disconnects.
+func streamHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		updates, unsubscribe := simulation.Subscribe(16)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		if err := writeEvent(w, simulation.Snapshot()); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case state, ok := <-updates:
+				if !ok {
+					return
+				}
+				if err := writeEvent(w, state); err != nil {
+					log.Printf("stream write error: %v", err)
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, state sim.Snapshot) error {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", payload)
+	return err
+}
+
+// influxHandler serves GET /api/influx: the latest snapshot as a single
+// InfluxDB line-protocol point, ready for Telegraf's http input.
+func influxHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, influxLine(simulation.Snapshot(), time.Now()))
+	}
+}
+
+// exportHandler serves GET /api/export.csv, the recorded history as a CSV
+// download for spreadsheets.
+func exportHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="pandemica.csv"`)
+		if err := simulation.ExportCSV(w); err != nil {
+			log.Printf("failed to export history: %v", err)
+		}
+	}
+}
+
+// hooksHandler serves GET /api/debug/hooks for diagnosing leaked listeners.
+func hooksHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, simulation.ActiveHooks())
+	}
+}
+
+// configHandler serves GET /api/config: the current parameters as a Config
+// that can be saved and passed back with -config.
+func configHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="pandemica-config.json"`)
+		writeJSON(w, http.StatusOK, simulation.Config())
+	}
+}
+
+// finalSizeHandler serves GET /api/finalsize with the analytic SIR estimate
+// of the fraction of the population that will eventually be infected.
+func finalSizeHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]float64{"final_size": simulation.FinalSizeEstimate()})
+	}
+}
+
+// historyHandler serves GET /api/history: the recorded snapshots as a JSON
+// array, oldest first. ?since=T keeps only snapshots after tick T and
+// ?limit=N keeps only the newest N. It answers 503 until the first tick.
+func historyHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		since, limit := -1, 0
+		if raw := query.Get("since"); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil {
+				http.Error(w, "since must be a tick number", http.StatusBadRequest)
+				return
+			}
+			since = value
+		}
+		if raw := query.Get("limit"); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value <= 0 {
+				http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+				return
+			}
+			limit = value
+		}
+
+		history := simulation.History()
+		if len(history) == 0 && simulation.Snapshot().Tick == 0 {
+			http.Error(w, "no ticks recorded yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		first := 0
+		for first < len(history) && history[first].Tick <= since {
+			first++
+		}
+		history = history[first:]
+		if limit > 0 && len(history) > limit {
+			history = history[len(history)-limit:]
+		}
+		writeJSON(w, http.StatusOK, history)
+	}
+}
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+func influxLine(state sim.Snapshot, at time.Time) string {
+	return fmt.Sprintf(
+		"pandemica,pathogen=%s infected=%di,deaths=%di,tick=%di,infection_probability=%g,death_probability=%g,capacity_utilization=%g,overloaded=%t %d",
+		influxTagEscaper.Replace(state.ActivePathogen),
+		state.CurrentInfected,
+		state.TotalDeaths,
+		state.Tick,
+		state.InfectionProbability,
+		state.EffectiveDeathProbability,
+		state.CapacityUtilization,
+		state.Overloaded,
+		at.UnixNano(),
+	)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// measurePopulationSystem is the FHIR code system for measure population
+// types. Every population here is a plain count, coded "measure-population".
+const measurePopulationSystem = "http://terminology.hl7.org/CodeSystem/measure-population"
+
+// measureReport is a minimal FHIR MeasureReport: just enough of the
+// group/population/count structure for health-informatics tooling to ingest
+// the simulation's aggregates. It makes no claim to full FHIR compliance.
+type measureReport struct {
+	ResourceType string         `json:"resourceType"`
+	Status       string         `json:"status"`
+	Type         string         `json:"type"`
+	Measure      string         `json:"measure"`
+	Date         string         `json:"date"`
+	Group        []measureGroup `json:"group"`
+}
+
+type measureGroup struct {
+	Code       measureCode         `json:"code"`
+	Population []measurePopulation `json:"population"`
+}
+
+type measurePopulation struct {
+	Code  measureCode `json:"code"`
+	Count int         `json:"count"`
+}
+
+type measureCode struct {
+	Coding []measureCoding `json:"coding,omitempty"`
+	Text   string          `json:"text"`
+}
+
+type measureCoding struct {
+	System string `json:"system"`
+	Code   string `json:"code"`
+}
+
+func population(code string, count int) measurePopulation {
+	return measurePopulation{
+		Code: measureCode{
+			Coding: []measureCoding{{System: measurePopulationSystem, Code: "measure-population"}},
+			Text:   code,
+		},
+		Count: count,
+	}
+}
+
+func measureReportFor(state sim.Snapshot, at time.Time) measureReport {
+	return measureReport{
+		ResourceType: "MeasureReport",
+		Status:       "complete",
+		Type:         "summary",
+		Measure:      "urn:pandemica:measure:epidemic-aggregates",
+		Date:         at.UTC().Format(time.RFC3339),
+		Group: []measureGroup{
+			{
+				Code: measureCode{Text: "pathogen:" + state.ActivePathogen},
+				Population: []measurePopulation{
+					population("currently-infected", state.CurrentInfected),
+					population("cumulative-infections", state.TotalInfections),
+					population("recovered", state.TotalRecoveries),
+					population("deaths", state.TotalDeaths),
+				},
+			},
+			{
+				Code: measureCode{Text: "hospital"},
+				Population: []measurePopulation{
+					population("capacity", state.HospitalCapacity),
+					population("over-capacity", max(state.CurrentInfected-state.HospitalCapacity, 0)),
+				},
+			},
+		},
+	}
+}
+
+// measureReportHandler serves GET /api/fhir/MeasureReport with the current
+// aggregates as a FHIR-style MeasureReport.
+func measureReportHandler(simulation *sim.Simulation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/fhir+json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(measureReportFor(simulation.Snapshot(), time.Now())); err != nil {
+			log.Printf("failed to encode measure report: %v", err)
+		}
+	}
+}
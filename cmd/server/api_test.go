@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	sim "pandemica/internal/sim"
+)
+
+func getHubStats(t *testing.T, hub *controlHub) hubStats {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	hub.statsHandler()(recorder, httptest.NewRequest(http.MethodGet, "/api/hub", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+
+	var stats hubStats
+	if err := json.NewDecoder(recorder.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode hub stats: %v", err)
+	}
+	return stats
+}
+
+func TestHubStatsCountBroadcastBytes(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	before := getHubStats(t, hub)
+	if before.Clients != 1 {
+		t.Fatalf("expected 1 connected client, got %d", before.Clients)
+	}
+
+	hub.broadcastControl(simulation.Snapshot())
+	hub.broadcastControl(simulation.Snapshot())
+	readControl(t, conn)
+	readControl(t, conn)
+
+	after := getHubStats(t, hub)
+	if after.BytesSent <= before.BytesSent {
+		t.Fatalf("expected bytes sent to grow past %d, got %d", before.BytesSent, after.BytesSent)
+	}
+	if after.MessagesSent < before.MessagesSent+2 {
+		t.Fatalf("expected at least %d messages, got %d", before.MessagesSent+2, after.MessagesSent)
+	}
+	if after.BytesPerSecond <= 0 {
+		t.Fatalf("expected a positive byte rate, got %v", after.BytesPerSecond)
+	}
+}
+
+func TestHubStatsRejectsNonGet(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newControlHub().statsHandler()(recorder, httptest.NewRequest(http.MethodPost, "/api/hub", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", recorder.Code)
+	}
+}
+
+func TestStepAdvancesPausedSimulationOneTick(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.Pause()
+	handler := stepHandler(simulation, newControlHub())
+
+	before := simulation.Snapshot().Tick
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/api/step", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var state sim.Snapshot
+	if err := json.NewDecoder(recorder.Body).Decode(&state); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if state.Tick != before+1 {
+		t.Fatalf("expected tick %d in response, got %d", before+1, state.Tick)
+	}
+	if got := simulation.Snapshot().Tick; got != before+1 {
+		t.Fatalf("expected simulation tick %d, got %d", before+1, got)
+	}
+}
+
+func TestStepRejectsFreeRunningSimulation(t *testing.T) {
+	simulation := sim.New(0.25)
+	handler := stepHandler(simulation, newControlHub())
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/api/step", nil))
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", recorder.Code)
+	}
+	if got := simulation.Snapshot().Tick; got != 0 {
+		t.Fatalf("expected tick to stay at 0, got %d", got)
+	}
+}
+
+func TestStepNeedsTheControllerToken(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.Pause()
+	hub := newControlHub()
+	hub.token = "s3cret"
+	hub.readOnlyToken = "peek"
+	handler := stepHandler(simulation, hub)
+
+	for name, tc := range map[string]struct {
+		header string
+		want   int
+	}{
+		"missing":   {want: http.StatusUnauthorized},
+		"wrong":     {header: "Bearer guess", want: http.StatusUnauthorized},
+		"read-only": {header: "Bearer peek", want: http.StatusForbidden},
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/api/step", nil)
+		if tc.header != "" {
+			request.Header.Set("Authorization", tc.header)
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		if recorder.Code != tc.want {
+			t.Fatalf("%s token: expected %d, got %d", name, tc.want, recorder.Code)
+		}
+	}
+	if got := simulation.Snapshot().Tick; got != 0 {
+		t.Fatalf("expected refused steps to leave tick 0, got %d", got)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/api/step?token=s3cret", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected the controller token to step, got %d", recorder.Code)
+	}
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) sim.Snapshot {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event stream: %v", err)
+		}
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		var state sim.Snapshot
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			t.Fatalf("decode event %q: %v", data, err)
+		}
+		return state
+	}
+}
+
+func TestStreamSendsSnapshotEvents(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(streamHandler(simulation))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer response.Body.Close()
+
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", got)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	if initial := readEvent(t, reader); initial.Tick != 0 {
+		t.Fatalf("expected the initial event at tick 0, got %d", initial.Tick)
+	}
+
+	simulation.Step()
+	simulation.Step()
+	for want := 1; want <= 2; want++ {
+		if got := readEvent(t, reader).Tick; got != want {
+			t.Fatalf("expected event for tick %d, got %d", want, got)
+		}
+	}
+}
+
+func TestInfluxLineFormat(t *testing.T) {
+	state := sim.Snapshot{
+		Tick:                      12,
+		ActivePathogen:            "novel strain",
+		CurrentInfected:           340,
+		TotalDeaths:               7,
+		InfectionProbability:      0.25,
+		EffectiveDeathProbability: 0.02,
+		CapacityUtilization:       1.5,
+		Overloaded:                true,
+	}
+
+	got := influxLine(state, time.Unix(0, 1700000000000000000))
+	want := `pandemica,pathogen=novel\ strain infected=340i,deaths=7i,tick=12i,infection_probability=0.25,death_probability=0.02,capacity_utilization=1.5,overloaded=true 1700000000000000000`
+	if got != want {
+		t.Fatalf("unexpected line protocol\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestInfluxHandlerServesLineProtocol(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.Step()
+
+	recorder := httptest.NewRecorder()
+	influxHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, "/api/influx", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+
+	line := regexp.MustCompile(`^pandemica,pathogen=default infected=\d+i,deaths=\d+i,tick=1i,infection_probability=[0-9.e-]+,death_probability=[0-9.e-]+,capacity_utilization=[0-9.e-]+,overloaded=(true|false) \d+\n$`)
+	if body := recorder.Body.String(); !line.MatchString(body) {
+		t.Fatalf("response is not valid line protocol: %q", body)
+	}
+}
+
+func TestHooksHandlerReportsSubscribers(t *testing.T) {
+	simulation := sim.New(0.25)
+	_, unsubscribe := simulation.Subscribe(1)
+	defer unsubscribe()
+
+	recorder := httptest.NewRecorder()
+	hooksHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, "/api/debug/hooks", nil))
+
+	var hooks sim.HookStatus
+	if err := json.NewDecoder(recorder.Body).Decode(&hooks); err != nil {
+		t.Fatalf("decode hook status: %v", err)
+	}
+	if hooks.Subscribers != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", hooks.Subscribers)
+	}
+}
+
+func TestFinalSizeHandlerReportsEstimate(t *testing.T) {
+	simulation := sim.New(0.25)
+
+	recorder := httptest.NewRecorder()
+	finalSizeHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, "/api/finalsize", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+
+	var body struct {
+		FinalSize float64 `json:"final_size"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode final size: %v", err)
+	}
+	if want := simulation.FinalSizeEstimate(); body.FinalSize != want || want <= 0 {
+		t.Fatalf("expected positive final size %v, got %v", want, body.FinalSize)
+	}
+}
+
+func TestMeasureReportHandlerStructure(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.SetHospitalCapacity(5)
+	simulation.Step()
+	state := simulation.Snapshot()
+
+	recorder := httptest.NewRecorder()
+	measureReportHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, "/api/fhir/MeasureReport", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/fhir+json" {
+		t.Fatalf("expected FHIR content type, got %q", got)
+	}
+
+	var report struct {
+		ResourceType string `json:"resourceType"`
+		Status       string `json:"status"`
+		Group        []struct {
+			Population []struct {
+				Code struct {
+					Text string `json:"text"`
+				} `json:"code"`
+				Count int `json:"count"`
+			} `json:"population"`
+		} `json:"group"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+		t.Fatalf("decode measure report: %v", err)
+	}
+	if report.ResourceType != "MeasureReport" || report.Status != "complete" || len(report.Group) != 2 {
+		t.Fatalf("unexpected report envelope: %+v", report)
+	}
+
+	counts := map[string]int{}
+	for _, group := range report.Group {
+		for _, population := range group.Population {
+			counts[population.Code.Text] = population.Count
+		}
+	}
+	want := map[string]int{
+		"currently-infected":    state.CurrentInfected,
+		"cumulative-infections": state.TotalInfections,
+		"recovered":             state.TotalRecoveries,
+		"deaths":                state.TotalDeaths,
+		"capacity":              5,
+		"over-capacity":         max(state.CurrentInfected-5, 0),
+	}
+	for code, count := range want {
+		if got, ok := counts[code]; !ok || got != count {
+			t.Fatalf("expected population %q = %d, got %d (present=%t)", code, count, got, ok)
+		}
+	}
+}
+
+func TestConfigHandlerRoundTrips(t *testing.T) {
+	simulation := sim.New(0.25)
+	if err := simulation.LoadScenario("measles-outbreak"); err != nil {
+		t.Fatalf("load scenario: %v", err)
+	}
+	simulation.SetHospitalCapacity(75)
+	simulation.Step()
+
+	recorder := httptest.NewRecorder()
+	configHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, recorder.Body.Bytes(), 0o644); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cfg, err := sim.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	reloaded, err := sim.NewFromConfig(*cfg)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+
+	if got, want := reloaded.Config(), simulation.Config(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("reloaded config differs:\n got %+v\nwant %+v", got, want)
+	}
+	// The config restarts the experiment rather than resuming the run.
+	simulation.Reset()
+	got, want := reloaded.Snapshot(), simulation.Snapshot()
+	if got.InfectionProbability != want.InfectionProbability || got.CurrentInfected != want.CurrentInfected ||
+		got.HospitalCapacity != want.HospitalCapacity || got.ActivePathogen != want.ActivePathogen {
+		t.Fatalf("reloaded simulation differs:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestHistoryHandlerFiltersSnapshots(t *testing.T) {
+	simulation := sim.New(0.25)
+	get := func(target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		historyHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	if recorder := get("/api/history"); recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before the first tick, got %d", recorder.Code)
+	}
+
+	for i := 0; i < 6; i++ {
+		simulation.Step()
+	}
+	ticks := func(target string) []int {
+		t.Helper()
+		recorder := get(target)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", target, recorder.Code)
+		}
+		var history []sim.Snapshot
+		if err := json.NewDecoder(recorder.Body).Decode(&history); err != nil {
+			t.Fatalf("decode history: %v", err)
+		}
+		var ticks []int
+		for _, state := range history {
+			ticks = append(ticks, state.Tick)
+		}
+		return ticks
+	}
+
+	for target, want := range map[string][]int{
+		"/api/history":                 {1, 2, 3, 4, 5, 6},
+		"/api/history?since=3":         {4, 5, 6},
+		"/api/history?limit=2":         {5, 6},
+		"/api/history?since=1&limit=3": {4, 5, 6},
+		"/api/history?since=6":         nil,
+	} {
+		if got := ticks(target); !reflect.DeepEqual(got, want) {
+			t.Errorf("GET %s: expected ticks %v, got %v", target, want, got)
+		}
+	}
+
+	if recorder := get("/api/history?limit=0"); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a zero limit, got %d", recorder.Code)
+	}
+}
+
+func TestExportHandlerServesCSV(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.Step()
+	simulation.Step()
+
+	recorder := httptest.NewRecorder()
+	exportHandler(simulation)(recorder, httptest.NewRequest(http.MethodGet, "/api/export.csv", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Fatalf("expected a CSV content type, got %q", got)
+	}
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "tick,") || !strings.HasPrefix(lines[2], "2,") {
+		t.Fatalf("expected a header and rows for ticks 1 and 2, got %q", lines)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+
+	"google.golang.org/protobuf/proto"
+	pb "pandemica/proto"
+)
+
+// stateDelta returns the top-level fields of next that differ from prev. A
+// changed message field, such as settings, is sent whole.
+func stateDelta(prev, next *pb.ControlState) *pb.ControlDelta {
+	delta := &pb.ControlDelta{Values: &pb.ControlState{}}
+	before, after, values := prev.ProtoReflect(), next.ProtoReflect(), delta.Values.ProtoReflect()
+	fields := after.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if before.Has(field) == after.Has(field) && before.Get(field).Equal(after.Get(field)) {
+			continue
+		}
+		delta.ChangedFields = append(delta.ChangedFields, string(field.Name()))
+		if after.Has(field) {
+			values.Set(field, after.Get(field))
+		}
+	}
+	return delta
+}
+
+// marshalDelta encodes the delta from prev to next as a control message, or
+// returns nil if it cannot, in which case the full state is sent instead.
+func marshalDelta(prev, next *pb.ControlState) []byte {
+	message := &pb.ControlMessage{Control: &pb.ControlMessage_Delta{Delta: stateDelta(prev, next)}}
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		log.Printf("failed to marshal control delta: %v", err)
+		return nil
+	}
+	return payload
+}
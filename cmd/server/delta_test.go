@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	sim "pandemica/internal/sim"
+	pb "pandemica/proto"
+)
+
+// applyDelta patches base with delta in place, as clients do.
+func applyDelta(base *pb.ControlState, delta *pb.ControlDelta) {
+	target, values := base.ProtoReflect(), delta.GetValues().ProtoReflect()
+	fields := target.Descriptor().Fields()
+	for _, name := range delta.GetChangedFields() {
+		field := fields.ByTextName(name)
+		if field == nil {
+			continue
+		}
+		if values.Has(field) {
+			target.Set(field, values.Get(field))
+		} else {
+			target.Clear(field)
+		}
+	}
+}
+
+func TestStateDeltaRoundTrips(t *testing.T) {
+	simulation := sim.NewWithSeed(0.25, 3)
+	prev := snapshotToProto(simulation.Snapshot())
+	simulation.Pause()
+	simulation.Step()
+	next := snapshotToProto(simulation.Snapshot())
+
+	delta := stateDelta(prev, next)
+	changed := map[string]bool{}
+	for _, name := range delta.GetChangedFields() {
+		changed[name] = true
+	}
+	if !changed["tick"] || !changed["paused"] {
+		t.Fatalf("expected tick and paused to change, got %v", delta.GetChangedFields())
+	}
+	if changed["active_pathogen"] || changed["settings"] {
+		t.Fatalf("expected unchanged fields to be left out, got %v", delta.GetChangedFields())
+	}
+
+	patched := proto.Clone(prev).(*pb.ControlState)
+	applyDelta(patched, delta)
+	if !proto.Equal(patched, next) {
+		t.Fatalf("expected the patched state to match\n got %v\nwant %v", patched, next)
+	}
+}
+
+func TestStateDeltaCarriesFieldsResetToDefault(t *testing.T) {
+	prev := &pb.ControlState{Tick: 4, Overloaded: true, CurrentInfected: 30}
+	next := &pb.ControlState{Tick: 5, CurrentInfected: 30}
+
+	delta := stateDelta(prev, next)
+	patched := proto.Clone(prev).(*pb.ControlState)
+	applyDelta(patched, delta)
+	if patched.GetOverloaded() || patched.GetTick() != 5 || patched.GetCurrentInfected() != 30 {
+		t.Fatalf("expected overloaded to clear and tick to advance, got %v", patched)
+	}
+}
@@ -2,10 +2,20 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,15 +24,84 @@ import (
 	pb "pandemica/proto"
 )
 
+// demoPathogens are registered alongside the default profile so the UI can
+// flip between contrasting diseases live.
+var demoPathogens = map[string]sim.Profile{
+	"flu":     {BaseTransmission: 0.2, BaseDeathRate: 0.005, InfectiousPeriod: 7},
+	"measles": {BaseTransmission: 0.9, BaseDeathRate: 0.01, InfectiousPeriod: 10},
+}
+
+// clientRole is what a connection may do, fixed when it is upgraded.
+type clientRole int
+
+const (
+	// roleController may send any control message.
+	roleController clientRole = iota
+	// roleObserver receives every state broadcast and may query events,
+	// aggregates, and the random state, but cannot change the simulation.
+	roleObserver
+)
+
+// clientInfo is what the hub knows about a connection.
+type clientInfo struct {
+	role clientRole
+	out  *outbox
+}
+
 type controlHub struct {
 	mu       sync.Mutex
-	clients  map[*websocket.Conn]struct{}
+	clients  map[*websocket.Conn]clientInfo
 	upgrader websocket.Upgrader
+
+	// Outbound traffic counters, guarded by mu.
+	started      time.Time
+	bytesSent    uint64
+	messagesSent uint64
+	dropped      uint64
+
+	// maxConns caps concurrent websocket connections; zero means unlimited.
+	maxConns int64
+	conns    atomic.Int64
+
+	// lastGeneration and lastTick identify the newest state broadcast so
+	// far, guarded by mu.
+	lastGeneration int
+	lastTick       int
+
+	// closed is set once closeAll has run, guarded by mu; later connections
+	// are turned away.
+	closed bool
+
+	// disableReset rejects ControlReset requests.
+	disableReset bool
+
+	// keyframeTicks is how many ticks a client may be sent deltas before it
+	// gets a full state again; zero broadcasts full states only.
+	keyframeTicks int
+
+	// metrics, when set, follows broadcast states and counts applied
+	// control messages.
+	metrics *metrics
+
+	// pingInterval is how often each connection is pinged; a client that
+	// has not answered within two intervals is dropped. Zero disables
+	// keepalive.
+	pingInterval time.Duration
+
+	// updateRate caps the messages per second each connection may send to
+	// change the simulation; zero means unlimited.
+	updateRate float64
+
+	// token, when set, must be presented to connect. readOnlyToken admits
+	// clients as observers.
+	token         string
+	readOnlyToken string
 }
 
 func newControlHub() *controlHub {
 	return &controlHub{
-		clients: make(map[*websocket.Conn]struct{}),
+		started: time.Now(),
+		clients: make(map[*websocket.Conn]clientInfo),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -33,10 +112,78 @@ func newControlHub() *controlHub {
 	}
 }
 
-func (h *controlHub) add(conn *websocket.Conn) {
+// acquire reserves a connection slot, reporting false when the hub is full.
+func (h *controlHub) acquire() bool {
+	if n := h.conns.Add(1); h.maxConns > 0 && n > h.maxConns {
+		h.conns.Add(-1)
+		return false
+	}
+	return true
+}
+
+func (h *controlHub) release() {
+	h.conns.Add(-1)
+}
+
+// authorize checks the token a client presented, as an Authorization bearer
+// header or a token query parameter, and returns the role it grants. Without
+// a configured token every client is a controller.
+func (h *controlHub) authorize(r *http.Request) (clientRole, bool) {
+	if h.token == "" {
+		return roleController, true
+	}
+	presented := r.URL.Query().Get("token")
+	if bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		presented = bearer
+	}
+	switch {
+	case tokenMatches(presented, h.token):
+		return roleController, true
+	case h.readOnlyToken != "" && tokenMatches(presented, h.readOnlyToken):
+		return roleObserver, true
+	}
+	return roleController, false
+}
+
+// requireController authorizes an HTTP request that changes the simulation,
+// answering 401 without a valid token and 403 for observers, so the HTTP API
+// grants no more than the control socket. It reports whether the request may
+// proceed.
+func (h *controlHub) requireController(w http.ResponseWriter, r *http.Request) bool {
+	role, ok := h.authorize(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if role == roleObserver {
+		http.Error(w, "observers cannot change the simulation", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// tokenMatches compares tokens in constant time so a client cannot guess a
+// token from response timings.
+func tokenMatches(presented, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
+
+func (h *controlHub) clientCount() int {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.clients[conn] = struct{}{}
+	return len(h.clients)
+}
+
+// add registers a connection, reporting false once the hub has closed.
+func (h *controlHub) add(conn *websocket.Conn, info clientInfo) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.clients[conn] = info
+	return true
 }
 
 func (h *controlHub) remove(conn *websocket.Conn) {
@@ -46,34 +193,137 @@ func (h *controlHub) remove(conn *websocket.Conn) {
 	conn.Close()
 }
 
+// keepAlive pings conn until done is closed. Each pong extends the read
+// deadline, so the read loop fails, and the connection is removed, once a
+// client stops answering.
+func (h *controlHub) keepAlive(conn *websocket.Conn, done <-chan struct{}) {
+	wait := 2 * h.pingInterval
+	conn.SetReadDeadline(time.Now().Add(wait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wait))
+	})
+
+	go func() {
+		ticker := time.NewTicker(h.pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.pingInterval)); err != nil {
+					log.Printf("failed to ping client: %v", err)
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// closeAll closes every connection with a close frame and turns away new
+// ones, for a server that is shutting down.
+func (h *controlHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for conn := range h.clients {
+		closeGoingAway(conn)
+		delete(h.clients, conn)
+	}
+}
+
+// closeGoingAway tells the client the server is going away, so it sees an
+// orderly close rather than a reset, and closes the connection.
+func closeGoingAway(conn *websocket.Conn) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	if err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+		log.Printf("failed to send close frame: %v", err)
+	}
+	conn.Close()
+}
+
+// broadcastControl queues state for every client. Broadcasts come from both
+// the Run loop and control handlers; all of them pass through mu and are
+// queued in tick order, so every client sees the same non-decreasing
+// sequence of ticks. A state older than one already broadcast is dropped:
+// the newer tick already carries its settings.
+//
+// Queuing never blocks. A client whose outbox is full misses the broadcast
+// and catches up with the next one. With keyframeTicks set, a client whose
+// last full state is recent enough is sent a ControlDelta against the state
+// it last received instead.
 func (h *controlHub) broadcastControl(state sim.Snapshot) {
-	payload, err := proto.Marshal(stateMessage(state))
+	message := stateMessage(state)
+	payload, err := proto.Marshal(message)
 	if err != nil {
 		log.Printf("failed to marshal control update: %v", err)
 		return
 	}
+	queued := outgoing{
+		payload:   payload,
+		frame:     &frame{generation: state.Generation, tick: state.Tick, full: message.GetState()},
+		broadcast: true,
+	}
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	for conn := range h.clients {
-		if err := conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
-			log.Printf("failed to write to client: %v", err)
-			conn.Close()
-			delete(h.clients, conn)
+	if state.Generation < h.lastGeneration || (state.Generation == h.lastGeneration && state.Tick < h.lastTick) {
+		return
+	}
+	h.lastGeneration, h.lastTick = state.Generation, state.Tick
+	h.metrics.observe(state)
+
+	for conn, info := range h.clients {
+		select {
+		case info.out.queue <- queued:
+		default:
+			info.out.dropped++
+			h.dropped++
+			// Log the first drop and then every hundredth, so a stalled
+			// client does not flood the log at high tick rates.
+			if info.out.dropped == 1 || info.out.dropped%100 == 0 {
+				log.Printf("client %v is falling behind: dropped %d broadcasts", conn.RemoteAddr(), info.out.dropped)
+			}
 		}
 	}
 }
 
 func (h *controlHub) handler(simulation *sim.Simulation) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		role, ok := h.authorize(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !h.acquire() {
+			http.Error(w, "too many connections", http.StatusServiceUnavailable)
+			return
+		}
+		defer h.release()
+
 		conn, err := h.upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Printf("websocket upgrade failed: %v", err)
 			return
 		}
-		h.add(conn)
+		out := newOutbox(conn)
+		if !h.add(conn, clientInfo{role: role, out: out}) {
+			closeGoingAway(conn)
+			return
+		}
+		go h.writeLoop(out)
+		defer close(out.quit)
 		defer h.remove(conn)
+		if h.pingInterval > 0 {
+			done := make(chan struct{})
+			defer close(done)
+			h.keepAlive(conn, done)
+		}
+		limiter := newTokenBucket(h.updateRate, time.Now())
 
 		// Send the current control state immediately.
 		h.sendState(conn, simulation.Snapshot())
@@ -91,6 +341,16 @@ func (h *controlHub) handler(simulation *sim.Simulation) http.HandlerFunc {
 				h.sendError(conn, "invalid control payload")
 				continue
 			}
+			if mutates(&message) {
+				if role == roleObserver {
+					h.sendError(conn, "observers cannot change the simulation")
+					continue
+				}
+				if !limiter.allow(time.Now()) {
+					h.sendError(conn, "rate limit exceeded")
+					continue
+				}
+			}
 
 			switch m := message.Control.(type) {
 			case *pb.ControlMessage_Update:
@@ -98,15 +358,113 @@ func (h *controlHub) handler(simulation *sim.Simulation) http.HandlerFunc {
 				settings := sim.ControlSettings{
 					TransmissionModifier: m.Update.GetTransmissionRate(),
 					LockdownEnabled:      m.Update.GetLockdownEnabled(),
+					InteractionVariance:  m.Update.InteractionVariance,
+					ExpectedVersion:      m.Update.ExpectedVersion,
+					ImportRate:           m.Update.ImportRate,
+					InfectionRadius:      m.Update.InfectionRadius,
+				}
+				if m.Update.Population != nil {
+					population := int(m.Update.GetPopulation())
+					settings.Population = &population
+				}
+				if m.Update.TickIntervalMs != nil {
+					interval := time.Duration(m.Update.GetTickIntervalMs()) * time.Millisecond
+					settings.TickInterval = &interval
 				}
 				if hospital != nil {
 					settings.HospitalCapacity = int(hospital.GetCapacity())
 					settings.DeathRateOverloadMultiplier = hospital.GetDeathRateOverloadMultiplier()
 				}
 
-				state := simulation.ApplyControlSettings(settings)
+				state, warnings, err := simulation.ApplyControlSettings(settings)
+				if err != nil {
+					h.sendError(conn, err.Error(), fieldErrors(err)...)
+					continue
+				}
+				h.sendAck(conn, state, clampWarnings(warnings)...)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_SelectPathogen:
+				if err := simulation.SetActivePathogen(m.SelectPathogen.GetName()); err != nil {
+					h.sendError(conn, err.Error())
+					continue
+				}
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_LoadScenario:
+				if err := simulation.LoadScenario(m.LoadScenario.GetName()); err != nil {
+					h.sendError(conn, err.Error())
+					continue
+				}
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_LoadConfig:
+				cfg, err := sim.DecodeConfig(strings.NewReader(m.LoadConfig.GetConfigJson()))
+				if err != nil {
+					h.sendError(conn, fmt.Sprintf("invalid config: %v", err))
+					continue
+				}
+				if err := simulation.ApplyConfig(cfg); err != nil {
+					h.sendError(conn, err.Error())
+					continue
+				}
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_EventsSince:
+				events := simulation.EventsSince(int(m.EventsSince.GetTick()))
+				if err := h.writeMessage(conn, eventsMessage(events)); err != nil {
+					log.Printf("failed to send events: %v", err)
+				}
+			case *pb.ControlMessage_ClearHistory:
+				simulation.ClearHistory()
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_Pause:
+				if m.Pause.GetPaused() {
+					simulation.Pause()
+				} else {
+					simulation.Resume()
+				}
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_Vaccination:
+				if m.Vaccination.Efficacy != nil {
+					simulation.SetVaccineEfficacy(m.Vaccination.GetEfficacy())
+				}
+				simulation.StartVaccination(int(m.Vaccination.GetDosesPerTick()))
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_IntroduceVariant:
+				if err := introduceVariant(simulation, m.IntroduceVariant); err != nil {
+					h.sendError(conn, err.Error())
+					continue
+				}
+				state := simulation.Snapshot()
+				h.sendAck(conn, state)
+				h.broadcastControl(state)
+			case *pb.ControlMessage_Reset_:
+				if h.disableReset {
+					h.sendError(conn, "reset is disabled on this server")
+					continue
+				}
+				simulation.Reset()
+				state := simulation.Snapshot()
 				h.sendAck(conn, state)
 				h.broadcastControl(state)
+			case *pb.ControlMessage_Aggregate:
+				aggregates := simulation.Aggregate(int(m.Aggregate.GetWindow()))
+				if err := h.writeMessage(conn, aggregatesMessage(aggregates)); err != nil {
+					log.Printf("failed to send aggregates: %v", err)
+				}
+			case *pb.ControlMessage_RandState:
+				if err := h.writeMessage(conn, randStateMessage(simulation.RandState())); err != nil {
+					log.Printf("failed to send rand state: %v", err)
+				}
 			default:
 				h.sendError(conn, "unsupported control message type")
 			}
@@ -115,41 +473,169 @@ func (h *controlHub) handler(simulation *sim.Simulation) http.HandlerFunc {
 }
 
 func (h *controlHub) sendState(conn *websocket.Conn, state sim.Snapshot) {
-	if err := h.writeMessage(conn, stateMessage(state)); err != nil {
+	message := stateMessage(state)
+	if err := h.send(conn, message, state); err != nil {
 		log.Printf("failed to send control state: %v", err)
 	}
 }
 
-func (h *controlHub) sendAck(conn *websocket.Conn, state sim.Snapshot) {
+// sendAck confirms an applied control message to conn, counting it in the
+// metrics.
+func (h *controlHub) sendAck(conn *websocket.Conn, state sim.Snapshot, warnings ...*pb.ControlClampWarning) {
+	h.metrics.controlUpdateApplied()
 	ack := &pb.ControlMessage{
 		Control: &pb.ControlMessage_Ack{
-			Ack: &pb.ControlAck{Message: "applied control update", State: stateMessage(state).GetState()},
+			Ack: &pb.ControlAck{Message: "applied control update", State: stateMessage(state).GetState(), Warnings: warnings},
 		},
 	}
-	if err := h.writeMessage(conn, ack); err != nil {
+	if err := h.send(conn, ack, state); err != nil {
 		log.Printf("failed to send control ack: %v", err)
 	}
 }
 
-func (h *controlHub) sendError(conn *websocket.Conn, message string) {
-	errMsg := &pb.ControlMessage{Control: &pb.ControlMessage_Error{Error: &pb.ControlError{Message: message}}}
+func (h *controlHub) sendError(conn *websocket.Conn, message string, fields ...*pb.ControlFieldError) {
+	errMsg := &pb.ControlMessage{Control: &pb.ControlMessage_Error{Error: &pb.ControlError{Message: message, Fields: fields}}}
 	if err := h.writeMessage(conn, errMsg); err != nil {
 		log.Printf("failed to send control error: %v", err)
 	}
 }
 
+// errClientGone is returned when a message is sent to a connection that has
+// already closed.
+var errClientGone = errors.New("client connection closed")
+
+// writeMessage queues message for conn, waiting for room in its outbox.
+// Only a connection's own handler sends it replies, so waiting slows nobody
+// else down.
 func (h *controlHub) writeMessage(conn *websocket.Conn, message *pb.ControlMessage) error {
 	payload, err := proto.Marshal(message)
 	if err != nil {
 		return err
 	}
-	return conn.WriteMessage(websocket.BinaryMessage, payload)
+	return h.enqueue(conn, outgoing{payload: payload})
+}
+
+// send queues a message carrying state in full; the client's later deltas
+// are taken against it.
+func (h *controlHub) send(conn *websocket.Conn, message *pb.ControlMessage, state sim.Snapshot) error {
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	full := message.GetState()
+	if full == nil {
+		full = message.GetAck().GetState()
+	}
+	return h.enqueue(conn, outgoing{
+		payload: payload,
+		frame:   &frame{generation: state.Generation, tick: state.Tick, full: full},
+	})
+}
+
+func (h *controlHub) enqueue(conn *websocket.Conn, message outgoing) error {
+	h.mu.Lock()
+	info, ok := h.clients[conn]
+	h.mu.Unlock()
+	if !ok {
+		return errClientGone
+	}
+
+	select {
+	case info.out.queue <- message:
+		return nil
+	case <-info.out.done:
+		return errClientGone
+	}
+}
+
+// countSent records an outbound message in the traffic counters.
+func (h *controlHub) countSent(bytes int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bytesSent += uint64(bytes)
+	h.messagesSent++
+}
+
+// mutates reports whether a control message changes the simulation, as
+// opposed to only querying it.
+func mutates(message *pb.ControlMessage) bool {
+	switch message.Control.(type) {
+	case *pb.ControlMessage_EventsSince, *pb.ControlMessage_Aggregate, *pb.ControlMessage_RandState:
+		return false
+	}
+	return true
+}
+
+// fieldErrors extracts the per-field violations from a validation error so
+// clients can mark each offending input.
+func fieldErrors(err error) []*pb.ControlFieldError {
+	var validation *sim.ValidationError
+	if !errors.As(err, &validation) {
+		return nil
+	}
+
+	fields := make([]*pb.ControlFieldError, 0, len(validation.Fields))
+	for _, field := range validation.Fields {
+		fields = append(fields, &pb.ControlFieldError{Field: field.Field, Reason: field.Reason})
+	}
+	return fields
+}
+
+// clampWarnings converts the values the simulation clamped into their wire
+// form so clients can show what was actually applied.
+func clampWarnings(warnings []sim.ClampWarning) []*pb.ControlClampWarning {
+	converted := make([]*pb.ControlClampWarning, len(warnings))
+	for i, warning := range warnings {
+		converted[i] = &pb.ControlClampWarning{Field: warning.Field, Requested: warning.Requested, Applied: warning.Applied}
+	}
+	return converted
 }
 
 func stateMessage(state sim.Snapshot) *pb.ControlMessage {
 	return &pb.ControlMessage{Control: &pb.ControlMessage_State{State: snapshotToProto(state)}}
 }
 
+func variantsToProto(variants []sim.VariantSnapshot) []*pb.VariantState {
+	states := make([]*pb.VariantState, len(variants))
+	for i, v := range variants {
+		states[i] = &pb.VariantState{
+			Name:                   v.Name,
+			TransmissionMultiplier: v.TransmissionMultiplier,
+			DeathMultiplier:        v.DeathMultiplier,
+			CurrentInfected:        int32(v.CurrentInfected),
+			TotalInfections:        int32(v.TotalInfections),
+		}
+	}
+	return states
+}
+
+// introduceVariant registers the requested variant if needed, first naming
+// the circulating infections after the active pathogen, and seeds it.
+func introduceVariant(simulation *sim.Simulation, request *pb.ControlIntroduceVariant) error {
+	if len(simulation.Variants()) == 0 {
+		if err := simulation.AddVariant(simulation.ActivePathogen(), 1, 1); err != nil {
+			return err
+		}
+	}
+	known := slices.ContainsFunc(simulation.Variants(), func(v sim.VariantSnapshot) bool {
+		return v.Name == request.GetName()
+	})
+	if !known {
+		transmission, death := 1.0, 1.0
+		if request.TransmissionMultiplier != nil {
+			transmission = request.GetTransmissionMultiplier()
+		}
+		if request.DeathMultiplier != nil {
+			death = request.GetDeathMultiplier()
+		}
+		if err := simulation.AddVariant(request.GetName(), transmission, death); err != nil {
+			return err
+		}
+	}
+	_, err := simulation.IntroduceVariant(request.GetName(), int(request.GetCount()))
+	return err
+}
+
 func snapshotToProto(state sim.Snapshot) *pb.ControlState {
 	return &pb.ControlState{
 		Settings: &pb.ControlUpdate{
@@ -159,46 +645,298 @@ func snapshotToProto(state sim.Snapshot) *pb.ControlState {
 				Capacity:                    int32(state.HospitalCapacity),
 				DeathRateOverloadMultiplier: state.DeathRateOverloadMultiplier,
 			},
+			InteractionVariance: proto.Float64(state.InteractionVariance),
+			TickIntervalMs:      proto.Int64(state.TickIntervalMs),
+			ImportRate:          proto.Float64(state.ImportRate),
+			InfectionRadius:     proto.Float64(state.InfectionRadius),
+			Population:          proto.Int32(int32(state.Population)),
 		},
 		CurrentInfected:           int32(state.CurrentInfected),
+		CurrentRecovered:          int32(state.CurrentRecovered),
 		EffectiveDeathProbability: state.EffectiveDeathProbability,
 		Overloaded:                state.Overloaded,
 		InfectionProbability:      state.InfectionProbability,
 		SpeedModifier:             state.SpeedModifier,
 		CapacityUtilization:       state.CapacityUtilization,
+		ActivePathogen:            state.ActivePathogen,
+		StateVersion:              state.StateVersion,
+		Tick:                      int64(state.Tick),
+		Rt:                        state.EffectiveR,
+		Paused:                    state.Paused,
+		CurrentVaccinated:         int32(state.CurrentVaccinated),
+		Variants:                  variantsToProto(state.Variants),
+		Imported:                  int32(state.Imported),
+		CurrentExposed:            int32(state.CurrentExposed),
+		CurrentSusceptible:        int32(state.CurrentSusceptible),
+	}
+}
+
+func eventsMessage(events []sim.Event) *pb.ControlMessage {
+	reply := &pb.ControlEvents{Events: make([]*pb.ControlEvent, 0, len(events))}
+	for _, event := range events {
+		reply.Events = append(reply.Events, &pb.ControlEvent{
+			Tick:    int64(event.Tick),
+			Kind:    string(event.Kind),
+			Count:   int32(event.Count),
+			Message: event.Message,
+		})
+	}
+	return &pb.ControlMessage{Control: &pb.ControlMessage_Events{Events: reply}}
+}
+
+func randStateMessage(state sim.RandState) *pb.ControlMessage {
+	return &pb.ControlMessage{Control: &pb.ControlMessage_RandState{RandState: &pb.ControlRandState{
+		Seed:    state.Seed,
+		Draws:   state.Draws,
+		Tracked: state.Tracked,
+	}}}
+}
+
+func aggregatesMessage(agg sim.Aggregates) *pb.ControlMessage {
+	return &pb.ControlMessage{Control: &pb.ControlMessage_Aggregates{Aggregates: &pb.ControlAggregates{
+		Ticks:             int32(agg.Ticks),
+		FromTick:          int64(agg.FromTick),
+		ToTick:            int64(agg.ToTick),
+		MeanInfected:      agg.MeanInfected,
+		PeakInfected:      int32(agg.PeakInfected),
+		PeakTick:          int64(agg.PeakTick),
+		Infections:        int32(agg.Infections),
+		Deaths:            int32(agg.Deaths),
+		TicksOverCapacity: int32(agg.TicksOverCapacity),
+	}}}
+}
+
+// compartments are the initial conditions set on the command line.
+type compartments struct {
+	infected   int
+	recovered  int
+	immune     int
+	population int
+}
+
+// compartmentFlags are the flags that set the starting compartments.
+var compartmentFlags = []string{"infected", "recovered", "immune", "population"}
+
+// startConflict reports an error when the explicitly set flags describe the
+// starting point more than once: -config and -scenario each replace the whole
+// starting point, so they rule out each other and the compartment flags.
+func startConflict(set map[string]bool) error {
+	if set["config"] && set["scenario"] {
+		return errors.New("-config and -scenario cannot be combined")
+	}
+	for _, source := range []string{"config", "scenario"} {
+		if !set[source] {
+			continue
+		}
+		for _, name := range compartmentFlags {
+			if set[name] {
+				return fmt.Errorf("-%s cannot be combined with -%s; set the starting compartments in one place", name, source)
+			}
+		}
+	}
+	return nil
+}
+
+// simulation creates a simulation with the default parameters for base,
+// starting from these compartments. Combinations that don't fit in the
+// population are rejected.
+func (c compartments) simulation(base float64) (*sim.Simulation, error) {
+	cfg := sim.New(base).Config()
+	cfg.InitialInfected = c.infected
+	cfg.InitialRecovered = c.recovered
+	cfg.InitialImmune = c.immune
+	cfg.Population = c.population
+	return sim.NewFromConfig(cfg)
+}
+
+// loadReplay reads the recording at path and converts speed into the interval
+// between replayed snapshots, taking one recorded tick as one second.
+func loadReplay(path, speed string) ([]sim.Snapshot, time.Duration, error) {
+	multiplier, err := parseSpeed(speed)
+	if err != nil {
+		return nil, 0, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
 	}
+	defer file.Close()
+
+	states, err := readRecording(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	return states, max(time.Duration(float64(time.Second)/multiplier), sim.MinTickInterval), nil
 }
 
 func main() {
 	addr := flag.String("addr", ":8080", "server listen address")
 	base := flag.Float64("base", 0.25, "base transmission probability")
+	paused := flag.Bool("paused", false, "start paused; advance ticks with POST /api/step")
+	strict := flag.Bool("strict", false, "reject out-of-range control input instead of clamping it")
+	maxConns := flag.Int("maxconns", 0, "maximum concurrent websocket connections (0 for unlimited)")
+	seed := flag.Int64("seed", 0, "seed the random stream for a reproducible run (0 picks a time-based seed)")
+	seedPhrase := flag.String("seedphrase", "", "seed the random stream from a shareable phrase; overrides -seed")
+	configPath := flag.String("config", "", "start from a JSON or YAML config file, such as one saved from GET /api/config")
+	protoDir := flag.String("proto", "proto", "directory of schema files served under /proto/")
+	var initial compartments
+	flag.IntVar(&initial.infected, "infected", 10, "people infected at the start")
+	flag.IntVar(&initial.recovered, "recovered", 0, "people who start recovered")
+	flag.IntVar(&initial.immune, "immune", 0, "people who start immune")
+	flag.IntVar(&initial.population, "population", 1000, "total population; everyone else starts susceptible (0 for unbounded)")
+	noReset := flag.Bool("noreset", false, "reject ControlReset requests from clients")
+	keyframe := flag.Int("keyframe", 100, "ticks between full state broadcasts; clients get only changed fields in between (0 always sends full states)")
+	pingInterval := flag.Duration("ping-interval", 30*time.Second, "how often to ping websocket clients; those silent for two intervals are dropped (0 disables)")
+	updateRate := flag.Float64("update-rate", 10, "maximum control messages per second from each websocket connection (0 for unlimited)")
+	token := flag.String("token", "", "shared secret clients must present to use /ws/control and POST /api/step (default $PANDEMICA_TOKEN)")
+	readOnlyToken := flag.String("readonly-token", "", "secret that admits /ws/control clients to receive state only (default $PANDEMICA_READONLY_TOKEN)")
+	replayPath := flag.String("replay", "", "stream a recorded run (NDJSON snapshots) to clients instead of running the model")
+	speed := flag.String("speed", "1x", "playback speed for -replay, such as 2x")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 5*time.Second, "how long to wait for requests to finish on SIGINT or SIGTERM")
+	scenario := flag.String("scenario", "", "start from a built-in scenario: "+strings.Join(sim.AvailableScenarios(), ", "))
 	flag.Parse()
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if err := startConflict(set); err != nil {
+		fmt.Fprintln(flag.CommandLine.Output(), err)
+		flag.Usage()
+		os.Exit(2)
+	}
+	// Secrets fall back to the environment so they stay out of process
+	// listings.
+	if *token == "" {
+		*token = os.Getenv("PANDEMICA_TOKEN")
+	}
+	if *readOnlyToken == "" {
+		*readOnlyToken = os.Getenv("PANDEMICA_READONLY_TOKEN")
+	}
+	if *readOnlyToken != "" && *token == "" {
+		log.Fatal("-readonly-token needs -token; without it every client already has full control")
+	}
+
+	if missing := missingSchemaFiles(*protoDir); len(missing) > 0 {
+		log.Printf("warning: schema files missing from %s: %s; browser clients will fail to decode control messages",
+			*protoDir, strings.Join(missing, ", "))
+	}
 
-	simulation := sim.New(*base)
+	simulation, err := initial.simulation(*base)
+	if err != nil {
+		log.Fatalf("initial conditions: %v", err)
+	}
+	simulation.SetStrict(*strict)
+	if *seedPhrase != "" {
+		simulation.SetSeedPhrase(*seedPhrase)
+	} else if *seed != 0 {
+		simulation.SetSeed(*seed)
+	}
+	if *paused {
+		simulation.Pause()
+	}
+	for name, profile := range demoPathogens {
+		if err := simulation.AddPathogen(name, profile); err != nil {
+			log.Fatalf("register pathogen %q: %v", name, err)
+		}
+	}
+	if *configPath != "" {
+		cfg, err := sim.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("load config: %v", err)
+		}
+		if err := simulation.ApplyConfig(*cfg); err != nil {
+			log.Fatalf("apply config: %v", err)
+		}
+	}
+	if *scenario != "" {
+		if err := simulation.LoadScenario(*scenario); err != nil {
+			log.Fatalf("load scenario: %v", err)
+		}
+	}
 	hub := newControlHub()
+	hub.maxConns = int64(*maxConns)
+	hub.disableReset = *noReset
+	hub.updateRate = *updateRate
+	hub.pingInterval = *pingInterval
+	hub.keyframeTicks = *keyframe
+	hub.metrics = newMetrics(hub)
+	hub.token = *token
+	hub.readOnlyToken = *readOnlyToken
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// SIGINT or SIGTERM cancels ctx, which stops the simulation and ends
+	// long-lived requests such as /api/stream.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	go simulation.Run(ctx, time.Second, func(state sim.Snapshot) {
-		// Broadcast computed modifier so clients stay in sync.
-		hub.broadcastControl(state)
-		log.Printf(
-			"tick probability=%.3f modifier=%.2f infected=%d overloaded=%t death_prob=%.3f",
-			state.InfectionProbability,
-			state.TransmissionModifier,
-			state.CurrentInfected,
-			state.Overloaded,
-			state.EffectiveDeathProbability,
-		)
-	})
+	var running sync.WaitGroup
+	running.Add(1)
+	if *replayPath != "" {
+		states, interval, err := loadReplay(*replayPath, *speed)
+		if err != nil {
+			log.Fatalf("load replay: %v", err)
+		}
+		log.Printf("replaying %d snapshots from %s at %s", len(states), *replayPath, *speed)
+		go func() {
+			defer running.Done()
+			replay(ctx, states, interval, hub.broadcastControl)
+		}()
+	} else {
+		go func() {
+			defer running.Done()
+			simulation.Run(ctx, simulation.TickInterval(), func(state sim.Snapshot) {
+				// Broadcast computed modifier so clients stay in sync.
+				hub.broadcastControl(state)
+				log.Printf(
+					"tick probability=%.3f modifier=%.2f infected=%d overloaded=%t death_prob=%.3f",
+					state.InfectionProbability,
+					state.TransmissionModifier,
+					state.CurrentInfected,
+					state.Overloaded,
+					state.EffectiveDeathProbability,
+				)
+			})
+		}()
+	}
 
-	http.Handle("/proto/", http.StripPrefix("/proto/", http.FileServer(http.Dir("proto"))))
+	http.Handle("/proto/", http.StripPrefix("/proto/", schemaHandler(*protoDir)))
 	http.Handle("/ws/control", hub.handler(simulation))
+	http.Handle("/api/hub", hub.statsHandler())
+	http.Handle("/metrics", hub.metrics.handler())
+	http.Handle("/api/step", stepHandler(simulation, hub))
+	http.Handle("/api/stream", streamHandler(simulation))
+	http.Handle("/api/influx", influxHandler(simulation))
+	http.Handle("/api/config", configHandler(simulation))
+	http.Handle("/api/finalsize", finalSizeHandler(simulation))
+	http.Handle("/api/history", historyHandler(simulation))
+	http.Handle("/api/export.csv", exportHandler(simulation))
+	http.Handle("/api/debug/hooks", hooksHandler(simulation))
+	http.Handle("/api/fhir/MeasureReport", measureReportHandler(simulation))
 	http.Handle("/", http.FileServer(http.Dir("web")))
 
+	server := &http.Server{
+		Addr:        *addr,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
 	log.Printf("serving UI on http://localhost%v", *addr)
-	if err := http.ListenAndServe(*addr, nil); err != nil {
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("server failed: %v", err)
+	case <-ctx.Done():
+	}
+	// A second signal kills the process outright.
+	stop()
+	log.Printf("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v", err)
 	}
+	// http.Server does not track hijacked websocket connections, so the hub
+	// closes them itself.
+	hub.closeAll()
+	running.Wait()
 }
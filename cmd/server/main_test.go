@@ -0,0 +1,1005 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+	sim "pandemica/internal/sim"
+	pb "pandemica/proto"
+)
+
+func dialControl(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial control socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readControl(t *testing.T, conn *websocket.Conn) *pb.ControlMessage {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read control message: %v", err)
+	}
+	var message pb.ControlMessage
+	if err := proto.Unmarshal(data, &message); err != nil {
+		t.Fatalf("decode control message: %v", err)
+	}
+	return &message
+}
+
+func sendControl(t *testing.T, conn *websocket.Conn, message *pb.ControlMessage) {
+	t.Helper()
+
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("encode control message: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+		t.Fatalf("write control message: %v", err)
+	}
+}
+
+// readAck skips state broadcasts until the next ack or error arrives.
+func readAck(t *testing.T, conn *websocket.Conn) *pb.ControlMessage {
+	t.Helper()
+
+	for {
+		message := readControl(t, conn)
+		if message.GetAck() != nil || message.GetError() != nil {
+			return message
+		}
+	}
+}
+
+func TestControlUpdateAppliesInteractionVariance(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	if initial := readControl(t, conn).GetState(); initial.GetSettings().GetInteractionVariance() != 0 {
+		t.Fatalf("expected default variance 0, got %v", initial.GetSettings().GetInteractionVariance())
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate:    1,
+		Hospital:            &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		InteractionVariance: proto.Float64(1.5),
+	}}})
+
+	ack := readAck(t, conn).GetAck()
+	if ack == nil {
+		t.Fatal("expected control ack")
+	}
+	if got := ack.GetState().GetSettings().GetInteractionVariance(); got != 1.5 {
+		t.Fatalf("expected acked variance 1.5, got %v", got)
+	}
+	if got := simulation.InteractionVariance(); got != 1.5 {
+		t.Fatalf("expected simulation variance 1.5, got %v", got)
+	}
+
+	// Updates that omit the variance must leave it untouched.
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 0.5,
+	}}})
+	readAck(t, conn)
+	if got := simulation.InteractionVariance(); got != 1.5 {
+		t.Fatalf("expected variance to persist, got %v", got)
+	}
+}
+
+func TestControlUpdateChangesTickInterval(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	if initial := readControl(t, conn).GetState(); initial.GetSettings().GetTickIntervalMs() != 1000 {
+		t.Fatalf("expected the default 1000ms interval, got %v", initial.GetSettings().GetTickIntervalMs())
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		TickIntervalMs:   proto.Int64(250),
+	}}})
+	if got := readAck(t, conn).GetAck().GetState().GetSettings().GetTickIntervalMs(); got != 250 {
+		t.Fatalf("expected acked interval 250ms, got %v", got)
+	}
+	if got := simulation.TickInterval(); got != 250*time.Millisecond {
+		t.Fatalf("expected simulation interval 250ms, got %v", got)
+	}
+
+	// Intervals that would busy-spin the loop are raised to the minimum.
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		TickIntervalMs:   proto.Int64(1),
+	}}})
+	readAck(t, conn)
+	if got := simulation.TickInterval(); got != sim.MinControlTickInterval {
+		t.Fatalf("expected interval clamped to %v, got %v", sim.MinControlTickInterval, got)
+	}
+}
+
+func TestControlUpdateSetsImportRate(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		ImportRate:       proto.Float64(3),
+	}}})
+	if got := readAck(t, conn).GetAck().GetState().GetSettings().GetImportRate(); got != 3 {
+		t.Fatalf("expected acked import rate 3, got %v", got)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		ImportRate:       proto.Float64(-1),
+	}}})
+	ack := readAck(t, conn).GetAck()
+	if got := ack.GetState().GetSettings().GetImportRate(); got != 0 {
+		t.Fatalf("expected a negative import rate clamped to 0, got %v", got)
+	}
+	if warnings := ack.GetWarnings(); len(warnings) != 1 || warnings[0].GetField() != "import_rate" {
+		t.Fatalf("expected an import_rate clamp warning, got %v", warnings)
+	}
+}
+
+func TestControlUpdateSetsInfectionRadius(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		InfectionRadius:  proto.Float64(2.5),
+	}}})
+	if got := readAck(t, conn).GetAck().GetState().GetSettings().GetInfectionRadius(); got != 2.5 {
+		t.Fatalf("expected acked infection radius 2.5, got %v", got)
+	}
+	if got := simulation.InfectionRadius(); got != 2.5 {
+		t.Fatalf("expected the simulation to use radius 2.5, got %v", got)
+	}
+}
+
+func TestControlUpdateResizesThePopulation(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		Population:       proto.Int32(2000),
+	}}})
+	if got := readAck(t, conn).GetAck().GetState().GetSettings().GetPopulation(); got != 2000 {
+		t.Fatalf("expected acked population 2000, got %d", got)
+	}
+	if got := simulation.Population(); got != 2000 {
+		t.Fatalf("expected the simulation to hold 2000 people, got %d", got)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+		Population:       proto.Int32(5),
+	}}})
+	if reply := readAck(t, conn); !strings.Contains(reply.GetError().GetMessage(), "population") {
+		t.Fatalf("expected a population error, got %v", reply)
+	}
+	if got := simulation.Population(); got != 2000 {
+		t.Fatalf("expected a rejected resize to keep 2000 people, got %d", got)
+	}
+}
+
+func TestStrictModeReportsControlError(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.SetStrict(true)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 1,
+		Hospital:         &pb.HospitalParameters{Capacity: -5, DeathRateOverloadMultiplier: 2},
+	}}})
+
+	reply := readAck(t, conn)
+	if reply.GetError() == nil {
+		t.Fatalf("expected a control error, got %v", reply)
+	}
+	if !strings.Contains(reply.GetError().GetMessage(), "hospital capacity") {
+		t.Fatalf("expected the error to name the field, got %q", reply.GetError().GetMessage())
+	}
+	if got := simulation.HospitalCapacity(); got != 50 {
+		t.Fatalf("expected capacity to stay at 50, got %d", got)
+	}
+}
+
+func TestStrictModeReportsEveryInvalidField(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.SetStrict(true)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 2,
+		Hospital:         &pb.HospitalParameters{Capacity: -5, DeathRateOverloadMultiplier: 0.5},
+	}}})
+
+	reply := readAck(t, conn).GetError()
+	if reply == nil {
+		t.Fatal("expected a control error")
+	}
+	got := map[string]bool{}
+	for _, field := range reply.GetFields() {
+		if field.GetReason() == "" {
+			t.Fatalf("expected a reason for %q", field.GetField())
+		}
+		got[field.GetField()] = true
+	}
+	for _, want := range []string{"transmission_rate", "hospital.capacity", "hospital.death_rate_overload_multiplier"} {
+		if !got[want] {
+			t.Fatalf("expected %q among the field errors, got %v", want, reply.GetFields())
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected exactly 3 field errors, got %v", reply.GetFields())
+	}
+}
+
+func TestClampedUpdateReportsWarnings(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+		TransmissionRate: 2,
+		Hospital:         &pb.HospitalParameters{Capacity: 40, DeathRateOverloadMultiplier: 2},
+	}}})
+
+	ack := readAck(t, conn).GetAck()
+	if ack == nil {
+		t.Fatal("expected the clamped update to be acknowledged")
+	}
+	warnings := ack.GetWarnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected one clamp warning, got %v", warnings)
+	}
+	if w := warnings[0]; w.GetField() != "transmission_rate" || w.GetRequested() != 2 || w.GetApplied() != 1 {
+		t.Fatalf("expected transmission_rate clamped from 2 to 1, got %v", w)
+	}
+}
+
+func TestStaleVersionUpdateIsRejected(t *testing.T) {
+	simulation := sim.New(0.25)
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	first := dialControl(t, server)
+	second := dialControl(t, server)
+	seen := readControl(t, first).GetState().GetStateVersion()
+	readControl(t, second)
+
+	update := func(rate float64) *pb.ControlMessage {
+		return &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{
+			TransmissionRate: rate,
+			Hospital:         &pb.HospitalParameters{Capacity: 50, DeathRateOverloadMultiplier: 2},
+			ExpectedVersion:  proto.Uint64(seen),
+		}}}
+	}
+
+	sendControl(t, first, update(0.4))
+	if reply := readAck(t, first); reply.GetAck() == nil {
+		t.Fatalf("expected the first operator's update to apply, got %v", reply)
+	}
+
+	sendControl(t, second, update(0.9))
+	reply := readAck(t, second)
+	if reply.GetError() == nil || !strings.Contains(reply.GetError().GetMessage(), "changed since it was read") {
+		t.Fatalf("expected a conflict error for the stale update, got %v", reply)
+	}
+	if got := simulation.CurrentTransmissionModifier(); got != 0.4 {
+		t.Fatalf("expected the first operator's rate 0.4 to stand, got %v", got)
+	}
+}
+
+func TestBroadcastTicksNeverGoBackwards(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	for _, tick := range []int{1, 3, 2, 3, 4} {
+		hub.broadcastControl(sim.Snapshot{Tick: tick})
+	}
+
+	var got []int64
+	for len(got) < 4 {
+		got = append(got, readControl(t, conn).GetState().GetTick())
+	}
+	want := []int64{1, 3, 3, 4}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected broadcast ticks %v, got %v", want, got)
+		}
+	}
+}
+
+func TestEventsSinceReturnsLaterEvents(t *testing.T) {
+	simulation := sim.New(0.25)
+	for tick := 1; tick <= 3; tick++ {
+		if err := simulation.ScheduleImport(tick, tick); err != nil {
+			t.Fatalf("schedule import: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		simulation.Step()
+	}
+
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_EventsSince{
+		EventsSince: &pb.ControlEventsSince{Tick: 1},
+	}})
+
+	var reply *pb.ControlEvents
+	for reply == nil {
+		reply = readControl(t, conn).GetEvents()
+	}
+	events := reply.GetEvents()
+	if len(events) != 2 {
+		t.Fatalf("expected events for ticks 2 and 3, got %v", events)
+	}
+	for i, event := range events {
+		wantTick := int64(i + 2)
+		if event.GetTick() != wantTick || event.GetKind() != "import" || event.GetCount() != int32(wantTick) {
+			t.Fatalf("unexpected event %d: %v", i, event)
+		}
+	}
+}
+
+func TestClearHistoryKeepsModelState(t *testing.T) {
+	simulation := sim.New(0.25)
+	if err := simulation.ScheduleImport(1, 5); err != nil {
+		t.Fatalf("schedule import: %v", err)
+	}
+	simulation.Step()
+	infected := simulation.CurrentInfected()
+
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_ClearHistory{
+		ClearHistory: &pb.ControlClearHistory{},
+	}})
+	if reply := readAck(t, conn); reply.GetAck() == nil {
+		t.Fatalf("expected an ack, got %v", reply)
+	}
+
+	if events := simulation.Events(); len(events) != 0 {
+		t.Fatalf("expected the event log to be empty, got %v", events)
+	}
+	if history := simulation.History(); len(history) != 0 {
+		t.Fatalf("expected the history to be empty, got %d snapshots", len(history))
+	}
+	if got := simulation.CurrentInfected(); got != infected {
+		t.Fatalf("expected infected to stay at %d, got %d", infected, got)
+	}
+}
+
+func TestRandStateReportsSeed(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.SetSeed(1234)
+	simulation.Step()
+
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_RandState{
+		RandState: &pb.ControlRandState{},
+	}})
+
+	var reply *pb.ControlRandState
+	for reply == nil {
+		reply = readControl(t, conn).GetRandState()
+	}
+	if reply.GetSeed() != 1234 || !reply.GetTracked() {
+		t.Fatalf("expected tracked seed 1234, got %v", reply)
+	}
+	if want := simulation.RandState().Draws; reply.GetDraws() != want {
+		t.Fatalf("expected %d draws, got %d", want, reply.GetDraws())
+	}
+}
+
+func TestMaxConnsRejectsExtraConnection(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	hub.maxConns = 2
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		readControl(t, dialControl(t, server))
+	}
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, response, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected the third connection to be refused")
+	}
+	if response == nil || response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %v", response)
+	}
+}
+
+func TestTokenGuardsTheControlSocket(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	hub.token = "s3cret"
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	for name, dial := range map[string]struct {
+		url    string
+		header http.Header
+	}{
+		"missing":      {url: url},
+		"wrong query":  {url: url + "?token=guess"},
+		"wrong bearer": {url: url, header: http.Header{"Authorization": {"Bearer guess"}}},
+	} {
+		conn, response, err := websocket.DefaultDialer.Dial(dial.url, dial.header)
+		if err == nil {
+			conn.Close()
+			t.Fatalf("%s token: expected the connection to be refused", name)
+		}
+		if response == nil || response.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("%s token: expected 401, got %v", name, response)
+		}
+	}
+
+	for name, dial := range map[string]struct {
+		url    string
+		header http.Header
+	}{
+		"query":  {url: url + "?token=s3cret"},
+		"bearer": {url: url, header: http.Header{"Authorization": {"Bearer s3cret"}}},
+	} {
+		conn, _, err := websocket.DefaultDialer.Dial(dial.url, dial.header)
+		if err != nil {
+			t.Fatalf("%s token: dial control socket: %v", name, err)
+		}
+		readControl(t, conn)
+		sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Pause{Pause: &pb.ControlPause{Paused: true}}})
+		if reply := readAck(t, conn); reply.GetAck() == nil {
+			t.Fatalf("%s token: expected an ack, got %v", name, reply)
+		}
+		conn.Close()
+	}
+}
+
+func TestObserversReceiveStateButCannotControl(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	hub.token = "s3cret"
+	hub.readOnlyToken = "viewer"
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?token=viewer"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial control socket: %v", err)
+	}
+	defer conn.Close()
+	if readControl(t, conn).GetState() == nil {
+		t.Fatal("expected the observer to receive the current state")
+	}
+	if stats := getHubStats(t, hub); stats.Clients != 1 || stats.Observers != 1 {
+		t.Fatalf("expected one observer among 1 client, got %+v", stats)
+	}
+
+	for name, message := range map[string]*pb.ControlMessage{
+		"update": {Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{TransmissionRate: 2}}},
+		"pause":  {Control: &pb.ControlMessage_Pause{Pause: &pb.ControlPause{Paused: true}}},
+		"reset":  {Control: &pb.ControlMessage_Reset_{Reset_: &pb.ControlReset{}}},
+	} {
+		sendControl(t, conn, message)
+		if reply := readAck(t, conn); reply.GetError() == nil {
+			t.Fatalf("%s: expected a control error, got %v", name, reply)
+		}
+	}
+	if state := simulation.Snapshot(); state.Paused || state.TransmissionModifier != 1 {
+		t.Fatalf("expected observers to leave the simulation alone, got %+v", state)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_RandState{RandState: &pb.ControlRandState{}}})
+	if reply := readControl(t, conn); reply.GetRandState() == nil {
+		t.Fatalf("expected observers to query the random state, got %v", reply)
+	}
+
+	hub.broadcastControl(simulation.Step())
+	if state := readControl(t, conn).GetState(); state == nil || state.GetTick() != 1 {
+		t.Fatalf("expected the tick 1 broadcast, got %v", state)
+	}
+}
+
+func TestControlUpdatesAreRateLimited(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	hub.updateRate = 1
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	flooder := dialControl(t, server)
+	readControl(t, flooder)
+	other := dialControl(t, server)
+	readControl(t, other)
+
+	pause := &pb.ControlMessage{Control: &pb.ControlMessage_Pause{Pause: &pb.ControlPause{Paused: true}}}
+	sendControl(t, flooder, pause)
+	if reply := readAck(t, flooder); reply.GetAck() == nil {
+		t.Fatalf("expected the first update to be applied, got %v", reply)
+	}
+	sendControl(t, flooder, pause)
+	if reply := readAck(t, flooder); reply.GetError().GetMessage() != "rate limit exceeded" {
+		t.Fatalf("expected a rate limit error, got %v", reply)
+	}
+
+	// Queries are not limited, and other connections keep their own budget.
+	sendControl(t, flooder, &pb.ControlMessage{Control: &pb.ControlMessage_RandState{RandState: &pb.ControlRandState{}}})
+	if reply := readControl(t, flooder); reply.GetRandState() == nil {
+		t.Fatalf("expected the random state, got %v", reply)
+	}
+	sendControl(t, other, pause)
+	if reply := readAck(t, other); reply.GetAck() == nil {
+		t.Fatalf("expected another client's update to be applied, got %v", reply)
+	}
+}
+
+func TestCloseAllSendsCloseFrames(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	hub.closeAll()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected a going-away close frame, got %v", err)
+	}
+
+	late := dialControl(t, server)
+	late.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := late.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected a connection after shutdown to be closed, got %v", err)
+	}
+}
+
+func TestKeepAliveDropsSilentClients(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	hub.pingInterval = 20 * time.Millisecond
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	// Gorilla answers pings only while reading, so this client keeps
+	// reading and the other goes silent.
+	live := dialControl(t, server)
+	go func() {
+		for {
+			if _, _, err := live.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	dialControl(t, server)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for getHubStats(t, hub).Clients != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the silent client to be dropped, got %+v", getHubStats(t, hub))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(5 * hub.pingInterval)
+	if clients := getHubStats(t, hub).Clients; clients != 1 {
+		t.Fatalf("expected the answering client to stay connected, got %d clients", clients)
+	}
+}
+
+func TestBroadcastsSendDeltasBetweenKeyframes(t *testing.T) {
+	simulation := sim.NewWithSeed(0.25, 3)
+	hub := newControlHub()
+	hub.keyframeTicks = 2
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	state := readControl(t, conn).GetState()
+	if state == nil {
+		t.Fatal("expected a full state on connect")
+	}
+
+	hub.broadcastControl(simulation.Step())
+	delta := readControl(t, conn).GetDelta()
+	if delta == nil {
+		t.Fatal("expected a delta for tick 1")
+	}
+	applyDelta(state, delta)
+	if want := snapshotToProto(simulation.Snapshot()); !proto.Equal(state, want) {
+		t.Fatalf("expected the patched state to match tick 1\n got %v\nwant %v", state, want)
+	}
+
+	hub.broadcastControl(simulation.Step())
+	if keyframe := readControl(t, conn).GetState(); keyframe == nil || keyframe.GetTick() != 2 {
+		t.Fatalf("expected a full state keyframe at tick 2, got %v", keyframe)
+	}
+}
+
+func TestSlowClientDoesNotStallBroadcasts(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	// The stalled client's outbox has no writer, as if its network had
+	// stopped draining it.
+	registered := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := hub.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		hub.add(conn, clientInfo{out: newOutbox(conn)})
+		close(registered)
+	}))
+	defer stalled.Close()
+	dialControl(t, stalled)
+	<-registered
+
+	live := dialControl(t, server)
+	readControl(t, live)
+
+	broadcasts := outboxSize + 10
+	for tick := 1; tick <= broadcasts; tick++ {
+		hub.broadcastControl(sim.Snapshot{Tick: tick})
+		if state := readControl(t, live).GetState(); state.GetTick() != int64(tick) {
+			t.Fatalf("expected the live client to receive tick %d, got %v", tick, state)
+		}
+	}
+	if dropped := getHubStats(t, hub).Dropped; dropped != 10 {
+		t.Fatalf("expected 10 broadcasts dropped for the stalled client, got %d", dropped)
+	}
+}
+
+func TestAggregateSummarisesRecentHistory(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.SetSeed(4)
+	for i := 0; i < 5; i++ {
+		simulation.Step()
+	}
+	want := simulation.Aggregate(3)
+
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Aggregate{
+		Aggregate: &pb.ControlAggregate{Window: 3},
+	}})
+	got := readControl(t, conn).GetAggregates()
+	if got == nil {
+		t.Fatal("expected an aggregates reply")
+	}
+	if got.GetTicks() != 3 || got.GetFromTick() != 3 || got.GetToTick() != 5 ||
+		got.GetMeanInfected() != want.MeanInfected || int(got.GetDeaths()) != want.Deaths {
+		t.Fatalf("expected aggregates %+v, got %v", want, got)
+	}
+}
+
+func TestControlStateReportsExposedAndSusceptible(t *testing.T) {
+	simulation := sim.NewWithSeed(0.5, 1)
+	simulation.SetIncubationPeriod(3)
+	for i := 0; i < 5; i++ {
+		simulation.Step()
+	}
+	want := simulation.Snapshot()
+	if want.CurrentExposed == 0 {
+		t.Fatal("expected someone to be exposed after five ticks")
+	}
+
+	server := httptest.NewServer(newControlHub().handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	state := readControl(t, conn).GetState()
+	if int(state.GetCurrentExposed()) != want.CurrentExposed || int(state.GetCurrentSusceptible()) != want.CurrentSusceptible {
+		t.Fatalf("expected %d exposed and %d susceptible, got %v", want.CurrentExposed, want.CurrentSusceptible, state)
+	}
+	if int(state.GetSettings().GetPopulation()) != want.Population {
+		t.Fatalf("expected population %d, got %d", want.Population, state.GetSettings().GetPopulation())
+	}
+}
+
+func TestInitialCompartmentsFromFlags(t *testing.T) {
+	simulation, err := compartments{infected: 4, recovered: 30, immune: 66, population: 200}.simulation(0.3)
+	if err != nil {
+		t.Fatalf("build simulation: %v", err)
+	}
+	state := simulation.Snapshot()
+	if state.CurrentInfected != 4 || state.CurrentRecovered != 30 || state.CurrentImmune != 66 ||
+		state.CurrentSusceptible != 100 || state.Population != 200 {
+		t.Fatalf("expected 4 infected, 30 recovered, 66 immune, and 100 susceptible of 200, got %+v", state)
+	}
+	if state.BaseTransmission != 0.3 {
+		t.Fatalf("expected base transmission 0.3, got %v", state.BaseTransmission)
+	}
+
+	if _, err := (compartments{infected: 10, immune: 95, population: 100}).simulation(0.3); err == nil {
+		t.Fatal("expected compartments exceeding the population to be rejected")
+	}
+}
+
+func TestStartConflictRejectsOverlappingFlags(t *testing.T) {
+	for _, set := range []map[string]bool{
+		{"config": true, "scenario": true},
+		{"config": true, "infected": true},
+		{"scenario": true, "population": true},
+		{"config": true, "immune": true, "seed": true},
+	} {
+		if err := startConflict(set); err == nil {
+			t.Fatalf("expected %v to be rejected", set)
+		}
+	}
+	for _, set := range []map[string]bool{
+		{},
+		{"config": true, "seed": true},
+		{"scenario": true, "paused": true},
+		{"infected": true, "recovered": true, "immune": true, "population": true},
+	} {
+		if err := startConflict(set); err != nil {
+			t.Fatalf("expected %v to be accepted, got %v", set, err)
+		}
+	}
+}
+
+func TestResetRestartsTheSimulationForEveryClient(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+	watcher := dialControl(t, server)
+	readControl(t, watcher)
+
+	for i := 0; i < 5; i++ {
+		hub.broadcastControl(simulation.Step())
+	}
+	for i := 0; i < 5; i++ {
+		readControl(t, watcher)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Reset_{Reset_: &pb.ControlReset{}}})
+	reply := readAck(t, conn)
+	if reply.GetAck() == nil || reply.GetAck().GetState().GetTick() != 0 {
+		t.Fatalf("expected an ack at tick 0, got %v", reply)
+	}
+
+	// The restarted run's tick 0 must not be dropped as older than tick 5.
+	state := readControl(t, watcher).GetState()
+	if state.GetTick() != 0 || state.GetCurrentInfected() != 10 {
+		t.Fatalf("expected a broadcast of the reset state, got %v", state)
+	}
+}
+
+func TestResetCanBeDisabled(t *testing.T) {
+	simulation := sim.New(0.25)
+	simulation.Step()
+	hub := newControlHub()
+	hub.disableReset = true
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Reset_{Reset_: &pb.ControlReset{}}})
+	if reply := readAck(t, conn); reply.GetError() == nil {
+		t.Fatalf("expected a control error, got %v", reply)
+	}
+	if got := simulation.Snapshot().Tick; got != 1 {
+		t.Fatalf("expected the simulation to stay at tick 1, got %d", got)
+	}
+}
+
+func TestLoadConfigUpdatesConnectedClients(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+	watcher := dialControl(t, server)
+	readControl(t, watcher)
+
+	config := `{"name": "classroom", "base_transmission": 0.6, "base_death_rate": 0.01, "infectious_period": 7,
+		"transmission_modifier": 0.5, "hospital_capacity": 12, "death_rate_overload_multiplier": 3,
+		"initial_infected": 4, "population": 200}`
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_LoadConfig{
+		LoadConfig: &pb.ControlLoadConfig{ConfigJson: config},
+	}})
+	if reply := readAck(t, conn); reply.GetAck() == nil {
+		t.Fatalf("expected an ack, got %v", reply)
+	}
+
+	state := readControl(t, watcher).GetState()
+	if state.GetActivePathogen() != "classroom" || state.GetCurrentInfected() != 4 ||
+		state.GetSettings().GetHospital().GetCapacity() != 12 || state.GetSettings().GetTransmissionRate() != 0.5 {
+		t.Fatalf("expected the watcher to receive the new config, got %v", state)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_LoadConfig{
+		LoadConfig: &pb.ControlLoadConfig{ConfigJson: `{"base_transmission": 0.6, "death_rate_overload_multiplier": 0.5}`},
+	}})
+	if reply := readAck(t, conn); reply.GetError() == nil {
+		t.Fatalf("expected an invalid config to be rejected, got %v", reply)
+	}
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_LoadConfig{
+		LoadConfig: &pb.ControlLoadConfig{ConfigJson: `{"base_transmision": 0.6}`},
+	}})
+	if reply := readAck(t, conn); reply.GetError() == nil {
+		t.Fatalf("expected a misspelled field to be rejected, got %v", reply)
+	}
+	if got := simulation.HospitalCapacity(); got != 12 {
+		t.Fatalf("expected rejected configs to leave capacity at 12, got %d", got)
+	}
+}
+
+func TestPauseHoldsTheSimulationStill(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Pause{Pause: &pb.ControlPause{Paused: true}}})
+	if reply := readAck(t, conn); !reply.GetAck().GetState().GetPaused() {
+		t.Fatalf("expected an ack reporting the pause, got %v", reply)
+	}
+	if !simulation.Paused() {
+		t.Fatal("expected the simulation to be paused")
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Pause{Pause: &pb.ControlPause{Paused: false}}})
+	if reply := readAck(t, conn); reply.GetAck() == nil || reply.GetAck().GetState().GetPaused() {
+		t.Fatalf("expected an ack reporting the resume, got %v", reply)
+	}
+	if simulation.Paused() {
+		t.Fatal("expected the simulation to be running again")
+	}
+}
+
+func TestVaccinationStartsAndStops(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Vaccination{
+		Vaccination: &pb.ControlVaccination{DosesPerTick: 25, Efficacy: proto.Float64(0.9)},
+	}})
+	if reply := readAck(t, conn); reply.GetAck() == nil {
+		t.Fatalf("expected an ack, got %v", reply)
+	}
+	if simulation.VaccinationDoses() != 25 || simulation.VaccineEfficacy() != 0.9 {
+		t.Fatalf("expected 25 doses at 90%% efficacy, got %d at %v",
+			simulation.VaccinationDoses(), simulation.VaccineEfficacy())
+	}
+	if got := simulation.Step().CurrentVaccinated; got != 25 {
+		t.Fatalf("expected 25 vaccinated after a tick, got %d", got)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Vaccination{
+		Vaccination: &pb.ControlVaccination{},
+	}})
+	if reply := readAck(t, conn); reply.GetAck().GetState().GetCurrentVaccinated() != 25 {
+		t.Fatalf("expected the ack to report 25 vaccinated, got %v", reply)
+	}
+	if simulation.VaccinationDoses() != 0 || simulation.VaccineEfficacy() != 0.9 {
+		t.Fatal("expected the campaign to stop and keep its efficacy")
+	}
+}
+
+func TestIntroduceVariantMidRun(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+	simulation.Step()
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_IntroduceVariant{
+		IntroduceVariant: &pb.ControlIntroduceVariant{Name: "delta", TransmissionMultiplier: proto.Float64(1.8), Count: 5},
+	}})
+	reply := readAck(t, conn)
+	variants := reply.GetAck().GetState().GetVariants()
+	if len(variants) != 2 || variants[0].GetName() != "default" || variants[1].GetName() != "delta" ||
+		variants[1].GetCurrentInfected() != 5 || variants[1].GetTransmissionMultiplier() != 1.8 {
+		t.Fatalf("expected the original strain and 5 delta infections, got %v", reply)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_IntroduceVariant{
+		IntroduceVariant: &pb.ControlIntroduceVariant{Name: "delta", Count: 3},
+	}})
+	if got := readAck(t, conn).GetAck().GetState().GetVariants()[1].GetTotalInfections(); got != 8 {
+		t.Fatalf("expected 8 delta infections in total, got %d", got)
+	}
+}
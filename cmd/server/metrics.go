@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	sim "pandemica/internal/sim"
+)
+
+// metrics exposes the simulation for Prometheus to scrape. The gauges
+// follow every state the hub broadcasts, whether from a tick, /api/step, or a
+// control message; the client count is read from the hub at scrape time.
+type metrics struct {
+	registry             *prometheus.Registry
+	infected             prometheus.Gauge
+	deathProbability     prometheus.Gauge
+	infectionProbability prometheus.Gauge
+	overloaded           prometheus.Gauge
+	controlUpdates       prometheus.Counter
+}
+
+func newMetrics(hub *controlHub) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		infected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "pandemica_infected",
+			Help: "People currently infected.",
+		}),
+		deathProbability: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "pandemica_death_probability",
+			Help: "Per-tick death probability after hospital overload effects.",
+		}),
+		infectionProbability: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "pandemica_infection_probability",
+			Help: "Per-contact infection probability after the transmission modifier.",
+		}),
+		overloaded: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "pandemica_overloaded",
+			Help: "1 while infections exceed hospital capacity, otherwise 0.",
+		}),
+		controlUpdates: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pandemica_control_updates_total",
+			Help: "Control messages of every kind applied from websocket clients.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.infected,
+		m.deathProbability,
+		m.infectionProbability,
+		m.overloaded,
+		m.controlUpdates,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "pandemica_websocket_clients",
+			Help: "Connected control websocket clients.",
+		}, func() float64 { return float64(hub.clientCount()) }),
+	)
+	return m
+}
+
+// observe updates the gauges from a broadcast state. A hub without metrics
+// does nothing.
+func (m *metrics) observe(state sim.Snapshot) {
+	if m == nil {
+		return
+	}
+	m.infected.Set(float64(state.CurrentInfected))
+	m.deathProbability.Set(state.EffectiveDeathProbability)
+	m.infectionProbability.Set(state.InfectionProbability)
+	overloaded := 0.0
+	if state.Overloaded {
+		overloaded = 1
+	}
+	m.overloaded.Set(overloaded)
+}
+
+// controlUpdateApplied counts an applied control message. A hub without
+// metrics does nothing.
+func (m *metrics) controlUpdateApplied() {
+	if m != nil {
+		m.controlUpdates.Inc()
+	}
+}
+
+// handler serves GET /metrics in the Prometheus text format.
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sim "pandemica/internal/sim"
+	pb "pandemica/proto"
+)
+
+func scrapeMetrics(t *testing.T, m *metrics) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	m.handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	return recorder.Body.String()
+}
+
+func TestMetricsReportTheLatestTick(t *testing.T) {
+	hub := newControlHub()
+	m := newMetrics(hub)
+	m.observe(sim.Snapshot{
+		CurrentInfected:           42,
+		EffectiveDeathProbability: 0.05,
+		InfectionProbability:      0.25,
+		Overloaded:                true,
+	})
+
+	body := scrapeMetrics(t, m)
+	for _, want := range []string{
+		"pandemica_infected 42\n",
+		"pandemica_death_probability 0.05\n",
+		"pandemica_infection_probability 0.25\n",
+		"pandemica_overloaded 1\n",
+		"pandemica_websocket_clients 0\n",
+		"pandemica_control_updates_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsCountClientsAndControlUpdates(t *testing.T) {
+	simulation := sim.New(0.25)
+	hub := newControlHub()
+	hub.metrics = newMetrics(hub)
+	server := httptest.NewServer(hub.handler(simulation))
+	defer server.Close()
+
+	conn := dialControl(t, server)
+	readControl(t, conn)
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Update{Update: &pb.ControlUpdate{TransmissionRate: 1.5}}})
+	if reply := readAck(t, conn); reply.GetAck() == nil {
+		t.Fatalf("expected an ack, got %v", reply)
+	}
+
+	sendControl(t, conn, &pb.ControlMessage{Control: &pb.ControlMessage_Pause{Pause: &pb.ControlPause{Paused: true}}})
+	if reply := readAck(t, conn); reply.GetAck() == nil {
+		t.Fatalf("expected an ack, got %v", reply)
+	}
+
+	body := scrapeMetrics(t, hub.metrics)
+	for _, want := range []string{"pandemica_websocket_clients 1\n", "pandemica_control_updates_total 2\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in metrics:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsFollowSteppedStates(t *testing.T) {
+	simulation := sim.NewWithSeed(0.25, 1)
+	simulation.Pause()
+	hub := newControlHub()
+	hub.metrics = newMetrics(hub)
+
+	recorder := httptest.NewRecorder()
+	stepHandler(simulation, hub)(recorder, httptest.NewRequest(http.MethodPost, "/api/step", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+
+	want := fmt.Sprintf("pandemica_infected %d\n", simulation.Snapshot().CurrentInfected)
+	if body := scrapeMetrics(t, hub.metrics); !strings.Contains(body, want) {
+		t.Fatalf("expected %q in metrics after a step:\n%s", want, body)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	pb "pandemica/proto"
+)
+
+// outboxSize is how many messages may wait for a slow client before state
+// broadcasts to it are dropped.
+const outboxSize = 32
+
+// frame is a full control state on its way to clients, in a broadcast or an
+// ack. Clients that last received the same state share one encoded delta.
+type frame struct {
+	generation int
+	tick       int
+	full       *pb.ControlState
+
+	mu     sync.Mutex
+	deltas map[*pb.ControlState][]byte
+}
+
+// deltaFrom returns the encoded delta from base to the frame's state, or nil
+// if it cannot be encoded.
+func (f *frame) deltaFrom(base *pb.ControlState) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if payload, ok := f.deltas[base]; ok {
+		return payload
+	}
+	if f.deltas == nil {
+		f.deltas = make(map[*pb.ControlState][]byte)
+	}
+	payload := marshalDelta(base, f.full)
+	f.deltas[base] = payload
+	return payload
+}
+
+// outgoing is a message queued for one client. frame is set when payload
+// carries a full state; a broadcast may be sent as a delta instead.
+type outgoing struct {
+	payload   []byte
+	frame     *frame
+	broadcast bool
+}
+
+// outbox is a connection's queue of outbound messages. Its writer goroutine
+// is the only one to write data messages to the connection, so a slow client
+// holds up nobody but itself.
+type outbox struct {
+	conn  *websocket.Conn
+	queue chan outgoing
+	// quit stops the writer; done is closed once it has stopped.
+	quit chan struct{}
+	done chan struct{}
+	// dropped counts broadcasts skipped because the queue was full, guarded
+	// by the hub's mu.
+	dropped uint64
+}
+
+func newOutbox(conn *websocket.Conn) *outbox {
+	return &outbox{
+		conn:  conn,
+		queue: make(chan outgoing, outboxSize),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// writeLoop sends out's messages until quit is closed or a write fails. It
+// tracks the last state the client received so broadcasts between keyframes
+// can go out as deltas against it.
+func (h *controlHub) writeLoop(out *outbox) {
+	defer close(out.done)
+
+	var base *pb.ControlState
+	var keyframe *frame
+	for {
+		var message outgoing
+		select {
+		case <-out.quit:
+			return
+		case message = <-out.queue:
+		}
+
+		payload, full := message.payload, message.frame != nil
+		if message.broadcast && h.deltaDue(keyframe, base, message.frame) {
+			if delta := message.frame.deltaFrom(base); delta != nil {
+				payload, full = delta, false
+			}
+		}
+		if err := out.conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+			log.Printf("failed to write to client: %v", err)
+			out.conn.Close()
+			return
+		}
+		h.countSent(len(payload))
+		if message.frame != nil {
+			base = message.frame.full
+		}
+		if full {
+			keyframe = message.frame
+		}
+	}
+}
+
+// deltaDue reports whether next may be sent as a delta against base, given
+// the last full state the client was sent.
+func (h *controlHub) deltaDue(keyframe *frame, base *pb.ControlState, next *frame) bool {
+	return h.keyframeTicks > 0 && base != nil && keyframe != nil && next.generation == keyframe.generation &&
+		next.tick-keyframe.tick < h.keyframeTicks
+}
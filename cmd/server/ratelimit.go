@@ -0,0 +1,37 @@
+package main
+
+import "time"
+
+// tokenBucket limits a connection to rate events per second with bursts of
+// up to rate events, at least one. Each connection's read loop owns its
+// bucket, so it needs no lock and refills lazily instead of on a timer.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket. A rate of zero or less never limits.
+func newTokenBucket(rate float64, now time.Time) tokenBucket {
+	burst := max(rate, 1)
+	return tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// allow reports whether an event at now is within the limit, spending a
+// token when it is.
+func (b *tokenBucket) allow(now time.Time) bool {
+	if b.rate <= 0 {
+		return true
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed*b.rate, b.burst)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRefillsOverTime(t *testing.T) {
+	start := time.Unix(0, 0)
+	bucket := newTokenBucket(2, start)
+
+	for i := 0; i < 2; i++ {
+		if !bucket.allow(start) {
+			t.Fatalf("expected event %d of the burst to be allowed", i)
+		}
+	}
+	if bucket.allow(start) {
+		t.Fatal("expected the third event in the same instant to be limited")
+	}
+	if !bucket.allow(start.Add(500 * time.Millisecond)) {
+		t.Fatal("expected a token back after half a second at 2/s")
+	}
+	if bucket.allow(start.Add(500 * time.Millisecond)) {
+		t.Fatal("expected only one token back after half a second")
+	}
+
+	// An idle connection saves up no more than one burst.
+	later := start.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if !bucket.allow(later) {
+			t.Fatalf("expected event %d after idling to be allowed", i)
+		}
+	}
+	if bucket.allow(later) {
+		t.Fatal("expected idling to refill only up to the burst")
+	}
+}
+
+func TestTokenBucketWithoutRateNeverLimits(t *testing.T) {
+	bucket := newTokenBucket(0, time.Unix(0, 0))
+	for i := 0; i < 100; i++ {
+		if !bucket.allow(time.Unix(0, 0)) {
+			t.Fatalf("expected event %d to be allowed", i)
+		}
+	}
+}
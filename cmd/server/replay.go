@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	sim "pandemica/internal/sim"
+)
+
+// readRecording decodes a recorded run stored as newline-delimited JSON, one
+// snapshot per line. Blank lines are skipped.
+func readRecording(r io.Reader) ([]sim.Snapshot, error) {
+	var states []sim.Snapshot
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var state sim.Snapshot
+		if err := json.Unmarshal([]byte(text), &state); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		states = append(states, state)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("recording holds no snapshots")
+	}
+	return states, nil
+}
+
+// parseSpeed reads a playback speed such as "2x", "0.5x", or "3".
+func parseSpeed(value string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("speed must be a positive multiplier such as 2x, got %q", value)
+	}
+	return speed, nil
+}
+
+// replay emits the recorded states in order, one every interval, until they
+// run out or ctx is cancelled.
+func replay(ctx context.Context, states []sim.Snapshot, interval time.Duration, emit func(sim.Snapshot)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for _, state := range states {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			emit(state)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sim "pandemica/internal/sim"
+)
+
+func TestReplayEmitsSnapshotsInRecordedOrder(t *testing.T) {
+	recording := `{"tick":1,"current_infected":10}
+{"tick":2,"current_infected":14}
+
+{"tick":3,"current_infected":21}
+`
+	states, err := readRecording(strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+
+	var emitted []sim.Snapshot
+	replay(context.Background(), states, time.Millisecond, func(state sim.Snapshot) {
+		emitted = append(emitted, state)
+	})
+
+	want := []int{10, 14, 21}
+	if len(emitted) != len(want) {
+		t.Fatalf("expected %d snapshots, got %d", len(want), len(emitted))
+	}
+	for i, state := range emitted {
+		if state.Tick != i+1 || state.CurrentInfected != want[i] {
+			t.Fatalf("snapshot %d: expected tick %d with %d infected, got tick %d with %d",
+				i, i+1, want[i], state.Tick, state.CurrentInfected)
+		}
+	}
+}
+
+func TestReplayRejectsBadInput(t *testing.T) {
+	if _, err := readRecording(strings.NewReader("{\"tick\":1}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected a line 2 decode error, got %v", err)
+	}
+	if _, err := readRecording(strings.NewReader("\n")); err == nil {
+		t.Fatal("expected an empty recording to be rejected")
+	}
+	if speed, err := parseSpeed("2x"); err != nil || speed != 2 {
+		t.Fatalf("expected speed 2, got %v (%v)", speed, err)
+	}
+	if _, err := parseSpeed("-1x"); err == nil {
+		t.Fatal("expected a negative speed to be rejected")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// requiredSchemaFiles are the artifacts browser clients load from /proto/.
+var requiredSchemaFiles = []string{"control.proto"}
+
+// missingSchemaFiles lists the required schema files absent from dir.
+func missingSchemaFiles(dir string) []string {
+	var missing []string
+	for _, name := range requiredSchemaFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// schemaHandler serves the files in dir without directory listings, so a
+// request for anything but a schema file gets a plain 404.
+func schemaHandler(dir string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(r.URL.Path))); err == nil && info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSchemaHandlerHidesDirectoryListings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "control.proto"), []byte(`syntax = "proto3";`), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "old"), 0o755); err != nil {
+		t.Fatalf("make subdirectory: %v", err)
+	}
+	handler := http.StripPrefix("/proto/", schemaHandler(dir))
+
+	for path, want := range map[string]int{
+		"/proto/":              http.StatusNotFound,
+		"/proto/old":           http.StatusNotFound,
+		"/proto/old/":          http.StatusNotFound,
+		"/proto/missing.proto": http.StatusNotFound,
+		"/proto/control.proto": http.StatusOK,
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != want {
+			t.Errorf("GET %s: expected status %d, got %d", path, want, recorder.Code)
+		}
+	}
+}
+
+func TestMissingSchemaFilesListsAbsentArtifacts(t *testing.T) {
+	if missing := missingSchemaFiles(t.TempDir()); len(missing) != 1 || missing[0] != "control.proto" {
+		t.Fatalf("expected control.proto to be reported missing, got %v", missing)
+	}
+	if missing := missingSchemaFiles("../../proto"); len(missing) != 0 {
+		t.Fatalf("expected the repository schema to be complete, got %v missing", missing)
+	}
+}
@@ -1,14 +1,24 @@
 package sim
 
-import "sync"
+import (
+	"fmt"
+	"sync"
+)
 
 var (
-	speedMu              sync.RWMutex
+	speedMu sync.RWMutex
+	// CurrentSpeedModifier mirrors the speed modifier of the simulation that
+	// last changed its lockdown.
+	//
+	// Deprecated: use Simulation.SpeedModifier. Each simulation keeps its own
+	// modifier and never reads this one.
 	CurrentSpeedModifier = 1.0
 )
 
-// SetCurrentSpeedModifier updates the package-level movement modifier used when
-// calculating agent velocity. Values below zero are clamped to zero.
+// SetCurrentSpeedModifier updates the package-level movement modifier used by
+// Agent.Step. Values below zero are clamped to zero.
+//
+// Deprecated: simulations keep their own modifier; pass it to Agent.Move.
 func SetCurrentSpeedModifier(modifier float64) {
 	speedMu.Lock()
 	defer speedMu.Unlock()
@@ -20,7 +30,10 @@ func SetCurrentSpeedModifier(modifier float64) {
 	CurrentSpeedModifier = modifier
 }
 
-// SpeedModifier returns the current movement modifier used by agents.
+// SpeedModifier returns the package-level movement modifier used by
+// Agent.Step.
+//
+// Deprecated: use Simulation.SpeedModifier.
 func SpeedModifier() float64 {
 	speedMu.RLock()
 	defer speedMu.RUnlock()
@@ -29,19 +42,126 @@ func SpeedModifier() float64 {
 }
 
 // Agent represents a moving participant in the simulation space.
-// The base speed is scaled by the global CurrentSpeedModifier.
+// The base speed is scaled by the owning simulation's speed modifier.
 type Agent struct {
 	X, Y       float64
 	DirectionX float64
 	DirectionY float64
 	BaseSpeed  float64
+	// Dead agents stay where they fell: they no longer move and must not be
+	// treated as contacts.
+	Dead bool
+	// Immune agents cannot be infected, for example after vaccination.
+	Immune bool
+	// Infected agents spread the infection to susceptible agents nearby in
+	// spatial mode; Recovered agents have had it and cannot catch it again.
+	Infected  bool
+	Recovered bool
+	// Region names the region the agent lives in, as passed to AddRegion.
+	// It is empty while the world is a single pool.
+	Region string
 }
 
-// Step advances the agent's position by deltaSeconds, applying the global
-// speed modifier to the base speed before movement.
-func (a *Agent) Step(deltaSeconds float64) {
-	modifier := SpeedModifier()
-	speed := a.BaseSpeed * modifier
+// susceptible reports whether the agent can still be infected.
+func (a *Agent) susceptible() bool {
+	return !a.Dead && !a.Immune && !a.Infected && !a.Recovered
+}
+
+// LivingAgents counts the agents that are still active in the space.
+func LivingAgents(agents []Agent) int {
+	living := 0
+	for i := range agents {
+		if !agents[i].Dead {
+			living++
+		}
+	}
+	return living
+}
+
+// Move advances the agent's position by deltaSeconds, applying speedModifier,
+// usually Simulation.SpeedModifier, to the base speed before movement. Dead
+// agents do not move.
+func (a *Agent) Move(deltaSeconds, speedModifier float64) {
+	if a.Dead {
+		return
+	}
+	speed := a.BaseSpeed * speedModifier
 	a.X += a.DirectionX * speed * deltaSeconds
 	a.Y += a.DirectionY * speed * deltaSeconds
 }
+
+// Step moves the agent with the package-level speed modifier.
+//
+// Deprecated: use Move with the owning simulation's SpeedModifier.
+func (a *Agent) Step(deltaSeconds float64) {
+	a.Move(deltaSeconds, SpeedModifier())
+}
+
+// MoveBounded moves the agent like Move but keeps it inside the
+// [0, width] x [0, height] world: an agent that crosses an edge bounces off
+// it, reversing that direction component, and its position is clamped to the
+// world in case one step would carry it past both edges.
+func (a *Agent) MoveBounded(deltaSeconds, speedModifier, width, height float64) {
+	if a.Dead {
+		return
+	}
+	a.Move(deltaSeconds, speedModifier)
+	a.X, a.DirectionX = bounce(a.X, a.DirectionX, width)
+	a.Y, a.DirectionY = bounce(a.Y, a.DirectionY, height)
+}
+
+// bounce reflects a coordinate off the walls at 0 and limit.
+func bounce(position, direction, limit float64) (float64, float64) {
+	switch {
+	case position < 0:
+		position, direction = -position, -direction
+	case position > limit:
+		position, direction = 2*limit-position, -direction
+	}
+	return min(max(position, 0), limit), direction
+}
+
+// EventVaccination marks a ring vaccination performed with VaccinateRegion.
+const EventVaccination EventKind = "vaccination"
+
+// VaccinateRegion models ring vaccination around an outbreak: of the
+// simulation's susceptible agents within radius of (x, y), a randomly chosen
+// fraction (rounded to the nearest agent) are marked immune. Infected,
+// recovered, dead, and already immune agents, and agents outside the region,
+// are untouched. In spatial mode the vaccinated agents move into the immune
+// count. The number vaccinated is returned and recorded as an
+// EventVaccination.
+func (s *Simulation) VaccinateRegion(x, y, radius, fraction float64) int {
+	fraction = min(max(fraction, 0), 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var eligible []int
+	for i := range s.agents {
+		a := &s.agents[i]
+		dx, dy := a.X-x, a.Y-y
+		if a.susceptible() && dx*dx+dy*dy <= radius*radius {
+			eligible = append(eligible, i)
+		}
+	}
+
+	count := int(float64(len(eligible))*fraction + 0.5)
+	s.rng.Shuffle(len(eligible), func(i, j int) {
+		eligible[i], eligible[j] = eligible[j], eligible[i]
+	})
+	for _, i := range eligible[:count] {
+		s.agents[i].Immune = true
+	}
+	if s.spatialLocked() {
+		s.syncAgentsLocked()
+	}
+
+	s.recordEventLocked(Event{
+		Tick:    s.tick,
+		Kind:    EventVaccination,
+		Count:   count,
+		Message: fmt.Sprintf("vaccinated %d of %d agents within %.1f of (%.1f, %.1f)", count, len(eligible), radius, x, y),
+	})
+	return count
+}
@@ -18,3 +18,109 @@ func TestAgentStepUsesSpeedModifier(t *testing.T) {
 		t.Fatalf("expected Y to remain unchanged, got %v", agent.Y)
 	}
 }
+
+func TestAgentMoveUsesSpeedModifier(t *testing.T) {
+	agent := Agent{BaseSpeed: 2, DirectionX: 1, DirectionY: 0}
+	agent.Move(1.0, 0.5)
+
+	if agent.X != 1.0 {
+		t.Fatalf("expected X to advance by 1.0, got %v", agent.X)
+	}
+	if agent.Y != 0 {
+		t.Fatalf("expected Y to remain unchanged, got %v", agent.Y)
+	}
+}
+
+func TestDeadAgentsStayPutAndAreNotCounted(t *testing.T) {
+	agents := []Agent{
+		{BaseSpeed: 1, DirectionX: 1},
+		{BaseSpeed: 1, DirectionX: 1, Dead: true},
+		{BaseSpeed: 1, DirectionY: 1},
+	}
+	for i := range agents {
+		agents[i].Move(1.0, 1.0)
+	}
+
+	if agents[1].X != 0 || agents[1].Y != 0 {
+		t.Fatalf("expected dead agent to stay at the origin, got (%v, %v)", agents[1].X, agents[1].Y)
+	}
+	if agents[0].X == 0 {
+		t.Fatal("expected living agent to move")
+	}
+	if got := LivingAgents(agents); got != 2 {
+		t.Fatalf("expected 2 living agents, got %d", got)
+	}
+}
+
+func TestVaccinateRegionOnlyTouchesAgentsInside(t *testing.T) {
+	s := New(0.25)
+	for i := 0; i < 10; i++ {
+		s.AddAgent(Agent{X: float64(i) * 0.1, Y: 0}) // inside radius 1 of the origin
+	}
+	s.AddAgent(Agent{X: 5, Y: 5})
+	s.AddAgent(Agent{X: 0.5, Y: 0.5, Dead: true})
+
+	if got := s.VaccinateRegion(0, 0, 1, 0.4); got != 4 {
+		t.Fatalf("expected 40%% of the 10 agents in the region to be vaccinated, got %d", got)
+	}
+
+	agents := s.Agents()
+	immune := 0
+	for _, agent := range agents[:10] {
+		if agent.Immune {
+			immune++
+		}
+	}
+	if immune != 4 {
+		t.Fatalf("expected 4 immune agents in the region, got %d", immune)
+	}
+	if agents[10].Immune || agents[11].Immune {
+		t.Fatal("expected agents outside the region and dead agents to be left alone")
+	}
+
+	events := s.Events()
+	if len(events) != 1 || events[0].Kind != EventVaccination || events[0].Count != 4 {
+		t.Fatalf("expected one vaccination event for 4 agents, got %+v", events)
+	}
+}
+
+func TestVaccinateRegionSkipsInfectedAndRecoveredAgents(t *testing.T) {
+	s := New(0.25)
+	s.AddAgent(Agent{X: 0.1, Infected: true})
+	s.AddAgent(Agent{X: 0.2, Recovered: true})
+	s.AddAgent(Agent{X: 0.3})
+	s.AddAgent(Agent{X: 0.4})
+	s.SetInfectionRadius(0.01)
+
+	if got := s.VaccinateRegion(0, 0, 1, 1); got != 2 {
+		t.Fatalf("expected only the 2 susceptible agents to be vaccinated, got %d", got)
+	}
+	agents := s.Agents()
+	if agents[0].Immune || agents[1].Immune {
+		t.Fatalf("expected infected and recovered agents to be left alone, got %+v", agents[:2])
+	}
+
+	state := s.Snapshot()
+	if state.CurrentImmune != 2 || state.CurrentSusceptible != 0 || state.CurrentInfected != 1 || state.CurrentRecovered != 1 {
+		t.Fatalf("expected 2 immune, 1 infected, 1 recovered, and none susceptible, got %+v", state)
+	}
+}
+
+func TestMoveBoundedBouncesOffWalls(t *testing.T) {
+	agent := Agent{X: 9, Y: 1, DirectionX: 1, DirectionY: -1, BaseSpeed: 2}
+	agent.MoveBounded(1.0, 1.0, 10, 10)
+
+	if agent.X != 9 || agent.DirectionX != -1 {
+		t.Fatalf("expected a bounce off the right wall to (9, -1), got (%v, %v)", agent.X, agent.DirectionX)
+	}
+	if agent.Y != 1 || agent.DirectionY != 1 {
+		t.Fatalf("expected a bounce off the floor to (1, 1), got (%v, %v)", agent.Y, agent.DirectionY)
+	}
+
+	// A step longer than the world is clamped inside it.
+	agent = Agent{X: 5, DirectionX: 1, BaseSpeed: 100}
+	agent.MoveBounded(1.0, 1.0, 10, 10)
+	if agent.X < 0 || agent.X > 10 {
+		t.Fatalf("expected the agent to stay inside the world, got X=%v", agent.X)
+	}
+}
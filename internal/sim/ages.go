@@ -0,0 +1,188 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AgeBracket is one age group of the population, such as "0-17" or "65+".
+type AgeBracket struct {
+	Name string `json:"name"`
+	// Fraction is the bracket's share of the population. Fractions are
+	// normalised, so they need not sum to 1.
+	Fraction float64 `json:"fraction"`
+	// DeathMultiplier scales the per-tick death probability of the
+	// bracket's infected people.
+	DeathMultiplier float64 `json:"death_multiplier"`
+}
+
+// AgeBracketSnapshot captures one age bracket's state. TotalDeaths counts
+// deaths attributed to the bracket since the brackets were set.
+type AgeBracketSnapshot struct {
+	Name            string  `json:"name"`
+	Fraction        float64 `json:"fraction"`
+	DeathMultiplier float64 `json:"death_multiplier"`
+	CurrentInfected int     `json:"current_infected"`
+	TotalDeaths     int     `json:"total_deaths"`
+}
+
+// ageBracket is an AgeBracket with its running counts.
+type ageBracket struct {
+	name            string
+	fraction        float64
+	deathMultiplier float64
+	infected        int
+	deaths          int
+}
+
+// SetAgeBrackets splits the population into age brackets, each with its own
+// death multiplier. Current infections are shared out by fraction and the
+// per-bracket death counts start from zero. Passing no brackets restores the
+// default single uniform bracket.
+//
+// New cases are attributed to brackets by population fraction. With the
+// memoryless outcome model each bracket's deaths are drawn at the pathogen's
+// death probability times its multiplier; the other outcome models use the
+// infected-weighted mean multiplier and attribute deaths in proportion to it.
+// Age brackets apply to the single pool, not to regions or spatial mode.
+func (s *Simulation) SetAgeBrackets(brackets []AgeBracket) error {
+	total := 0.0
+	seen := make(map[string]bool, len(brackets))
+	for _, b := range brackets {
+		if b.Name == "" {
+			return errors.New("age bracket name must not be empty")
+		}
+		if seen[b.Name] {
+			return fmt.Errorf("age bracket %q appears twice", b.Name)
+		}
+		seen[b.Name] = true
+		if !validMultiplier(b.Fraction) || !validMultiplier(b.DeathMultiplier) {
+			return fmt.Errorf("age bracket %q fraction and death multiplier must be finite and non-negative, got %v and %v",
+				b.Name, b.Fraction, b.DeathMultiplier)
+		}
+		total += b.Fraction
+	}
+	if len(brackets) > 0 && total == 0 {
+		return errors.New("age bracket fractions must not all be zero")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ageBrackets = nil
+	for _, b := range brackets {
+		s.ageBrackets = append(s.ageBrackets, ageBracket{
+			name:            b.Name,
+			fraction:        b.Fraction / total,
+			deathMultiplier: b.DeathMultiplier,
+		})
+	}
+	s.reconcileAgesLocked(0)
+	return nil
+}
+
+// AgeBrackets returns every age bracket in the order they were set, or nil
+// for the default single uniform bracket.
+func (s *Simulation) AgeBrackets() []AgeBracketSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ageSnapshotsLocked()
+}
+
+func (s *Simulation) ageSnapshotsLocked() []AgeBracketSnapshot {
+	if len(s.ageBrackets) == 0 {
+		return nil
+	}
+
+	snapshots := make([]AgeBracketSnapshot, len(s.ageBrackets))
+	for i, b := range s.ageBrackets {
+		snapshots[i] = AgeBracketSnapshot{
+			Name:            b.name,
+			Fraction:        b.fraction,
+			DeathMultiplier: b.deathMultiplier,
+			CurrentInfected: b.infected,
+			TotalDeaths:     b.deaths,
+		}
+	}
+	return snapshots
+}
+
+// ageMultiplierLocked is the infected-weighted mean age death multiplier, or
+// 1 with the default bracket or nobody infected.
+func (s *Simulation) ageMultiplierLocked() float64 {
+	weighted, infected := 0.0, 0
+	for _, b := range s.ageBrackets {
+		weighted += float64(b.infected) * b.deathMultiplier
+		infected += b.infected
+	}
+	if infected == 0 {
+		return 1
+	}
+	return weighted / float64(infected)
+}
+
+// resolveAgesLocked draws this tick's deaths and recoveries bracket by
+// bracket, each at its own death probability.
+func (s *Simulation) resolveAgesLocked() (deaths, recoveries int) {
+	for i := range s.ageBrackets {
+		b := &s.ageBrackets[i]
+		deathProbability, _ := s.deathProbabilityAtLocked(b.deathMultiplier)
+		died, recovered := s.resolveOutcomesLocked(b.infected, deathProbability, s.recoveryProbabilityLocked())
+		b.infected -= died + recovered
+		b.deaths += died
+		deaths += died
+		recoveries += recovered
+	}
+	return deaths, recoveries
+}
+
+// reconcileAgesLocked brings the bracket counts in line with the infected
+// count after it changed. New infections are attributed by population
+// fraction; people leaving are drawn by infected count, with the first
+// deaths of them weighted by the death multiplier and counted as deaths.
+func (s *Simulation) reconcileAgesLocked(deaths int) {
+	if len(s.ageBrackets) == 0 {
+		return
+	}
+
+	total := 0
+	for _, b := range s.ageBrackets {
+		total += b.infected
+	}
+	weights := make([]float64, len(s.ageBrackets))
+	for i, b := range s.ageBrackets {
+		weights[i] = b.fraction
+	}
+	for ; total < s.currentInfected; total++ {
+		s.ageBrackets[s.pickWeightedLocked(weights)].infected++
+	}
+	for ; total > s.currentInfected; total-- {
+		for i, b := range s.ageBrackets {
+			weights[i] = float64(b.infected)
+			if deaths > 0 {
+				weights[i] *= b.deathMultiplier
+			}
+		}
+		if sumWeights(weights) == 0 {
+			for i, b := range s.ageBrackets {
+				weights[i] = float64(b.infected)
+			}
+		}
+		picked := &s.ageBrackets[s.pickWeightedLocked(weights)]
+		picked.infected--
+		if deaths > 0 {
+			picked.deaths++
+		}
+		deaths--
+	}
+}
+
+// resetAgesLocked clears the bracket counts and shares the current
+// infections out afresh, for a run that starts over.
+func (s *Simulation) resetAgesLocked() {
+	for i := range s.ageBrackets {
+		s.ageBrackets[i].infected, s.ageBrackets[i].deaths = 0, 0
+	}
+	s.reconcileAgesLocked(0)
+}
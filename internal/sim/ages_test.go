@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAgeBracketsSampleDeathsPerBracket(t *testing.T) {
+	s := NewWithSeed(0.2, 5)
+	s.SetPopulation(0)
+	s.baseDeathRate = 0.05
+	s.SetRecoveryRate(0.2)
+	if err := s.SetAgeBrackets([]AgeBracket{
+		{Name: "0-17", Fraction: 1, DeathMultiplier: 0},
+		{Name: "18-64", Fraction: 2, DeathMultiplier: 1},
+		{Name: "65+", Fraction: 1, DeathMultiplier: 10},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var state Snapshot
+	for i := 0; i < 40; i++ {
+		state = s.Step()
+		infected, deaths := 0, 0
+		for _, b := range state.AgeBrackets {
+			infected += b.CurrentInfected
+			deaths += b.TotalDeaths
+		}
+		if infected != state.CurrentInfected || deaths != state.TotalDeaths {
+			t.Fatalf("tick %d: brackets add up to %d infected and %d deaths, want %d and %d",
+				state.Tick, infected, deaths, state.CurrentInfected, state.TotalDeaths)
+		}
+	}
+
+	young, adults, old := state.AgeBrackets[0], state.AgeBrackets[1], state.AgeBrackets[2]
+	if young.Fraction != 0.25 || adults.Fraction != 0.5 {
+		t.Fatalf("expected fractions normalised to 0.25 and 0.5, got %v and %v", young.Fraction, adults.Fraction)
+	}
+	if young.TotalDeaths != 0 {
+		t.Fatalf("expected no deaths in a bracket with multiplier 0, got %d", young.TotalDeaths)
+	}
+	if old.TotalDeaths <= adults.TotalDeaths {
+		t.Fatalf("expected the 65+ bracket to suffer the most deaths, got %d against %d", old.TotalDeaths, adults.TotalDeaths)
+	}
+}
+
+func TestDefaultAgeBracketKeepsBehaviour(t *testing.T) {
+	plain, uniform := NewWithSeed(0.2, 9), NewWithSeed(0.2, 9)
+	if err := uniform.SetAgeBrackets(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 30; i++ {
+		if want, got := plain.Step(), uniform.Step(); !reflect.DeepEqual(want, got) {
+			t.Fatalf("tick %d: expected identical snapshots, got %+v and %+v", i, want, got)
+		}
+	}
+	if brackets := uniform.AgeBrackets(); brackets != nil {
+		t.Fatalf("expected no brackets by default, got %+v", brackets)
+	}
+}
+
+func TestSetAgeBracketsRejectsBadInput(t *testing.T) {
+	s := NewWithSeed(0.2, 9)
+	for name, brackets := range map[string][]AgeBracket{
+		"empty name":     {{Name: "", Fraction: 1, DeathMultiplier: 1}},
+		"duplicate":      {{Name: "a", Fraction: 1, DeathMultiplier: 1}, {Name: "a", Fraction: 1, DeathMultiplier: 1}},
+		"negative":       {{Name: "a", Fraction: -1, DeathMultiplier: 1}},
+		"zero fractions": {{Name: "a", Fraction: 0, DeathMultiplier: 1}},
+	} {
+		if err := s.SetAgeBrackets(brackets); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package sim
+
+import "math"
+
+// BehavioralResponse describes how people voluntarily cut their contacts as
+// infections rise. The reduction follows a logistic curve in the infected
+// count: MaxReduction / (1 + exp(-Steepness * (infected - Midpoint))).
+type BehavioralResponse struct {
+	// MaxReduction is the largest fraction of contacts people will give up,
+	// in [0, 1]. Zero disables the response.
+	MaxReduction float64 `json:"max_reduction"`
+	// Midpoint is the infected count at which half the maximum reduction
+	// applies.
+	Midpoint float64 `json:"midpoint"`
+	// Steepness controls how sharply behaviour changes around Midpoint.
+	Steepness float64 `json:"steepness"`
+}
+
+// SetBehavioralResponse configures voluntary contact reduction. It scales
+// transmission every tick alongside any mandated interventions (see
+// SetInterventionCombination), producing self-limiting epidemics even without
+// a lockdown. Out-of-range values are
+// clamped.
+func (s *Simulation) SetBehavioralResponse(response BehavioralResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.behavior = BehavioralResponse{
+		MaxReduction: math.Min(math.Max(response.MaxReduction, 0), 1),
+		Midpoint:     math.Max(response.Midpoint, 0),
+		Steepness:    math.Max(response.Steepness, 0),
+	}
+}
+
+// BehavioralResponse returns the configured voluntary contact reduction.
+func (s *Simulation) BehavioralResponse() BehavioralResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.behavior
+}
+
+// voluntaryReductionLocked returns the fraction of contacts people currently
+// avoid of their own accord.
+func (s *Simulation) voluntaryReductionLocked() float64 {
+	b := s.behavior
+	if b.MaxReduction == 0 {
+		return 0
+	}
+	return b.MaxReduction / (1 + math.Exp(-b.Steepness*(float64(s.currentInfected)-b.Midpoint)))
+}
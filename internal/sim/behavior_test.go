@@ -0,0 +1,37 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBehavioralResponseCutsContactsAsPrevalenceRises(t *testing.T) {
+	newSim := func(infected int) *Simulation {
+		s := New(1)
+		s.currentInfected = infected
+		s.SetBehavioralResponse(BehavioralResponse{MaxReduction: 0.8, Midpoint: 100, Steepness: 0.1})
+		return s
+	}
+
+	low := newSim(10).Snapshot()
+	if low.VoluntaryReduction > 1e-3 || low.InfectionProbability < 0.999 {
+		t.Fatalf("expected low prevalence to keep nearly all contacts, got reduction %v and probability %v",
+			low.VoluntaryReduction, low.InfectionProbability)
+	}
+
+	high := newSim(300).Snapshot()
+	if high.VoluntaryReduction <= low.VoluntaryReduction || high.VoluntaryReduction < 0.79 {
+		t.Fatalf("expected reduction to rise with prevalence, got %v at 10 and %v at 300", low.VoluntaryReduction, high.VoluntaryReduction)
+	}
+	if math.Abs(high.InfectionProbability-(1-high.VoluntaryReduction)) > 1e-9 {
+		t.Fatalf("expected effective contacts cut to %v, got probability %v", 1-high.VoluntaryReduction, high.InfectionProbability)
+	}
+}
+
+func TestBehavioralResponseDisabledByDefault(t *testing.T) {
+	s := New(0.25)
+	s.currentInfected = 1000
+	if got := s.Snapshot().VoluntaryReduction; got != 0 {
+		t.Fatalf("expected no voluntary reduction by default, got %v", got)
+	}
+}
@@ -0,0 +1,59 @@
+package sim
+
+// EventBreakpoint marks a tick on which a PauseWhen predicate paused Run.
+const EventBreakpoint EventKind = "breakpoint"
+
+// PauseWhen installs a breakpoint: Run pauses itself after the first tick
+// whose snapshot satisfies predicate and records an EventBreakpoint. The
+// breakpoint is cleared once it fires unless SetBreakpointRepeat(true) was
+// called. The predicate runs on the Run goroutine without the simulation lock
+// held, so it may call back into the simulation. A nil predicate clears the
+// breakpoint.
+func (s *Simulation) PauseWhen(predicate func(Snapshot) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.breakpoint = predicate
+}
+
+// ClearBreakpoint removes any breakpoint installed with PauseWhen.
+func (s *Simulation) ClearBreakpoint() {
+	s.PauseWhen(nil)
+}
+
+// SetBreakpointRepeat keeps the breakpoint installed after it fires, so Run
+// pauses again on the next matching tick after Resume.
+func (s *Simulation) SetBreakpointRepeat(repeat bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.breakpointRepeat = repeat
+}
+
+// checkBreakpoint evaluates the breakpoint against the snapshot Run just
+// produced and pauses the simulation if it matches. Ticks spent paused are
+// not evaluated.
+func (s *Simulation) checkBreakpoint(state Snapshot) {
+	s.mu.RLock()
+	predicate := s.breakpoint
+	paused := s.paused
+	s.mu.RUnlock()
+
+	if predicate == nil || paused || !predicate(state) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = true
+	if !s.breakpointRepeat {
+		s.breakpoint = nil
+	}
+	s.recordEventLocked(Event{
+		Tick:    state.Tick,
+		Kind:    EventBreakpoint,
+		Message: "breakpoint hit",
+	})
+	s.logger.Printf("breakpoint hit at tick %d; simulation paused", state.Tick)
+}
@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPauseWhenStopsRunAtThreshold(t *testing.T) {
+	s := New(0.9)
+	s.SetLogger(nil)
+	s.rng = rand.New(rand.NewSource(1))
+	s.baseDeathRate = 0
+	s.PauseWhen(func(state Snapshot) bool {
+		return state.CurrentInfected >= 30
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx, time.Millisecond, nil)
+
+	deadline := time.After(time.Second)
+	for !s.Paused() {
+		select {
+		case <-deadline:
+			t.Fatal("breakpoint never paused the run")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	state := s.Snapshot()
+	if state.CurrentInfected < 30 {
+		t.Fatalf("expected to pause once infected reached 30, got %d", state.CurrentInfected)
+	}
+
+	events := s.Events()
+	if len(events) != 1 || events[0].Kind != EventBreakpoint || events[0].Tick != state.Tick {
+		t.Fatalf("expected one breakpoint event at tick %d, got %+v", state.Tick, events)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if got := s.Snapshot().Tick; got != state.Tick {
+		t.Fatalf("expected paused run to hold tick %d, got %d", state.Tick, got)
+	}
+
+	s.Resume()
+	time.Sleep(10 * time.Millisecond)
+	if s.Paused() {
+		t.Fatal("expected one-shot breakpoint to be cleared after firing")
+	}
+}
+
+func TestRepeatingBreakpointFiresAgainAfterResume(t *testing.T) {
+	s := New(0.2)
+	s.SetLogger(nil)
+	s.SetBreakpointRepeat(true)
+	s.PauseWhen(func(Snapshot) bool { return true })
+
+	state, _ := s.advance()
+	s.checkBreakpoint(state)
+	if !s.Paused() {
+		t.Fatal("expected the breakpoint to pause the simulation")
+	}
+
+	s.Resume()
+	state, _ = s.advance()
+	s.checkBreakpoint(state)
+	if !s.Paused() {
+		t.Fatal("expected a repeating breakpoint to pause again")
+	}
+	if got := len(s.Events()); got != 2 {
+		t.Fatalf("expected two breakpoint events, got %d", got)
+	}
+}
@@ -0,0 +1,47 @@
+package sim
+
+// BurdenWeights converts each tick's harms into a single cost score so
+// intervention strategies can be compared on one number.
+type BurdenWeights struct {
+	// Death is charged per death.
+	Death float64 `json:"death"`
+	// OverloadTick is charged for every tick hospitals are over capacity.
+	OverloadTick float64 `json:"overload_tick"`
+	// LockdownTick is the economic cost of every tick spent under lockdown.
+	LockdownTick float64 `json:"lockdown_tick"`
+}
+
+// DefaultBurdenWeights weighs a death like ten ticks of hospital overload or
+// twenty ticks of lockdown.
+var DefaultBurdenWeights = BurdenWeights{Death: 1, OverloadTick: 0.1, LockdownTick: 0.05}
+
+// SetBurdenWeights replaces the weights used for future ticks. Negative
+// weights are clamped to zero; the burden accrued so far is kept.
+func (s *Simulation) SetBurdenWeights(weights BurdenWeights) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.burdenWeights = BurdenWeights{
+		Death:        max(weights.Death, 0),
+		OverloadTick: max(weights.OverloadTick, 0),
+		LockdownTick: max(weights.LockdownTick, 0),
+	}
+}
+
+// BurdenWeights returns the weights applied to each tick's harms.
+func (s *Simulation) BurdenWeights() BurdenWeights {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.burdenWeights
+}
+
+func (s *Simulation) accrueBurdenLocked(deaths int) {
+	s.burden += float64(deaths) * s.burdenWeights.Death
+	if _, overloaded := s.deathProbabilityLocked(); overloaded {
+		s.burden += s.burdenWeights.OverloadTick
+	}
+	if s.lockdownEnabled {
+		s.burden += s.burdenWeights.LockdownTick
+	}
+}
@@ -0,0 +1,36 @@
+package sim
+
+import "testing"
+
+func TestBurdenAccruesUnderOverloadAndLockdown(t *testing.T) {
+	s := New(0.2)
+	s.UpdateTransmissionModifier(0)
+	s.baseDeathRate = 0
+	s.SetBurdenWeights(BurdenWeights{Death: 5, OverloadTick: 2, LockdownTick: 1})
+
+	s.Step()
+	if got := s.Snapshot().Burden; got != 0 {
+		t.Fatalf("expected no burden without deaths, overload, or lockdown, got %v", got)
+	}
+
+	s.SetHospitalCapacity(1)
+	s.Step()
+	if got := s.Snapshot().Burden; got != 2 {
+		t.Fatalf("expected an overload tick to add 2, got %v", got)
+	}
+
+	s.SetLockdown(true)
+	s.Step()
+	if got := s.Snapshot().Burden; got != 5 {
+		t.Fatalf("expected overload plus lockdown to add 3 more, got %v", got)
+	}
+
+	s.SetHospitalCapacity(0)
+	s.SetLockdown(false)
+	s.baseDeathRate = 1
+	infected := s.CurrentInfected()
+	s.Step()
+	if got, want := s.Snapshot().Burden, 5+5*float64(infected); got != want {
+		t.Fatalf("expected %d deaths to add %v, got burden %v", infected, 5*float64(infected), got)
+	}
+}
@@ -0,0 +1,55 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// defaultDaysPerTick makes one tick one simulated day.
+const defaultDaysPerTick = 1.0
+
+// SetDaysPerTick sets how much simulated calendar time one tick represents.
+// The new ratio applies from the next tick on; the days already elapsed are
+// kept. The infectious period is given in days and converted to ticks with
+// this ratio, so changing it changes how many ticks an infection lasts.
+// Everything else stays per tick and is not rescaled: the transmission,
+// death, recovery, detection, and import rates, the contact counts, and the
+// incubation, immunity, and tracing durations. Non-positive ratios are
+// rejected.
+func (s *Simulation) SetDaysPerTick(days float64) error {
+	if days <= 0 || math.IsNaN(days) || math.IsInf(days, 0) {
+		return fmt.Errorf("days per tick must be positive and finite, got %v", days)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.daysPerTick = days
+	return nil
+}
+
+// DaysPerTick returns the simulated days represented by one tick.
+func (s *Simulation) DaysPerTick() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.daysPerTickLocked()
+}
+
+func (s *Simulation) daysPerTickLocked() float64 {
+	if s.daysPerTick <= 0 {
+		return defaultDaysPerTick
+	}
+	return s.daysPerTick
+}
+
+// ticksForDaysLocked converts a duration in days to a whole number of ticks,
+// never less than one.
+func (s *Simulation) ticksForDaysLocked(days int) int {
+	return max(int(math.Round(float64(days)/s.daysPerTickLocked())), 1)
+}
+
+// infectiousTicksLocked is the infectious period expressed in ticks.
+func (s *Simulation) infectiousTicksLocked() int {
+	return s.ticksForDaysLocked(s.infectiousPeriod)
+}
@@ -0,0 +1,75 @@
+package sim
+
+import "testing"
+
+func TestSimulatedDayFollowsDaysPerTick(t *testing.T) {
+	s := New(0.25)
+	if err := s.SetDaysPerTick(0.5); err != nil {
+		t.Fatalf("set days per tick: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		s.Step()
+	}
+	if got := s.Snapshot().SimulatedDay; got != 1.5 {
+		t.Fatalf("expected day 1.5 after three half-day ticks, got %v", got)
+	}
+
+	if err := s.SetDaysPerTick(2); err != nil {
+		t.Fatalf("set days per tick: %v", err)
+	}
+	if got := s.Snapshot().SimulatedDay; got != 1.5 {
+		t.Fatalf("expected a new ratio to leave the elapsed 1.5 days alone, got %v", got)
+	}
+	s.Step()
+	if got := s.Snapshot().SimulatedDay; got != 3.5 {
+		t.Fatalf("expected day 3.5 after a two-day tick, got %v", got)
+	}
+
+	if err := s.SetDaysPerTick(0); err == nil {
+		t.Fatal("expected a zero ratio to be rejected")
+	}
+	if got := s.DaysPerTick(); got != 2 {
+		t.Fatalf("expected rejected ratio to leave 2 in place, got %v", got)
+	}
+}
+
+func TestInfectiousPeriodConvertsDaysToTicks(t *testing.T) {
+	s := New(0.25)
+	s.SetOutcomeModel(OutcomeScheduled, 14)
+	if err := s.SetDaysPerTick(7); err != nil {
+		t.Fatalf("set days per tick: %v", err)
+	}
+
+	s.mu.Lock()
+	s.outcomes = nil
+	s.scheduleOutcomesLocked(5)
+	slots := len(s.outcomes)
+	s.mu.Unlock()
+
+	if slots != 2 {
+		t.Fatalf("expected a 14-day infection to span 2 weekly ticks, got %d", slots)
+	}
+}
+
+func TestCalibrationUsesInfectiousTicks(t *testing.T) {
+	daily := NewWithSeed(0.25, 1)
+	daily.SetOutcomeModel(OutcomeScheduled, 7)
+	want, err := daily.CalibrateToR0(2, 5)
+	if err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+
+	twoDay := NewWithSeed(0.25, 1)
+	if err := twoDay.SetDaysPerTick(2); err != nil {
+		t.Fatalf("set days per tick: %v", err)
+	}
+	twoDay.SetOutcomeModel(OutcomeScheduled, 14)
+	got, err := twoDay.CalibrateToR0(2, 5)
+	if err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+	if got != want {
+		t.Fatalf("expected a 14-day period at two days per tick to calibrate like 7 daily ticks: got %v, want %v", got, want)
+	}
+}
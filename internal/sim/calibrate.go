@@ -0,0 +1,109 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// calibrationSeed fixes the warmup draws so every candidate base transmission
+// is judged on the same random numbers and the bisection stays stable.
+const calibrationSeed = 1
+
+// warmupState is a copy of the simulation, encoded as a checkpoint without
+// its history or event log, that each warmup decodes afresh so no run sees
+// another's changes.
+type warmupState []byte
+
+// CalibrateToR0 bisects the base transmission until short warmup runs, each
+// on a copy of the simulation as it stands with every setting in place, show
+// an early-phase reproduction number of target. R0 is estimated as new infections per
+// infected individual per tick, multiplied by the mean infectious duration in
+// ticks under the active outcome rules, the same measure the effective
+// reproduction number uses. The calibrated base is applied to the active
+// pathogen and returned.
+func (s *Simulation) CalibrateToR0(target float64, warmupTicks int) (float64, error) {
+	if target <= 0 {
+		return 0, fmt.Errorf("target R0 must be positive, got %v", target)
+	}
+	if warmupTicks <= 0 {
+		return 0, fmt.Errorf("warmup ticks must be positive, got %d", warmupTicks)
+	}
+
+	s.mu.RLock()
+	state, err := s.warmupStateLocked()
+	s.mu.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+
+	reached, err := estimateR0(state, 1, warmupTicks, calibrationSeed)
+	if err != nil {
+		return 0, err
+	}
+	if reached < target {
+		return 0, fmt.Errorf("target R0 %v is out of reach even at base transmission 1", target)
+	}
+
+	low, high := 0.0, 1.0
+	for i := 0; i < 40; i++ {
+		mid := (low + high) / 2
+		r0, err := estimateR0(state, mid, warmupTicks, calibrationSeed)
+		if err != nil {
+			return 0, err
+		}
+		if r0 < target {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	base := high
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.baseTransmission = base
+	profile := s.pathogens[s.activePathogen]
+	profile.BaseTransmission = base
+	s.pathogens[s.activePathogen] = profile
+	return base, nil
+}
+
+// warmupStateLocked copies the simulation for the warmups.
+func (s *Simulation) warmupStateLocked() (warmupState, error) {
+	saved := s.modelStateLocked()
+	saved.Events, saved.History = nil, nil
+	state, err := json.Marshal(saved)
+	if err != nil {
+		return nil, fmt.Errorf("copy simulation for warmup: %w", err)
+	}
+	return state, nil
+}
+
+// estimateR0 runs a throwaway warmup on a copy of the simulation with the
+// given base transmission and measures per-capita incidence times the mean
+// infectious duration. Imported cases are not counted as incidence.
+func estimateR0(state warmupState, base float64, ticks int, seed int64) (float64, error) {
+	var saved savedState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return 0, fmt.Errorf("restore warmup: %w", err)
+	}
+	warmup := NewWithSeed(base, seed)
+	warmup.restoreLocked(saved)
+	warmup.seedLocked(seed)
+	warmup.baseTransmission = base
+	warmup.currentInfected = max(warmup.currentInfected, 1)
+	infectiousTicks := warmup.meanInfectiousTicksLocked()
+
+	newInfections, infectedTicks := 0, 0
+	for i := 0; i < ticks; i++ {
+		infected, infections := warmup.currentInfected, warmup.totalInfections
+		warmup.stepEpidemicLocked()
+		infectedTicks += infected
+		newInfections += warmup.totalInfections - infections - warmup.imported
+	}
+	if infectedTicks == 0 {
+		return 0, nil
+	}
+	return float64(newInfections) / float64(infectedTicks) * infectiousTicks, nil
+}
@@ -0,0 +1,90 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalibrateToR0MatchesTarget(t *testing.T) {
+	const (
+		target = 2.0
+		warmup = 5
+	)
+
+	s := New(0.25)
+	s.SetRecoveryRate(0.06)
+	if err := s.ScheduleImport(1, 3); err != nil {
+		t.Fatalf("schedule import: %v", err)
+	}
+	base, err := s.CalibrateToR0(target, warmup)
+	if err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+	if base <= 0 || base > 1 {
+		t.Fatalf("expected a base transmission in (0, 1], got %v", base)
+	}
+	if got := s.Snapshot().BaseTransmission; got != base {
+		t.Fatalf("expected the calibrated base %v to be applied, got %v", base, got)
+	}
+	if got := s.StepN(1).Imported; got != 3 {
+		t.Fatalf("expected the warmups to leave the scheduled import for the run, got %d imported", got)
+	}
+
+	// Check against warmups on draws the bisection never saw.
+	s.mu.RLock()
+	state, err := s.warmupStateLocked()
+	s.mu.RUnlock()
+	if err != nil {
+		t.Fatalf("copy simulation: %v", err)
+	}
+	total := 0.0
+	const seeds = 20
+	for seed := int64(100); seed < 100+seeds; seed++ {
+		r0, err := estimateR0(state, base, warmup, seed)
+		if err != nil {
+			t.Fatalf("estimate R0: %v", err)
+		}
+		total += r0
+	}
+	if average := total / seeds; math.Abs(average-target) > 0.15*target {
+		t.Fatalf("expected R0 near %v with base %v, measured %v", target, base, average)
+	}
+}
+
+func TestCalibrateToR0RejectsUnreachableTarget(t *testing.T) {
+	s := New(0.25)
+	if _, err := s.CalibrateToR0(1e6, 10); err == nil {
+		t.Fatal("expected an unreachable target to be rejected")
+	}
+	if got := s.Snapshot().BaseTransmission; got != 0.25 {
+		t.Fatalf("expected base transmission to stay at 0.25, got %v", got)
+	}
+}
+
+func TestEffectiveRMatchesCalibratedR0(t *testing.T) {
+	const target = 2.0
+
+	s := NewWithSeed(0.25, 3)
+	s.SetLogger(nil)
+	// Half the population is immune, which a warmup that ignored the
+	// population would miss, calibrating a base that gives Rt near 1.
+	cfg := s.Config()
+	cfg.Population, cfg.InitialInfected, cfg.InitialImmune = 10000, 200, 5000
+	if err := s.ApplyConfig(cfg); err != nil {
+		t.Fatalf("apply config: %v", err)
+	}
+	s.SetRecoveryRate(0.02)
+	if _, err := s.CalibrateToR0(target, 10); err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+
+	total := 0.0
+	const ticks = 5
+	for i := 0; i < ticks; i++ {
+		s.Step()
+		total += s.EffectiveReproductionNumber()
+	}
+	if average := total / ticks; math.Abs(average-target) > 0.15*target {
+		t.Fatalf("expected Rt near the calibrated R0 %v, got %v", target, average)
+	}
+}
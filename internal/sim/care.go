@@ -0,0 +1,56 @@
+package sim
+
+import "math"
+
+// ampleCareOccupancy is the share of hospital capacity at or below which the
+// care bonus applies.
+const ampleCareOccupancy = 0.5
+
+// SetCareBonus sets how much good care speeds recovery: while infections use
+// at most half the hospital capacity, the per-tick recovery probability is
+// multiplied by factor. It mirrors the overload multiplier on deaths and
+// rewards keeping hospitals uncrowded. Values below 1 are raised to 1, the
+// default, which leaves recovery untouched. Without a hospital capacity
+// there is no bonus.
+func (s *Simulation) SetCareBonus(factor float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if math.IsNaN(factor) {
+		factor = 1
+	}
+	s.careBonus = math.Max(factor, 1)
+}
+
+// CareBonus returns the recovery multiplier applied while hospitals have
+// ample room.
+func (s *Simulation) CareBonus() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.careBonus
+}
+
+// EffectiveRecoveryRate returns the per-tick recovery probability after the
+// care bonus.
+func (s *Simulation) EffectiveRecoveryRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.recoveryProbabilityLocked()
+}
+
+// recoveryProbabilityLocked is the per-tick recovery probability for the
+// whole infected pool.
+func (s *Simulation) recoveryProbabilityLocked() float64 {
+	return s.recoveryProbabilityForLocked(s.currentInfected, s.hospitalCapacity)
+}
+
+// recoveryProbabilityForLocked is the per-tick recovery probability for
+// infected people sharing a hospital of the given capacity.
+func (s *Simulation) recoveryProbabilityForLocked(infected, capacity int) float64 {
+	if s.careBonus > 1 && capacity > 0 && float64(infected) <= ampleCareOccupancy*float64(capacity) {
+		return math.Min(s.recoveryRate*s.careBonus, 1)
+	}
+	return s.recoveryRate
+}
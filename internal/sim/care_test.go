@@ -0,0 +1,40 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCareBonusSpeedsRecoveryWithAmpleCapacity(t *testing.T) {
+	recovered := func(capacity int, bonus float64) (int, float64) {
+		s := NewWithSeed(0.25, 9)
+		s.UpdateTransmissionModifier(0)
+		s.SetRecoveryRate(0.05)
+		s.SetHospitalCapacity(capacity)
+		s.SetCareBonus(bonus)
+		rate := s.Snapshot().EffectiveRecoveryRate
+		return s.StepN(10).TotalRecoveries, rate
+	}
+
+	plain, plainRate := recovered(100, 1)
+	cared, caredRate := recovered(100, 3)
+	if plainRate != 0.05 || math.Abs(caredRate-0.15) > 1e-12 {
+		t.Fatalf("expected effective recovery rates 0.05 and 0.15, got %v and %v", plainRate, caredRate)
+	}
+	if cared <= plain {
+		t.Fatalf("expected good care to speed recovery: %d recoveries without the bonus, %d with", plain, cared)
+	}
+
+	// Ten infected in a hospital of 15 is more than half full: no bonus.
+	if _, rate := recovered(15, 3); rate != 0.05 {
+		t.Fatalf("expected a crowded hospital to get no care bonus, got %v", rate)
+	}
+}
+
+func TestCareBonusBelowOneIsRaised(t *testing.T) {
+	s := New(0.25)
+	s.SetCareBonus(0.5)
+	if got := s.CareBonus(); got != 1 {
+		t.Fatalf("expected a care bonus below 1 to be raised to 1, got %v", got)
+	}
+}
@@ -0,0 +1,302 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config captures a complete scenario: the disease, the interventions in
+// place, and the starting conditions.
+type Config struct {
+	// Name labels the scenario; its disease parameters are registered as a
+	// pathogen profile under this name. Empty names use "custom".
+	Name                        string  `json:"name"`
+	BaseTransmission            float64 `json:"base_transmission"`
+	BaseDeathRate               float64 `json:"base_death_rate"`
+	InfectiousPeriod            int     `json:"infectious_period"`
+	TransmissionModifier        float64 `json:"transmission_modifier"`
+	LockdownEnabled             bool    `json:"lockdown_enabled"`
+	HospitalCapacity            int     `json:"hospital_capacity"`
+	DeathRateOverloadMultiplier float64 `json:"death_rate_overload_multiplier"`
+	InteractionVariance         float64 `json:"interaction_variance"`
+	ImportRate                  float64 `json:"import_rate"`
+	// InfectionRadius turns on spatial mode once agents are added; zero
+	// leaves it off.
+	InfectionRadius float64 `json:"infection_radius"`
+	// TickIntervalMs is the time between Run ticks in milliseconds; zero
+	// keeps the current interval.
+	TickIntervalMs int64 `json:"tick_interval_ms"`
+	// RecoveryRate is the memoryless per-tick recovery probability.
+	RecoveryRate float64 `json:"recovery_rate"`
+	// IncubationPeriod is the mean ticks spent exposed; zero disables the
+	// exposed stage and otherwise it is at least 1.
+	IncubationPeriod float64 `json:"incubation_period"`
+	InitialInfected  int     `json:"initial_infected"`
+	InitialExposed   int     `json:"initial_exposed"`
+	InitialRecovered int     `json:"initial_recovered"`
+	InitialImmune    int     `json:"initial_immune"`
+	// Population is the total headcount; everyone not in another initial
+	// compartment starts susceptible. Zero leaves the population unbounded.
+	Population int `json:"population"`
+	// Timeline schedules control changes during the run.
+	Timeline []TimelineEvent `json:"timeline,omitempty"`
+}
+
+// LoadConfig reads and validates the Config file at path. Files ending in
+// .yaml or .yml are read as YAML and anything else as JSON; either way the
+// keys are the JSON field names and unknown keys are rejected, so a typo
+// doesn't silently fall back to a default.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+	}
+	cfg, err := DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// DecodeConfig reads a JSON Config, rejecting unknown fields. It does not
+// validate the values.
+func DecodeConfig(r io.Reader) (Config, error) {
+	var cfg Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate reports the first parameter that falls outside its valid range.
+func (c Config) Validate() error {
+	switch {
+	case c.BaseTransmission <= 0 || c.BaseTransmission > 1:
+		return fmt.Errorf("base_transmission %v not in (0, 1]", c.BaseTransmission)
+	case c.BaseDeathRate < 0 || c.BaseDeathRate > 1:
+		return fmt.Errorf("base_death_rate %v not in [0, 1]", c.BaseDeathRate)
+	case c.InfectiousPeriod < 0:
+		return fmt.Errorf("infectious_period %d is negative", c.InfectiousPeriod)
+	case c.TransmissionModifier < 0 || c.TransmissionModifier > 1:
+		return fmt.Errorf("transmission_modifier %v not in [0, 1]", c.TransmissionModifier)
+	case c.HospitalCapacity < 0:
+		return fmt.Errorf("hospital_capacity %d is negative", c.HospitalCapacity)
+	case c.DeathRateOverloadMultiplier < 1:
+		return fmt.Errorf("death_rate_overload_multiplier %v is below 1", c.DeathRateOverloadMultiplier)
+	case sanitizeInteractionVariance(c.InteractionVariance) != c.InteractionVariance:
+		return fmt.Errorf("interaction_variance %v is not a finite, non-negative number", c.InteractionVariance)
+	case sanitizeImportRate(c.ImportRate) != c.ImportRate:
+		return fmt.Errorf("import_rate %v is not a finite, non-negative number", c.ImportRate)
+	case sanitizeRadius(c.InfectionRadius) != c.InfectionRadius:
+		return fmt.Errorf("infection_radius %v is not a finite, non-negative number", c.InfectionRadius)
+	case c.TickIntervalMs < 0:
+		return fmt.Errorf("tick_interval_ms %d is negative", c.TickIntervalMs)
+	case c.RecoveryRate < 0 || c.RecoveryRate > 1:
+		return fmt.Errorf("recovery_rate %v not in [0, 1]", c.RecoveryRate)
+	case c.IncubationPeriod != 0 && !(c.IncubationPeriod >= 1 && !math.IsInf(c.IncubationPeriod, 1)):
+		return fmt.Errorf("incubation_period %v is neither 0 nor a finite number of at least 1", c.IncubationPeriod)
+	case c.InitialInfected < 0:
+		return fmt.Errorf("initial_infected %d is negative", c.InitialInfected)
+	case c.InitialExposed < 0:
+		return fmt.Errorf("initial_exposed %d is negative", c.InitialExposed)
+	case c.InitialRecovered < 0:
+		return fmt.Errorf("initial_recovered %d is negative", c.InitialRecovered)
+	case c.InitialImmune < 0:
+		return fmt.Errorf("initial_immune %d is negative", c.InitialImmune)
+	case c.Population < 0:
+		return fmt.Errorf("population %d is negative", c.Population)
+	case c.Population > 0 && c.InitialInfected+c.InitialExposed+c.InitialRecovered+c.InitialImmune > c.Population:
+		return fmt.Errorf("initial compartments (%d infected, %d exposed, %d recovered, %d immune) exceed population %d",
+			c.InitialInfected, c.InitialExposed, c.InitialRecovered, c.InitialImmune, c.Population)
+	}
+	for i, e := range c.Timeline {
+		if err := e.validate(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyConfig validates cfg and, if it is valid, replaces the simulation's
+// parameters with it and restarts the run as Reset does, from tick 0 in the
+// next Generation. The compartments restart from the initial counts, with
+// everyone else in the population susceptible, and any scheduled outcomes are
+// discarded. The config's timeline replaces the scheduled one, including
+// events added with ScheduleTimelineEvent, and replays in full.
+func (s *Simulation) ApplyConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.Name == "" {
+		cfg.Name = "custom"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile := sanitizeProfile(Profile{
+		BaseTransmission: cfg.BaseTransmission,
+		BaseDeathRate:    cfg.BaseDeathRate,
+		InfectiousPeriod: cfg.InfectiousPeriod,
+	})
+	s.pathogens[cfg.Name] = profile
+	s.activePathogen = cfg.Name
+	s.applyProfileLocked(profile)
+
+	s.applyTransmissionModifierLocked(cfg.TransmissionModifier)
+	s.applyLockdownLocked(cfg.LockdownEnabled)
+	s.hospitalCapacity = cfg.HospitalCapacity
+	s.deathRateOverloadMultiplier = cfg.DeathRateOverloadMultiplier
+	s.interactionVariance = cfg.InteractionVariance
+	s.importRate = cfg.ImportRate
+	s.infectionRadius = cfg.InfectionRadius
+	if cfg.TickIntervalMs > 0 {
+		s.tickInterval = max(time.Duration(cfg.TickIntervalMs)*time.Millisecond, MinTickInterval)
+	}
+	s.recoveryRate = cfg.RecoveryRate
+	s.incubationPeriod = cfg.IncubationPeriod
+	s.setTimelineLocked(cfg.Timeline)
+	s.start = startingPoint{
+		infected:   cfg.InitialInfected,
+		exposed:    cfg.InitialExposed,
+		recovered:  cfg.InitialRecovered,
+		immune:     cfg.InitialImmune,
+		population: cfg.Population,
+	}
+	s.restartLocked()
+	s.version++
+	return nil
+}
+
+// NewFromConfig creates a simulation and applies cfg to it.
+func NewFromConfig(cfg Config) (*Simulation, error) {
+	s := New(cfg.BaseTransmission)
+	if err := s.ApplyConfig(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Config captures the current parameters as a Config that NewFromConfig or
+// ApplyConfig can restore later. The active pathogen's name and disease
+// parameters are included, along with the starting compartments the run
+// restarts from, not the live counts; use Save to carry on a run where it
+// left off.
+func (s *Simulation) Config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return Config{
+		Name:                        s.activePathogen,
+		BaseTransmission:            s.baseTransmission,
+		BaseDeathRate:               s.baseDeathRate,
+		InfectiousPeriod:            s.infectiousPeriod,
+		TransmissionModifier:        s.currentTransmissionModifierLocked(),
+		LockdownEnabled:             s.lockdownEnabled,
+		HospitalCapacity:            s.hospitalCapacity,
+		DeathRateOverloadMultiplier: s.deathRateOverloadMultiplier,
+		InteractionVariance:         s.interactionVariance,
+		ImportRate:                  s.importRate,
+		InfectionRadius:             s.infectionRadius,
+		TickIntervalMs:              s.tickInterval.Milliseconds(),
+		RecoveryRate:                s.recoveryRate,
+		IncubationPeriod:            s.incubationPeriod,
+		InitialInfected:             s.start.infected,
+		InitialExposed:              s.start.exposed,
+		InitialRecovered:            s.start.recovered,
+		InitialImmune:               s.start.immune,
+		Population:                  s.start.population,
+		Timeline:                    slices.Clone(s.timeline),
+	}
+}
+
+// scenarios are the built-in starting points offered to new users.
+var scenarios = map[string]Config{
+	"flu-season": {
+		BaseTransmission:            0.15,
+		BaseDeathRate:               0.002,
+		InfectiousPeriod:            7,
+		TransmissionModifier:        1,
+		HospitalCapacity:            80,
+		DeathRateOverloadMultiplier: 2,
+		InitialInfected:             20,
+		Population:                  1000,
+	},
+	"measles-outbreak": {
+		BaseTransmission:            0.9,
+		BaseDeathRate:               0.005,
+		InfectiousPeriod:            10,
+		TransmissionModifier:        1,
+		HospitalCapacity:            50,
+		DeathRateOverloadMultiplier: 2.5,
+		InteractionVariance:         0.5,
+		InitialInfected:             3,
+		Population:                  1000,
+	},
+	"novel-pathogen": {
+		BaseTransmission:            0.35,
+		BaseDeathRate:               0.02,
+		InfectiousPeriod:            14,
+		TransmissionModifier:        1,
+		HospitalCapacity:            30,
+		DeathRateOverloadMultiplier: 3,
+		InteractionVariance:         1,
+		InitialInfected:             1,
+		Population:                  1000,
+	},
+}
+
+// AvailableScenarios lists the built-in scenario names in sorted order.
+func AvailableScenarios() []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Scenario returns the preset registered under name.
+func Scenario(name string) (Config, bool) {
+	cfg, ok := scenarios[name]
+	if ok {
+		cfg.Name = name
+	}
+	return cfg, ok
+}
+
+// LoadScenario applies a built-in scenario by name.
+func (s *Simulation) LoadScenario(name string) error {
+	cfg, ok := Scenario(name)
+	if !ok {
+		return fmt.Errorf("unknown scenario %q", name)
+	}
+	return s.ApplyConfig(cfg)
+}
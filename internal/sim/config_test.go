@@ -0,0 +1,257 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScenariosLoadValidParameters(t *testing.T) {
+	names := AvailableScenarios()
+	if len(names) < 3 {
+		t.Fatalf("expected at least three scenarios, got %v", names)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			cfg, _ := Scenario(name)
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("preset is invalid: %v", err)
+			}
+
+			s := New(0.25)
+			if err := s.LoadScenario(name); err != nil {
+				t.Fatalf("load scenario: %v", err)
+			}
+
+			snap := s.Snapshot()
+			if snap.ActivePathogen != name {
+				t.Fatalf("expected active pathogen %q, got %q", name, snap.ActivePathogen)
+			}
+			if snap.InfectionProbability != cfg.BaseTransmission*cfg.TransmissionModifier {
+				t.Fatalf("expected infection probability %v, got %v", cfg.BaseTransmission*cfg.TransmissionModifier, snap.InfectionProbability)
+			}
+			if snap.HospitalCapacity != cfg.HospitalCapacity {
+				t.Fatalf("expected capacity %d, got %d", cfg.HospitalCapacity, snap.HospitalCapacity)
+			}
+			if snap.CurrentInfected != cfg.InitialInfected {
+				t.Fatalf("expected %d initial infected, got %d", cfg.InitialInfected, snap.CurrentInfected)
+			}
+		})
+	}
+}
+
+func TestLoadScenarioRejectsUnknownName(t *testing.T) {
+	s := New(0.25)
+	if err := s.LoadScenario("zombie-apocalypse"); err == nil {
+		t.Fatal("expected an error for an unknown scenario")
+	}
+	if got := s.ActivePathogen(); got != DefaultPathogen {
+		t.Fatalf("expected the default pathogen to stay active, got %q", got)
+	}
+}
+
+func TestApplyConfigRejectsInvalidConfig(t *testing.T) {
+	s := New(0.25)
+	err := s.ApplyConfig(Config{BaseTransmission: 0.5, DeathRateOverloadMultiplier: 0.5})
+	if err == nil {
+		t.Fatal("expected an overload multiplier below 1 to be rejected")
+	}
+	if got := s.HospitalCapacity(); got != 50 {
+		t.Fatalf("expected an invalid config to leave capacity at 50, got %d", got)
+	}
+}
+
+func TestConfigRoundTripsThroughNewFromConfig(t *testing.T) {
+	s := New(0.3)
+	s.UpdateTransmissionModifier(0.4)
+	s.SetLockdown(true)
+	s.SetHospitalCapacity(20)
+	s.SetInteractionVariance(0.7)
+
+	cfg := s.Config()
+	restored, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("new from config: %v", err)
+	}
+	if got := restored.Config(); !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("expected %+v, got %+v", cfg, got)
+	}
+	if _, err := NewFromConfig(Config{}); err == nil {
+		t.Fatal("expected an invalid config to be rejected")
+	}
+}
+
+func TestConfigRoundTripsRuntimeSettings(t *testing.T) {
+	s := NewWithSeed(0.3, 6)
+	cfg := s.Config()
+	cfg.IncubationPeriod, cfg.InitialExposed = 3, 7
+	if err := s.ApplyConfig(cfg); err != nil {
+		t.Fatalf("apply config: %v", err)
+	}
+	s.SetImportRate(0.5)
+	s.SetInfectionRadius(2)
+	if err := s.SetTickInterval(250 * time.Millisecond); err != nil {
+		t.Fatalf("set tick interval: %v", err)
+	}
+	s.SetRecoveryRate(0.1)
+	for i := 0; i < 10; i++ {
+		s.Step()
+	}
+
+	cfg = s.Config()
+	if cfg.InitialInfected != 10 || cfg.InitialExposed != 7 {
+		t.Fatalf("expected the starting 10 infected and 7 exposed, not the live counts, got %+v", cfg)
+	}
+	restored := New(0.25)
+	if err := restored.ApplyConfig(cfg); err != nil {
+		t.Fatalf("apply exported config: %v", err)
+	}
+	if got := restored.Config(); !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("expected %+v, got %+v", cfg, got)
+	}
+	if restored.ImportRate() != 0.5 || restored.InfectionRadius() != 2 || restored.TickInterval() != 250*time.Millisecond ||
+		restored.RecoveryRate() != 0.1 || restored.IncubationPeriod() != 3 {
+		t.Fatalf("expected the runtime settings to carry over, got %+v", restored.Snapshot())
+	}
+	if state := restored.Snapshot(); state.CurrentExposed != 7 || state.CurrentInfected != 10 {
+		t.Fatalf("expected the run to restart with 10 infected and 7 exposed, got %+v", state)
+	}
+}
+
+func TestApplyConfigSeedsInitialCompartments(t *testing.T) {
+	s, err := NewFromConfig(Config{
+		BaseTransmission:            0.3,
+		TransmissionModifier:        1,
+		DeathRateOverloadMultiplier: 2,
+		InitialInfected:             5,
+		InitialRecovered:            20,
+		InitialImmune:               75,
+		Population:                  500,
+	})
+	if err != nil {
+		t.Fatalf("new from config: %v", err)
+	}
+	state := s.Snapshot()
+	if state.CurrentInfected != 5 || state.CurrentRecovered != 20 || state.CurrentImmune != 75 || state.CurrentSusceptible != 400 {
+		t.Fatalf("expected 5 infected, 20 recovered, 75 immune, and 400 susceptible, got %+v", state)
+	}
+
+	err = s.ApplyConfig(Config{
+		BaseTransmission:            0.3,
+		DeathRateOverloadMultiplier: 2,
+		InitialInfected:             50,
+		InitialImmune:               60,
+		Population:                  100,
+	})
+	if err == nil {
+		t.Fatal("expected compartments larger than the population to be rejected")
+	}
+}
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigReadsYAMLTimeline(t *testing.T) {
+	path := writeConfigFile(t, "lockdown.yaml", `
+base_transmission: 0.4
+base_death_rate: 0.01
+transmission_modifier: 1
+hospital_capacity: 40
+death_rate_overload_multiplier: 2
+initial_infected: 5
+population: 500
+timeline:
+  - tick: 3
+    lockdown_enabled: true
+    transmission_modifier: 0.5
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.HospitalCapacity != 40 || cfg.Population != 500 || len(cfg.Timeline) != 1 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	s, err := NewFromConfig(*cfg)
+	if err != nil {
+		t.Fatalf("apply config: %v", err)
+	}
+	s.SetLogger(nil)
+	for i := 0; i < 2; i++ {
+		if state := s.Step(); state.LockdownEnabled {
+			t.Fatalf("tick %d: expected lockdown to wait for tick 3", state.Tick)
+		}
+	}
+	before := s.Snapshot().StateVersion
+	state := s.Step()
+	if !state.LockdownEnabled || state.TransmissionModifier != 0.5 {
+		t.Fatalf("expected lockdown and a halved modifier at tick 3, got %+v", state)
+	}
+	if state.StateVersion == before {
+		t.Fatal("expected the timeline change to advance the state version")
+	}
+	events := s.EventsSince(2)
+	if len(events) != 1 || events[0].Kind != EventTimeline {
+		t.Fatalf("expected one timeline event, got %+v", events)
+	}
+}
+
+func TestApplyConfigMidRunRestartsTheTimeline(t *testing.T) {
+	s := NewWithSeed(0.3, 5)
+	s.SetLogger(nil)
+	lockdown := true
+	if err := s.ScheduleTimelineEvent(TimelineEvent{Tick: 30, LockdownEnabled: &lockdown}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	s.StepN(20)
+	generation := s.Snapshot().Generation
+
+	cfg := s.Config()
+	cfg.Timeline = []TimelineEvent{{Tick: 5, LockdownEnabled: &lockdown}}
+	if err := s.ApplyConfig(cfg); err != nil {
+		t.Fatalf("apply config: %v", err)
+	}
+	state := s.Snapshot()
+	if state.Tick != 0 || state.Generation != generation+1 || len(s.History()) != 0 {
+		t.Fatalf("expected a restart at tick 0 in the next generation with no history, got tick %d, generation %d, %d snapshots",
+			state.Tick, state.Generation, len(s.History()))
+	}
+	if got := s.Timeline(); len(got) != 1 || got[0].Tick != 5 {
+		t.Fatalf("expected the config's timeline to replace the scheduled one, got %+v", got)
+	}
+	if state := s.StepN(5); !state.LockdownEnabled {
+		t.Fatal("expected the tick 5 entry to fire after the restart")
+	}
+}
+
+func TestLoadConfigRejectsBadFiles(t *testing.T) {
+	for name, contents := range map[string]string{
+		"typo.json":     `{"base_transmission": 0.3, "death_rate_overload_multiplier": 2, "hospital_capcity": 10}`,
+		"typo.yaml":     "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\nlockdown: true\n",
+		"range.json":    `{"base_transmission": 3, "death_rate_overload_multiplier": 2}`,
+		"timeline.yml":  "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ntimeline:\n  - tick: 0\n    lockdown_enabled: true\n",
+		"empty.yml":     "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ntimeline:\n  - tick: 5\n",
+		"negative.yaml": "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ntimeline:\n  - tick: 5\n    hospital_capacity: -1\n",
+		"nan.yaml":      "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ninteraction_variance: .nan\n",
+		"inf.yml":       "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ntimeline:\n  - tick: 5\n    interaction_variance: .inf\n",
+		"recovery.yml":  "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\nrecovery_rate: 2\n",
+		"incubate.yml":  "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\nincubation_period: 0.5\n",
+		"interval.yml":  "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ntick_interval_ms: -1\n",
+		"radius.yml":    "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ninfection_radius: -2\n",
+		"exposed.yml":   "base_transmission: 0.3\ndeath_rate_overload_multiplier: 2\ninitial_exposed: 60\npopulation: 50\n",
+	} {
+		if _, err := LoadConfig(writeConfigFile(t, name, contents)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package sim
+
+const (
+	defaultContactBase         = 5
+	defaultContactsPerInfected = 1.0 / 3
+)
+
+// SetContactsPerInfected sets how many contacts the infected population makes
+// each tick: base plus perInfected for every infectious person, rounded down,
+// before any contact variance is applied. Higher values model denser social
+// mixing. Negative values are clamped to zero. The defaults are 5 and 1/3.
+func (s *Simulation) SetContactsPerInfected(base int, perInfected float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contactBase = max(base, 0)
+	s.contactsPerInfected = max(perInfected, 0)
+}
+
+// ContactsPerInfected returns the contact base and per-infected rate.
+func (s *Simulation) ContactsPerInfected() (base int, perInfected float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.contactBase, s.contactsPerInfected
+}
+
+// contactsLocked is the mean number of contacts made in one tick by infected
+// infectious people.
+func (s *Simulation) contactsLocked(infected int) int {
+	return s.contactBase + int(float64(infected)*s.contactsPerInfected)
+}
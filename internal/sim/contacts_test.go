@@ -0,0 +1,26 @@
+package sim
+
+import "testing"
+
+func TestContactsPerInfectedDrivesInteractions(t *testing.T) {
+	s := New(0.25)
+	s.SetPopulation(0)
+	s.currentInfected = 30
+	if got := s.Step().Contacts; got != 15 {
+		t.Fatalf("expected the default 5 + 30/3 contacts, got %d", got)
+	}
+
+	s.SetContactsPerInfected(2, 1.5)
+	s.currentInfected = 30
+	if got := s.Step().Contacts; got != 47 {
+		t.Fatalf("expected 2 + 30*1.5 contacts, got %d", got)
+	}
+
+	s.SetContactsPerInfected(-1, -2)
+	if base, perInfected := s.ContactsPerInfected(); base != 0 || perInfected != 0 {
+		t.Fatalf("expected negative rates clamped to 0, got %d and %v", base, perInfected)
+	}
+	if got := s.Step().Contacts; got != 0 {
+		t.Fatalf("expected no contacts, got %d", got)
+	}
+}
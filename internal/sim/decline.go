@@ -0,0 +1,89 @@
+package sim
+
+import "fmt"
+
+// EventHerdImmunity marks the tick on which the epidemic was judged to be in
+// sustained decline and Run stopped.
+const EventHerdImmunity EventKind = "herd_immunity"
+
+// SetHerdImmunityStop makes Run return once the effective reproduction number
+// has stayed below 1 for ticks consecutive ticks, which saves time in
+// parameter sweeps once the outcome is settled. A "herd immunity reached"
+// event records the cumulative infections at that point. Zero or negative
+// values disable the stop, which is the default.
+func (s *Simulation) SetHerdImmunityStop(ticks int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.herdStopTicks = max(ticks, 0)
+}
+
+// EffectiveReproductionNumber returns the latest estimate of Rt: the new
+// infections per infectious person on the last tick, times how many ticks an
+// infection stays infectious on average. It is 0 when nobody was infectious.
+func (s *Simulation) EffectiveReproductionNumber() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.effectiveR
+}
+
+// meanInfectiousTicksLocked is how long an infection stays infectious on
+// average under the active outcome rules: the fixed period for scheduled
+// outcomes, and the inverse of the per-tick chance of dying or recovering
+// when outcomes are drawn each tick. Rt, R0 calibration, and the final-size
+// estimate all measure the infectious duration with it.
+func (s *Simulation) meanInfectiousTicksLocked() float64 {
+	leave := 0.0
+	switch {
+	case s.transitions != nil:
+		deathProbability, _ := s.deathProbabilityLocked()
+		for to := Susceptible; to < numCompartments; to++ {
+			if to != Infectious && to != Dead {
+				leave += s.transitions[Infectious][to]
+			}
+		}
+		leave = min(leave+deathProbability, 1)
+	case s.outcomeModel == OutcomeMemoryless:
+		deathProbability, _ := s.deathProbabilityLocked()
+		leave = min(deathProbability+s.recoveryProbabilityLocked(), 1)
+	}
+	if leave > 0 {
+		return 1 / leave
+	}
+	return float64(s.infectiousTicksLocked())
+}
+
+// updateEffectiveRLocked estimates this tick's effective reproduction number
+// from the new infections caused by the infected pool, and tracks how long it
+// has stayed below 1.
+func (s *Simulation) updateEffectiveRLocked(infected, newInfections int) {
+	if infected > 0 {
+		s.effectiveR = float64(newInfections) / float64(infected) * s.meanInfectiousTicksLocked()
+	} else {
+		s.effectiveR = 0
+	}
+	if s.effectiveR < 1 {
+		s.ticksBelowOne++
+	} else {
+		s.ticksBelowOne = 0
+	}
+}
+
+// declineEstablishedLocked reports whether the herd immunity stop fires on
+// this tick, recording its event the first time the streak reaches the
+// configured length.
+func (s *Simulation) declineEstablishedLocked() bool {
+	if s.herdStopTicks == 0 || s.ticksBelowOne != s.herdStopTicks {
+		return false
+	}
+
+	s.recordEventLocked(Event{
+		Tick:  s.tick,
+		Kind:  EventHerdImmunity,
+		Count: s.totalInfections,
+		Message: fmt.Sprintf("herd immunity reached: effective R below 1 for %d ticks after %d infections",
+			s.herdStopTicks, s.totalInfections),
+	})
+	return true
+}
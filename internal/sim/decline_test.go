@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestHerdImmunityStopEndsRunAfterSustainedDecline(t *testing.T) {
+	s := New(0.5)
+	s.SetLogger(nil)
+	s.rng = rand.New(rand.NewSource(3))
+	s.baseDeathRate = 0
+	for i := 0; i < 5; i++ {
+		s.Step()
+	}
+	grown := s.Snapshot()
+	if grown.TotalInfections == 0 {
+		t.Fatal("expected the epidemic to grow before the decline")
+	}
+
+	// Cutting transmission to zero drives the effective R to 0 every tick.
+	s.UpdateTransmissionModifier(0)
+	s.SetHerdImmunityStop(3)
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(context.Background(), time.Millisecond, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run did not stop after a sustained decline")
+	}
+
+	state := s.Snapshot()
+	if state.Tick != grown.Tick+3 {
+		t.Fatalf("expected the run to stop 3 ticks after the decline began at tick %d, got tick %d", grown.Tick, state.Tick)
+	}
+	if state.EffectiveR != 0 {
+		t.Fatalf("expected effective R 0 without transmission, got %v", state.EffectiveR)
+	}
+
+	events := s.Events()
+	last := events[len(events)-1]
+	if last.Kind != EventHerdImmunity || last.Tick != state.Tick || last.Count != grown.TotalInfections {
+		t.Fatalf("expected a herd immunity event at tick %d with %d infections, got %+v", state.Tick, grown.TotalInfections, last)
+	}
+}
+
+func TestEffectiveReproductionNumberUsesMeanInfectiousTime(t *testing.T) {
+	s := NewWithSeed(0.3, 7)
+	s.SetPopulation(0)
+	s.baseDeathRate = 0
+	s.SetRecoveryRate(0.1)
+	s.currentInfected = 100
+
+	before := s.Snapshot()
+	state := s.Step()
+	newInfections := state.TotalInfections - before.TotalInfections
+	// Recovering at 0.1 per tick keeps people infectious for 10 ticks.
+	want := float64(newInfections) / 100 * 10
+	if got := s.EffectiveReproductionNumber(); math.Abs(got-want) > 1e-9 || state.EffectiveR != got {
+		t.Fatalf("expected Rt %v, got %v (snapshot %v)", want, got, state.EffectiveR)
+	}
+
+	s.currentInfected = 0
+	s.Step()
+	if got := s.EffectiveReproductionNumber(); got != 0 || math.IsNaN(got) {
+		t.Fatalf("expected Rt 0 with nobody infectious, got %v", got)
+	}
+}
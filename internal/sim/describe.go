@@ -0,0 +1,69 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String returns the outcome model's name.
+func (m OutcomeModel) String() string {
+	switch m {
+	case OutcomeMemoryless:
+		return "memoryless"
+	case OutcomeScheduled:
+		return "scheduled"
+	default:
+		return fmt.Sprintf("OutcomeModel(%d)", int(m))
+	}
+}
+
+// String returns the rounding mode's name.
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundStochastic:
+		return "stochastic"
+	case RoundTruncate:
+		return "truncate"
+	case RoundNearest:
+		return "nearest"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+// Describe returns a multi-line, human-readable summary of the current
+// configuration and state, suitable for error reports and debugging. The
+// layout is kept stable so tests can assert on it.
+func (s *Simulation) Describe() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := s.snapshotLocked()
+	runState := "running"
+	if s.paused {
+		runState = "paused"
+	}
+	hospitalState := "holding"
+	if state.Overloaded {
+		hospitalState = "overloaded"
+	}
+	lockdown := "off"
+	if state.LockdownEnabled {
+		lockdown = "on"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Simulation at tick %d (%s)\n", state.Tick, runState)
+	fmt.Fprintf(&b, "Pathogen: %s (base transmission %.3f, base death rate %.4f, infectious period %d days)\n",
+		s.activePathogen, s.baseTransmission, s.baseDeathRate, s.infectiousPeriod)
+	fmt.Fprintf(&b, "Transmission: modifier %.2f, infection probability %.3f, contact variance %.2f\n",
+		state.TransmissionModifier, state.InfectionProbability, state.InteractionVariance)
+	fmt.Fprintf(&b, "Hospital: capacity %d, overload multiplier %.2f, utilization %.0f%% (%s)\n",
+		state.HospitalCapacity, state.DeathRateOverloadMultiplier, state.CapacityUtilization*100, hospitalState)
+	fmt.Fprintf(&b, "Infected: %d current, %d deaths total, %d deaths scheduled\n",
+		state.CurrentInfected, state.TotalDeaths, state.ScheduledDeaths)
+	fmt.Fprintf(&b, "Outcomes: %s model, %s rounding, death probability %.4f\n",
+		s.outcomeModel, s.roundingMode, state.EffectiveDeathProbability)
+	fmt.Fprintf(&b, "Interventions: lockdown %s (speed %.2fx)\n", lockdown, state.SpeedModifier)
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribeIncludesKeyParameters(t *testing.T) {
+	s := New(0.3)
+	s.SetLockdown(true)
+	s.SetHospitalCapacity(5)
+	s.currentInfected = 12
+	s.Pause()
+
+	description := s.Describe()
+	for _, want := range []string{
+		"Simulation at tick 0 (paused)",
+		"Pathogen: default (base transmission 0.300",
+		"infection probability 0.300",
+		"Hospital: capacity 5, overload multiplier 2.00, utilization 240% (overloaded)",
+		"Infected: 12 current",
+		"Outcomes: memoryless model, stochastic rounding",
+		"Interventions: lockdown on (speed 0.10x)",
+	} {
+		if !strings.Contains(description, want) {
+			t.Fatalf("expected description to contain %q, got:\n%s", want, description)
+		}
+	}
+}
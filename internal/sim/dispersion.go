@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"math"
+	"sort"
+)
+
+// SetDispersion switches transmission to a negative-binomial offspring model
+// with dispersion k. Each infected individual's secondary cases for the tick
+// are drawn with the same mean the contact model would produce, but small k
+// concentrates them on a few superspreaders while most infect nobody. Values
+// of k at or below zero restore the contact model, which is the default.
+// Snapshots summarize the realized distribution in SecondaryMean,
+// SecondaryVariance, SecondaryMax, and CoreSpreaderFraction; those fields stay
+// zero under the contact model.
+func (s *Simulation) SetDispersion(k float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dispersion = max(k, 0)
+}
+
+// Dispersion returns the offspring dispersion k, or 0 when disabled.
+func (s *Simulation) Dispersion() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.dispersion
+}
+
+// offspringSummary describes the secondary cases each infected individual
+// caused on the last tick under the dispersion model.
+type offspringSummary struct {
+	mean     float64
+	variance float64
+	max      int
+	// coreFraction is the smallest fraction of infected individuals that
+	// together caused 80% of the new infections: the classic "20/80" rule
+	// becomes a value near 0.2, and heavier superspreading pushes it lower.
+	coreFraction float64
+}
+
+// drawOffspringLocked draws every infected individual's secondary cases from
+// a negative binomial (a gamma-Poisson mixture) with the given mean and the
+// configured dispersion, records their distribution, and returns the total.
+func (s *Simulation) drawOffspringLocked(infected int, mean float64) int {
+	if infected <= 0 {
+		s.offspring = offspringSummary{}
+		return 0
+	}
+
+	counts := make([]int, infected)
+	total, sumSquares := 0, 0.0
+	for i := range counts {
+		counts[i] = poissonSample(s.rng, gammaSample(s.rng, s.dispersion, mean/s.dispersion))
+		total += counts[i]
+		sumSquares += float64(counts[i]) * float64(counts[i])
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	summary := offspringSummary{
+		mean: float64(total) / float64(infected),
+		max:  counts[0],
+	}
+	summary.variance = math.Max(sumSquares/float64(infected)-summary.mean*summary.mean, 0)
+	if total > 0 {
+		covered := 0
+		for i, count := range counts {
+			covered += count
+			if float64(covered) >= 0.8*float64(total) {
+				summary.coreFraction = float64(i+1) / float64(infected)
+				break
+			}
+		}
+	}
+	s.offspring = summary
+	return total
+}
@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSmallerDispersionIncreasesVariance(t *testing.T) {
+	run := func(k float64, infected int) (variance, coreFraction float64) {
+		s := New(0.3)
+		s.rng = rand.New(rand.NewSource(9))
+		s.baseDeathRate = 0
+		s.SetDispersion(k)
+
+		const trials = 400
+		var sum, sumSquares, fractions float64
+		for i := 0; i < trials; i++ {
+			s.currentInfected = infected
+			state := s.Step()
+			n := float64(state.CurrentInfected - infected)
+			sum += n
+			sumSquares += n * n
+			fractions += state.CoreSpreaderFraction
+		}
+		mean := sum / trials
+		return sumSquares/trials - mean*mean, fractions / trials
+	}
+
+	narrowVariance, _ := run(0.1, 10)
+	wideVariance, _ := run(10, 10)
+	if narrowVariance <= 2*wideVariance {
+		t.Fatalf("expected k=0.1 to give much noisier new infections than k=10, got variance %v vs %v", narrowVariance, wideVariance)
+	}
+
+	_, narrowCore := run(0.1, 200)
+	_, wideCore := run(10, 200)
+	if narrowCore >= wideCore {
+		t.Fatalf("expected k=0.1 to concentrate transmission in fewer people, got core fraction %v vs %v", narrowCore, wideCore)
+	}
+}
+
+func TestDispersionKeepsMeanOffspring(t *testing.T) {
+	s := New(0.3)
+	s.rng = rand.New(rand.NewSource(4))
+	s.baseDeathRate = 0
+	s.SetDispersion(0.5)
+	// Repeated trials would otherwise exhaust the susceptible pool.
+	s.SetPopulation(0)
+
+	// 100 infected make 38 contacts at probability 0.3: 11.4 expected cases.
+	total := 0
+	const trials = 2000
+	for i := 0; i < trials; i++ {
+		s.currentInfected = 100
+		total += s.Step().CurrentInfected - 100
+	}
+	if mean := float64(total) / trials; mean < 10.4 || mean > 12.4 {
+		t.Fatalf("expected about 11.4 new infections per tick, got %v", mean)
+	}
+}
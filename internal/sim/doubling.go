@@ -0,0 +1,58 @@
+package sim
+
+import "math"
+
+// doublingWindow is how many recent ticks DoublingTime fits its trend to.
+const doublingWindow = 7
+
+// DoublingTime estimates how many ticks the infected count currently takes to
+// double, from a least-squares fit of log infected over the last 7 ticks of
+// history including the current one. A declining epidemic gives a negative
+// value whose magnitude is the halving time. It returns 0 when there is no
+// trend to report: fewer than 3 ticks of history, a tick with nobody
+// infected, or a flat curve.
+func (s *Simulation) DoublingTime() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.doublingTimeLocked()
+}
+
+func (s *Simulation) doublingTimeLocked() float64 {
+	xs := make([]float64, 0, doublingWindow)
+	ys := make([]float64, 0, doublingWindow)
+	for i := s.history.len() - 1; i >= 0 && len(xs) < doublingWindow-1; i-- {
+		if state := s.history.at(i); state.Tick < s.tick {
+			xs = append(xs, float64(state.Tick))
+			ys = append(ys, float64(state.CurrentInfected))
+		}
+	}
+	xs = append(xs, float64(s.tick))
+	ys = append(ys, float64(s.currentInfected))
+	if len(xs) < 3 {
+		return 0
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		if ys[i] <= 0 {
+			return 0
+		}
+		ys[i] = math.Log(ys[i])
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var covariance, variance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	slope := covariance / variance
+	if math.Abs(slope) < 1e-9 {
+		return 0
+	}
+	return math.Ln2 / slope
+}
@@ -0,0 +1,40 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDoublingTimeFromExponentialHistory(t *testing.T) {
+	synthetic := func(ticks float64) *Simulation {
+		s := New(0.25)
+		for tick := 1; tick < 10; tick++ {
+			infected := int(math.Round(1000 * math.Exp2(float64(tick)/ticks)))
+			s.recordHistoryLocked(Snapshot{Tick: tick, CurrentInfected: infected})
+		}
+		s.tick = 10
+		s.currentInfected = int(math.Round(1000 * math.Exp2(10/ticks)))
+		return s
+	}
+
+	if got := synthetic(4).DoublingTime(); math.Abs(got-4) > 0.05 {
+		t.Fatalf("expected a doubling time of 4 ticks, got %v", got)
+	}
+	if got := synthetic(-5).Snapshot().DoublingTime; math.Abs(got+5) > 0.05 {
+		t.Fatalf("expected a halving time of 5 ticks reported as -5, got %v", got)
+	}
+}
+
+func TestDoublingTimeNeedsATrend(t *testing.T) {
+	s := New(0.25)
+	if got := s.DoublingTime(); got != 0 {
+		t.Fatalf("expected 0 without history, got %v", got)
+	}
+
+	s.recordHistoryLocked(Snapshot{Tick: 1, CurrentInfected: 10})
+	s.recordHistoryLocked(Snapshot{Tick: 2, CurrentInfected: 10})
+	s.tick = 3
+	if got := s.DoublingTime(); got != 0 {
+		t.Fatalf("expected 0 for a flat curve, got %v", got)
+	}
+}
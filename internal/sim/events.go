@@ -0,0 +1,145 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxEvents bounds the event log; the oldest events are dropped first.
+const maxEvents = 256
+
+// EventKind identifies what happened during a tick.
+type EventKind string
+
+const (
+	// EventImport marks infections introduced from outside the population.
+	EventImport EventKind = "import"
+)
+
+// Event records a notable change in the simulation.
+type Event struct {
+	Tick    int       `json:"tick"`
+	Kind    EventKind `json:"kind"`
+	Count   int       `json:"count,omitempty"`
+	Message string    `json:"message"`
+}
+
+// Events returns a copy of the buffered events in chronological order.
+func (s *Simulation) Events() []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]Event, len(s.events))
+	copy(events, s.events)
+	return events
+}
+
+// EventsSince returns the buffered events recorded after tick. Events older
+// than the buffer's reach are no longer available.
+func (s *Simulation) EventsSince(tick int) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []Event
+	for _, event := range s.events {
+		if event.Tick > tick {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// ClearHistory empties the recorded snapshot history and event log so a
+// long-running server can free memory or start a fresh recording window. The
+// epidemic itself, including pending imports, is left untouched.
+func (s *Simulation) ClearHistory() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = nil
+	s.history.clear()
+}
+
+func (s *Simulation) recordEventLocked(event Event) {
+	if len(s.events) >= maxEvents {
+		copy(s.events, s.events[1:])
+		s.events = s.events[:len(s.events)-1]
+	}
+	s.events = append(s.events, event)
+}
+
+// ScheduleImport adds count infections from outside the population when Run
+// (or Step) reaches tick. Imports scheduled for the same tick accumulate.
+func (s *Simulation) ScheduleImport(tick, count int) error {
+	if count <= 0 {
+		return fmt.Errorf("import count must be positive, got %d", count)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tick <= s.tick {
+		return fmt.Errorf("import tick %d is not after the current tick %d", tick, s.tick)
+	}
+	if s.imports == nil {
+		s.imports = make(map[int]int)
+	}
+	s.imports[tick] += count
+	return nil
+}
+
+// SetImportRate seeds a steady stream of outside infections, such as
+// travellers from elsewhere: each tick a Poisson-distributed number with mean
+// perTick arrives, whatever the state of the susceptible pool, so the
+// epidemic never dies out entirely. Negative and non-finite rates are
+// clamped to 0, the default, which turns the stream off. Unlike scheduled
+// imports, these arrivals are not recorded as events.
+func (s *Simulation) SetImportRate(perTick float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.importRate = sanitizeImportRate(perTick)
+}
+
+// ImportRate returns the mean number of outside infections arriving each
+// tick.
+func (s *Simulation) ImportRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.importRate
+}
+
+// applyImportsLocked moves any imports due at the current tick, plus this
+// tick's draw from the import rate, into the infected pool and returns how
+// many arrived. Imported cases join the population rather than coming out of
+// its susceptible pool.
+func (s *Simulation) applyImportsLocked() int {
+	scheduled := s.imports[s.tick]
+	s.imported = scheduled + poissonSample(s.rng, s.importRate)
+	if s.imported == 0 {
+		return 0
+	}
+
+	s.currentInfected += s.imported
+	if s.population > 0 {
+		s.population += s.imported
+	}
+	if scheduled > 0 {
+		delete(s.imports, s.tick)
+		s.recordEventLocked(Event{
+			Tick:    s.tick,
+			Kind:    EventImport,
+			Count:   scheduled,
+			Message: fmt.Sprintf("%d imported infections", scheduled),
+		})
+	}
+	return s.imported
+}
+
+func sanitizeImportRate(rate float64) float64 {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return 0
+	}
+	return rate
+}
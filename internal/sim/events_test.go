@@ -0,0 +1,87 @@
+package sim
+
+import "testing"
+
+func TestScheduledImportArrivesAtTick(t *testing.T) {
+	s := New(0.2)
+	s.UpdateTransmissionModifier(0)
+	s.baseDeathRate = 0
+
+	if err := s.ScheduleImport(5, 3); err != nil {
+		t.Fatalf("schedule import: %v", err)
+	}
+
+	start := s.CurrentInfected()
+	for i := 0; i < 4; i++ {
+		s.Step()
+	}
+	if got := s.CurrentInfected(); got != start {
+		t.Fatalf("expected no imports before tick 5, got %d infected (from %d)", got, start)
+	}
+
+	if snap := s.Step(); snap.CurrentInfected != start+3 {
+		t.Fatalf("expected 3 imports at tick 5, got %d infected (from %d)", snap.CurrentInfected, start)
+	}
+
+	events := s.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected one import event, got %v", events)
+	}
+	if events[0].Kind != EventImport || events[0].Tick != 5 || events[0].Count != 3 {
+		t.Fatalf("unexpected import event %+v", events[0])
+	}
+}
+
+func TestScheduleImportRejectsPastTicks(t *testing.T) {
+	s := New(0.2)
+	s.Step()
+
+	if err := s.ScheduleImport(1, 3); err == nil {
+		t.Fatal("expected an error for a tick that already passed")
+	}
+	if err := s.ScheduleImport(10, 0); err == nil {
+		t.Fatal("expected an error for a non-positive count")
+	}
+}
+
+func TestImportRateSustainsTransmission(t *testing.T) {
+	s := NewWithSeed(0.2, 7)
+	s.SetRecoveryRate(0.5)
+	s.SetContactsPerInfected(0, 0)
+	s.SetImportRate(2)
+
+	imported := 0
+	for i := 0; i < 200; i++ {
+		state := s.Step()
+		imported += state.Imported
+	}
+	if imported < 300 || imported > 500 {
+		t.Fatalf("expected about 400 imports over 200 ticks at rate 2, got %d", imported)
+	}
+	if s.Snapshot().CurrentInfected == 0 {
+		t.Fatal("expected steady imports to keep infections going")
+	}
+	if events := s.Events(); len(events) != 0 {
+		t.Fatalf("expected rate-driven imports to stay out of the event log, got %d events", len(events))
+	}
+
+	s.SetImportRate(-3)
+	if got := s.ImportRate(); got != 0 {
+		t.Fatalf("expected a negative rate to clamp to 0, got %v", got)
+	}
+}
+
+func TestEventLogIsBounded(t *testing.T) {
+	s := New(0.2)
+	for i := 0; i < maxEvents+10; i++ {
+		s.recordEventLocked(Event{Tick: i})
+	}
+
+	events := s.Events()
+	if len(events) != maxEvents {
+		t.Fatalf("expected %d events, got %d", maxEvents, len(events))
+	}
+	if events[0].Tick != 10 {
+		t.Fatalf("expected the oldest events to be dropped, first tick is %d", events[0].Tick)
+	}
+}
@@ -0,0 +1,44 @@
+package sim
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvPrecision is the number of decimals written for probabilities, fixed so
+// exports of identical runs are byte-for-byte identical.
+const csvPrecision = 6
+
+var csvHeader = []string{
+	"tick", "infected", "susceptible", "recovered",
+	"death_probability", "infection_probability", "overloaded",
+}
+
+// ExportCSV writes the recorded history as CSV: a header row, then one row
+// per snapshot, oldest first. Probabilities have six decimals. Only the
+// snapshots still in the history buffer are written; see SetHistoryCapacity.
+func (s *Simulation) ExportCSV(w io.Writer) error {
+	history := s.History()
+
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, state := range history {
+		record := []string{
+			strconv.Itoa(state.Tick),
+			strconv.Itoa(state.CurrentInfected),
+			strconv.Itoa(state.CurrentSusceptible),
+			strconv.Itoa(state.CurrentRecovered),
+			strconv.FormatFloat(state.EffectiveDeathProbability, 'f', csvPrecision, 64),
+			strconv.FormatFloat(state.InfectionProbability, 'f', csvPrecision, 64),
+			strconv.FormatBool(state.Overloaded),
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,57 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"testing"
+)
+
+func TestExportCSVWritesOneRowPerTick(t *testing.T) {
+	s := NewWithSeed(0.25, 11)
+	for i := 0; i < 3; i++ {
+		s.Step()
+	}
+
+	var buf bytes.Buffer
+	if err := s.ExportCSV(&buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse export: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected a header and 3 rows, got %d records", len(records))
+	}
+	if got := records[0][0] + "," + records[0][4]; got != "tick,death_probability" {
+		t.Fatalf("unexpected header %v", records[0])
+	}
+	history := s.History()
+	for i, record := range records[1:] {
+		if record[0] != strconv.Itoa(history[i].Tick) {
+			t.Fatalf("row %d: expected tick %d, got %v", i, history[i].Tick, record)
+		}
+		if len(record[5]) != len("0.250000") {
+			t.Fatalf("row %d: expected six decimals for the infection probability, got %q", i, record[5])
+		}
+	}
+}
+
+func TestExportCSVIsStableAcrossIdenticalRuns(t *testing.T) {
+	export := func() string {
+		s := NewWithSeed(0.25, 11)
+		for i := 0; i < 20; i++ {
+			s.Step()
+		}
+		var buf bytes.Buffer
+		if err := s.ExportCSV(&buf); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		return buf.String()
+	}
+
+	if first, second := export(), export(); first != second {
+		t.Fatalf("expected identical exports for the same seed:\n%s\n%s", first, second)
+	}
+}
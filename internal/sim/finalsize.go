@@ -0,0 +1,40 @@
+package sim
+
+import "math"
+
+// FinalSizeEstimate predicts the fraction of the population an SIR epidemic
+// with the current parameters would eventually infect, by solving the
+// final-size relation z = 1 - exp(-R0 z). R0 is the expected number of new
+// infections per infected individual per tick at the current infected count
+// and contact rate, times the mean infectious duration in ticks. The estimate
+// assumes the current transmission settings hold from now on: no new
+// interventions and no behavioural change.
+func (s *Simulation) FinalSizeEstimate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infected := max(s.currentInfected, 1)
+	contactsPerInfected := float64(s.contactsLocked(infected)) / float64(infected)
+	r0 := s.infectionProbabilityLocked() * contactsPerInfected * s.meanInfectiousTicksLocked()
+	return finalSize(r0)
+}
+
+// finalSize solves z = 1 - exp(-r0 z) for the non-trivial root with Newton's
+// method. Epidemics with r0 <= 1 do not take off, so their final size is 0.
+func finalSize(r0 float64) float64 {
+	if r0 <= 1 {
+		return 0
+	}
+
+	z := 1.0
+	for i := 0; i < 50; i++ {
+		e := math.Exp(-r0 * z)
+		f := z - 1 + e
+		step := f / (1 - r0*e)
+		z -= step
+		if math.Abs(step) < 1e-12 {
+			break
+		}
+	}
+	return z
+}
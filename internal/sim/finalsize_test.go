@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFinalSizeMatchesKnownValues(t *testing.T) {
+	cases := []struct {
+		r0   float64
+		want float64
+	}{
+		{r0: 0.8, want: 0},
+		{r0: 1, want: 0},
+		{r0: 1.5, want: 0.5828},
+		{r0: 2, want: 0.7968},
+		{r0: 3, want: 0.9405},
+		{r0: 5, want: 0.9930},
+	}
+	for _, tc := range cases {
+		if got := finalSize(tc.r0); math.Abs(got-tc.want) > 1e-4 {
+			t.Errorf("finalSize(%v) = %v, want %v", tc.r0, got, tc.want)
+		}
+	}
+}
+
+func TestFinalSizeEstimateUsesCurrentParameters(t *testing.T) {
+	s := New(0.25)
+	s.SetOutcomeModel(OutcomeScheduled, 14)
+	// 10 infected make 8 contacts per tick over 14 ticks: R0 = 0.25 * 0.8 * 14 = 2.8.
+	if got, want := s.FinalSizeEstimate(), finalSize(2.8); math.Abs(got-want) > 1e-9 {
+		t.Fatalf("expected final size %v, got %v", want, got)
+	}
+
+	s.UpdateTransmissionModifier(0.1)
+	if got := s.FinalSizeEstimate(); got != 0 {
+		t.Fatalf("expected a subcritical epidemic to have final size 0, got %v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package sim
+
+import (
+	"math"
+	"slices"
+)
+
+// gridCell is the integer coordinate of one spatialGrid cell.
+type gridCell struct {
+	x, y int
+}
+
+// spatialGrid buckets agents into square cells as wide as the infection
+// radius, so everyone within the radius of a point lies in the 3x3 block of
+// cells around it.
+type spatialGrid struct {
+	cellSize float64
+	cells    map[gridCell][]int
+}
+
+// newSpatialGrid indexes the agents for which include reports true.
+func newSpatialGrid(agents []Agent, cellSize float64, include func(a *Agent) bool) *spatialGrid {
+	g := &spatialGrid{cellSize: cellSize, cells: make(map[gridCell][]int)}
+	for i := range agents {
+		if include(&agents[i]) {
+			cell := g.cellOf(agents[i].X, agents[i].Y)
+			g.cells[cell] = append(g.cells[cell], i)
+		}
+	}
+	return g
+}
+
+func (g *spatialGrid) cellOf(x, y float64) gridCell {
+	return gridCell{int(math.Floor(x / g.cellSize)), int(math.Floor(y / g.cellSize))}
+}
+
+// neighbours appends to buf the indexed agents in the cells around (x, y),
+// in ascending index order so random draws follow the same order as a scan
+// over every agent.
+func (g *spatialGrid) neighbours(x, y float64, buf []int) []int {
+	center := g.cellOf(x, y)
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			buf = append(buf, g.cells[gridCell{center.x + dx, center.y + dy}]...)
+		}
+	}
+	slices.Sort(buf)
+	return buf
+}
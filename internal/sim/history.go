@@ -0,0 +1,157 @@
+package sim
+
+// defaultHistoryCapacity is how many snapshots the history keeps unless
+// SetHistoryCapacity says otherwise.
+const defaultHistoryCapacity = 600
+
+// snapshotRing is a fixed-capacity ring of snapshots; once full, each push
+// overwrites the oldest entry.
+type snapshotRing struct {
+	buf   []Snapshot
+	start int
+	size  int
+}
+
+func (r *snapshotRing) len() int {
+	return r.size
+}
+
+// at returns the i-th snapshot, oldest first.
+func (r *snapshotRing) at(i int) Snapshot {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+func (r *snapshotRing) push(state Snapshot, capacity int) {
+	if len(r.buf) != capacity {
+		r.resize(capacity)
+	}
+	if r.size < len(r.buf) {
+		r.buf[(r.start+r.size)%len(r.buf)] = state
+		r.size++
+		return
+	}
+	r.buf[r.start] = state
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// resize changes the capacity, keeping the newest snapshots that fit.
+func (r *snapshotRing) resize(capacity int) {
+	keep := min(r.size, capacity)
+	buf := make([]Snapshot, capacity)
+	for i := 0; i < keep; i++ {
+		buf[i] = r.at(r.size - keep + i)
+	}
+	*r = snapshotRing{buf: buf, size: keep}
+}
+
+func (r *snapshotRing) snapshots() []Snapshot {
+	history := make([]Snapshot, r.size)
+	for i := range history {
+		history[i] = r.at(i)
+	}
+	return history
+}
+
+func (r *snapshotRing) clear() {
+	*r = snapshotRing{}
+}
+
+// SetHistoryCapacity bounds how many snapshots the history keeps; the oldest
+// are dropped first. Shrinking the capacity drops the oldest snapshots
+// straight away. Non-positive values restore the default of 600.
+func (s *Simulation) SetHistoryCapacity(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n <= 0 {
+		n = defaultHistoryCapacity
+	}
+	s.historyCapacity = n
+	s.history.resize(n)
+}
+
+// HistoryCapacity returns how many snapshots the history keeps.
+func (s *Simulation) HistoryCapacity() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.historyCapacityLocked()
+}
+
+func (s *Simulation) historyCapacityLocked() int {
+	if s.historyCapacity <= 0 {
+		return defaultHistoryCapacity
+	}
+	return s.historyCapacity
+}
+
+// History returns a copy of the snapshots recorded after each tick, oldest
+// first. Clients can spot gaps left by pauses or a full buffer from the
+// snapshots' ticks.
+func (s *Simulation) History() []Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.history.snapshots()
+}
+
+func (s *Simulation) recordHistoryLocked(state Snapshot) {
+	s.history.push(state, s.historyCapacityLocked())
+}
+
+// Aggregates summarises a window of recorded history.
+type Aggregates struct {
+	// Ticks is the number of snapshots in the window; zero when no history
+	// has been recorded.
+	Ticks        int     `json:"ticks"`
+	FromTick     int     `json:"from_tick"`
+	ToTick       int     `json:"to_tick"`
+	MeanInfected float64 `json:"mean_infected"`
+	PeakInfected int     `json:"peak_infected"`
+	PeakTick     int     `json:"peak_tick"`
+	// Infections and Deaths are the increases in the cumulative counts from
+	// the window's first snapshot to its last.
+	Infections int `json:"infections"`
+	Deaths     int `json:"deaths"`
+	// TicksOverCapacity counts the snapshots with hospitals overloaded.
+	TicksOverCapacity int `json:"ticks_over_capacity"`
+}
+
+// Aggregate summarises the last window snapshots of history. Windows larger
+// than the recorded history, or non-positive ones, cover all of it.
+func (s *Simulation) Aggregate(window int) Aggregates {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	recorded := s.history.len()
+	if window <= 0 || window > recorded {
+		window = recorded
+	}
+	if window == 0 {
+		return Aggregates{}
+	}
+
+	first, last := s.history.at(recorded-window), s.history.at(recorded-1)
+	agg := Aggregates{
+		Ticks:        window,
+		FromTick:     first.Tick,
+		ToTick:       last.Tick,
+		PeakInfected: -1,
+		Infections:   last.TotalInfections - first.TotalInfections,
+		Deaths:       last.TotalDeaths - first.TotalDeaths,
+	}
+	sum := 0
+	for i := recorded - window; i < recorded; i++ {
+		state := s.history.at(i)
+		sum += state.CurrentInfected
+		if state.CurrentInfected > agg.PeakInfected {
+			agg.PeakInfected = state.CurrentInfected
+			agg.PeakTick = state.Tick
+		}
+		if state.Overloaded {
+			agg.TicksOverCapacity++
+		}
+	}
+	agg.MeanInfected = float64(sum) / float64(window)
+	return agg
+}
@@ -0,0 +1,156 @@
+package sim
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistoryRecordsEachTick(t *testing.T) {
+	s := New(0.25)
+	s.SetSeed(2)
+	for i := 0; i < 3; i++ {
+		s.Step()
+	}
+
+	history := s.History()
+	if len(history) != 3 {
+		t.Fatalf("expected 3 snapshots, got %d", len(history))
+	}
+	for i, state := range history {
+		if state.Tick != i+1 {
+			t.Fatalf("expected snapshot %d at tick %d, got %d", i, i+1, state.Tick)
+		}
+	}
+}
+
+func TestAggregateMatchesHandComputedHistory(t *testing.T) {
+	s := New(0.25)
+	for _, state := range []Snapshot{
+		{Tick: 1, CurrentInfected: 10, TotalInfections: 10},
+		{Tick: 2, CurrentInfected: 20, TotalInfections: 22, TotalDeaths: 1},
+		{Tick: 3, CurrentInfected: 60, TotalInfections: 64, TotalDeaths: 2, Overloaded: true},
+		{Tick: 4, CurrentInfected: 40, TotalInfections: 70, TotalDeaths: 6, Overloaded: true},
+	} {
+		s.recordHistoryLocked(state)
+	}
+
+	got := s.Aggregate(3)
+	want := Aggregates{
+		Ticks:             3,
+		FromTick:          2,
+		ToTick:            4,
+		MeanInfected:      40,
+		PeakInfected:      60,
+		PeakTick:          3,
+		Infections:        48,
+		Deaths:            5,
+		TicksOverCapacity: 2,
+	}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if all := s.Aggregate(100); all.Ticks != 4 || all.FromTick != 1 || all.MeanInfected != 32.5 {
+		t.Fatalf("expected an oversized window to cover all 4 ticks, got %+v", all)
+	}
+	if empty := New(0.25).Aggregate(5); empty != (Aggregates{}) {
+		t.Fatalf("expected zero aggregates without history, got %+v", empty)
+	}
+}
+
+func TestHistoryKeepsTheNewestSnapshotsWithinCapacity(t *testing.T) {
+	s := New(0.25)
+	s.SetSeed(3)
+	if got := s.HistoryCapacity(); got != 600 {
+		t.Fatalf("expected a default capacity of 600, got %d", got)
+	}
+	s.SetHistoryCapacity(5)
+	for i := 0; i < 12; i++ {
+		s.Step()
+	}
+
+	history := s.History()
+	if len(history) != 5 {
+		t.Fatalf("expected 5 snapshots, got %d", len(history))
+	}
+	for i, state := range history {
+		if state.Tick != 8+i {
+			t.Fatalf("expected ticks 8 to 12 in order, got %d at position %d", state.Tick, i)
+		}
+	}
+
+	s.SetHistoryCapacity(2)
+	if history := s.History(); len(history) != 2 || history[0].Tick != 11 || history[1].Tick != 12 {
+		t.Fatalf("expected shrinking to keep ticks 11 and 12, got %d snapshots", len(history))
+	}
+}
+
+func TestHistoryIsSafeToReadWhileRunning(t *testing.T) {
+	s := NewWithSeed(0.25, 5)
+	s.SetLogger(nil)
+	s.SetHistoryCapacity(20)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, MinTickInterval, nil)
+	}()
+
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			deadline := time.Now().Add(2 * time.Second)
+			for time.Now().Before(deadline) {
+				history := s.History()
+				for i := 1; i < len(history); i++ {
+					if history[i].Tick != history[i-1].Tick+1 {
+						t.Errorf("expected consecutive ticks, got %d after %d", history[i].Tick, history[i-1].Tick)
+						return
+					}
+				}
+				if aggregate := s.Aggregate(10); aggregate.Ticks > 10 {
+					t.Errorf("expected at most 10 ticks in the window, got %d", aggregate.Ticks)
+					return
+				}
+				if len(history) > 0 && history[len(history)-1].Tick >= 50 {
+					return
+				}
+			}
+			t.Error("expected the run to reach tick 50 while reading history")
+		}()
+	}
+	readers.Wait()
+	cancel()
+	<-done
+}
+
+// BenchmarkHistoryDuringRun measures History calls from several goroutines
+// while Run ticks as fast as it is allowed to.
+func BenchmarkHistoryDuringRun(b *testing.B) {
+	s := newBenchmarkSimulation()
+	s.SetLogger(nil)
+	for i := 0; i < s.HistoryCapacity(); i++ {
+		s.Step()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, MinTickInterval, nil)
+	}()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.History()
+		}
+	})
+	b.StopTimer()
+	cancel()
+	<-done
+}
@@ -0,0 +1,75 @@
+package sim
+
+import "math"
+
+// SetImmunityDuration makes immunity from recovery and vaccination wane
+// after ticks on average: each tick, every recovered or vaccinated person
+// independently becomes susceptible again with probability 1/ticks, so
+// repeated waves can follow. Zero, negative, or non-finite durations keep
+// immunity forever, the default. Durations in (0, 1) are raised to 1. The
+// immune compartment does not wane, and with a transition matrix set its
+// Recovered row governs waning instead. Waning applies to the single pool,
+// not to regions or spatial mode.
+func (s *Simulation) SetImmunityDuration(ticks float64) {
+	if ticks <= 0 || math.IsNaN(ticks) || math.IsInf(ticks, 0) {
+		ticks = 0
+	} else if ticks < 1 {
+		ticks = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.immunityDuration = ticks
+}
+
+// ImmunityDuration returns the mean ticks immunity lasts, or 0 when it never
+// wanes.
+func (s *Simulation) ImmunityDuration() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.immunityDuration
+}
+
+// waneImmunityLocked returns this tick's recovered and vaccinated people who
+// lost their immunity to the susceptible pool.
+func (s *Simulation) waneImmunityLocked() {
+	s.waned, s.reinfections = 0, 0
+	if s.immunityDuration == 0 || s.transitions != nil {
+		return
+	}
+
+	probability := 1 / s.immunityDuration
+	recovered := s.binomialLocked(s.currentRecovered, probability)
+	vaccinated := s.binomialLocked(s.currentVaccinated, probability)
+	s.currentRecovered -= recovered
+	s.currentVaccinated -= vaccinated
+	s.waned = recovered + vaccinated
+	// An unbounded population has no susceptible count to return them to,
+	// so their reinfections cannot be told apart.
+	if s.population > 0 {
+		s.currentSusceptible += s.waned
+		s.wanedSusceptible += s.waned
+	}
+}
+
+// countReinfectionsLocked records how many of count infections drawn from
+// the susceptible pool hit people whose immunity had waned.
+func (s *Simulation) countReinfectionsLocked(count int) {
+	if s.wanedSusceptible == 0 || count == 0 {
+		return
+	}
+
+	// Vaccination may have taken some of them, so the share is capped.
+	share := min(float64(s.wanedSusceptible)/float64(s.currentSusceptible+count), 1)
+	reinfections := min(s.binomialLocked(count, share), s.wanedSusceptible)
+	s.wanedSusceptible -= reinfections
+	s.reinfections += reinfections
+}
+
+// clearWaningLocked forgets who lost their immunity, for compartments that
+// were just set afresh.
+func (s *Simulation) clearWaningLocked() {
+	s.wanedSusceptible, s.waned, s.reinfections = 0, 0, 0
+}
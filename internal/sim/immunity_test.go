@@ -0,0 +1,83 @@
+package sim
+
+import "testing"
+
+func newWaningSimulation(t *testing.T, duration float64) *Simulation {
+	t.Helper()
+
+	s := NewWithSeed(0.3, 8)
+	cfg := s.Config()
+	cfg.Population = 1000
+	cfg.InitialInfected = 20
+	cfg.InitialRecovered = 500
+	if err := s.ApplyConfig(cfg); err != nil {
+		t.Fatalf("apply config: %v", err)
+	}
+	s.SetImmunityDuration(duration)
+	return s
+}
+
+func TestImmunityWanesBackToSusceptible(t *testing.T) {
+	s := newWaningSimulation(t, 5)
+
+	state := s.Step()
+	if state.Waned == 0 {
+		t.Fatal("expected some recovered people to lose their immunity")
+	}
+	accounted := state.CurrentSusceptible + state.CurrentInfected + state.CurrentRecovered + state.CurrentImmune +
+		state.CurrentVaccinated + state.TotalDeaths
+	if accounted != state.Population {
+		t.Fatalf("expected the compartments to add up to %d, got %d", state.Population, accounted)
+	}
+
+	reinfections := 0
+	for i := 0; i < 50; i++ {
+		state = s.Step()
+		reinfections += state.Reinfections
+	}
+	if reinfections == 0 {
+		t.Fatal("expected people whose immunity waned to be reinfected")
+	}
+}
+
+func TestImmunityLastsForeverByDefault(t *testing.T) {
+	s := newWaningSimulation(t, 0)
+
+	recovered := s.Snapshot().CurrentRecovered
+	for i := 0; i < 30; i++ {
+		state := s.Step()
+		if state.Waned != 0 || state.Reinfections != 0 {
+			t.Fatalf("tick %d: expected no waning, got %d waned and %d reinfections",
+				state.Tick, state.Waned, state.Reinfections)
+		}
+		if state.CurrentRecovered < recovered {
+			t.Fatalf("tick %d: recovered fell from %d to %d", state.Tick, recovered, state.CurrentRecovered)
+		}
+		recovered = state.CurrentRecovered
+	}
+}
+
+func TestVaccinatedImmunityWanes(t *testing.T) {
+	s := NewWithSeed(0.3, 8)
+	s.SetPopulation(1000)
+	s.StartVaccination(1000)
+	s.Step()
+	s.SetImmunityDuration(2)
+
+	vaccinated := s.Snapshot().CurrentVaccinated
+	state := s.Step()
+	if state.CurrentVaccinated >= vaccinated || state.Waned == 0 {
+		t.Fatalf("expected vaccinated people to lose their immunity, got %d of %d still vaccinated",
+			state.CurrentVaccinated, vaccinated)
+	}
+}
+
+func TestSetImmunityDurationNormalisesInput(t *testing.T) {
+	s := New(0.3)
+	for _, tc := range []struct{ in, want float64 }{{-3, 0}, {0.5, 1}, {30, 30}} {
+		s.SetImmunityDuration(tc.in)
+		if got := s.ImmunityDuration(); got != tc.want {
+			t.Fatalf("SetImmunityDuration(%v): expected %v, got %v", tc.in, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package sim
+
+import "math"
+
+// CombinationMode decides how several interventions that each cut
+// transmission add up.
+type CombinationMode int
+
+const (
+	// CombineMultiplicative treats interventions as independent: each one
+	// removes its share of whatever transmission the others left, so two 50%
+	// cuts leave 25%.
+	CombineMultiplicative CombinationMode = iota
+	// CombineAdditive sums the reductions, so two 50% cuts stop transmission
+	// entirely. The combined reduction is capped at 100%.
+	CombineAdditive
+)
+
+// interventionFactors breaks the infection probability down into the
+// multiplier contributed by each intervention. A factor of 1 means no effect.
+// Snapshots report them as TransmissionModifier, LockdownFactor,
+// BehaviorFactor, and CombinedFactor.
+type interventionFactors struct {
+	// modifier is the UI transmission modifier (masking, distancing).
+	modifier float64
+	// lockdown applies while lockdown is enabled; see SetLockdownEffect.
+	lockdown float64
+	// behavior is the voluntary reduction from SetBehavioralResponse.
+	behavior float64
+	// combined merges the factors above according to the CombinationMode.
+	combined float64
+}
+
+// SetInterventionCombination selects how intervention factors combine into
+// the infection probability. The default is CombineMultiplicative.
+func (s *Simulation) SetInterventionCombination(mode CombinationMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.combination = mode
+}
+
+// SetLockdownEffect sets the fraction of transmission removed while lockdown
+// is enabled, clamped to [0, 1]. It defaults to 0: lockdown then only slows
+// agent movement and leaves the aggregate infection probability alone.
+func (s *Simulation) SetLockdownEffect(reduction float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lockdownEffect = math.Min(math.Max(reduction, 0), 1)
+}
+
+func (s *Simulation) interventionFactorsLocked() interventionFactors {
+	factors := interventionFactors{
+		modifier: s.currentTransmissionModifierLocked(),
+		lockdown: 1,
+		behavior: 1 - s.voluntaryReductionLocked(),
+	}
+	if s.lockdownEnabled {
+		factors.lockdown = 1 - s.lockdownEffect
+	}
+
+	switch s.combination {
+	case CombineAdditive:
+		reduction := (1 - factors.modifier) + (1 - factors.lockdown) + (1 - factors.behavior)
+		factors.combined = math.Max(1-reduction, 0)
+	default:
+		factors.combined = factors.modifier * factors.lockdown * factors.behavior
+	}
+	return factors
+}
@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterventionCombinationModes(t *testing.T) {
+	newSim := func(mode CombinationMode) *Simulation {
+		s := New(0.5)
+		s.SetInterventionCombination(mode)
+		s.UpdateTransmissionModifier(0.6) // 40% cut
+		s.SetLockdownEffect(0.3)
+		s.SetLockdown(true) // 30% cut
+		s.currentInfected = 100
+		s.SetBehavioralResponse(BehavioralResponse{MaxReduction: 0.4, Midpoint: 100}) // 20% cut
+		return s
+	}
+
+	cases := []struct {
+		mode     CombinationMode
+		combined float64
+	}{
+		{CombineMultiplicative, 0.6 * 0.7 * 0.8},
+		{CombineAdditive, 1 - (0.4 + 0.3 + 0.2)},
+	}
+	for _, tc := range cases {
+		state := newSim(tc.mode).Snapshot()
+		if state.TransmissionModifier != 0.6 || math.Abs(state.LockdownFactor-0.7) > 1e-9 || math.Abs(state.BehaviorFactor-0.8) > 1e-9 {
+			t.Fatalf("mode %d: unexpected factors %v, %v, %v", tc.mode, state.TransmissionModifier, state.LockdownFactor, state.BehaviorFactor)
+		}
+		if math.Abs(state.CombinedFactor-tc.combined) > 1e-9 {
+			t.Fatalf("mode %d: expected combined factor %v, got %v", tc.mode, tc.combined, state.CombinedFactor)
+		}
+		if math.Abs(state.InfectionProbability-0.5*tc.combined) > 1e-9 {
+			t.Fatalf("mode %d: expected probability %v, got %v", tc.mode, 0.5*tc.combined, state.InfectionProbability)
+		}
+	}
+
+	additive := newSim(CombineAdditive)
+	additive.UpdateTransmissionModifier(0.2)
+	if got := additive.Snapshot().InfectionProbability; got != 0 {
+		t.Fatalf("expected additive reductions beyond 100%% to stop transmission, got %v", got)
+	}
+}
@@ -0,0 +1,239 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+)
+
+const defaultInfectiousPeriod = 14
+
+// OutcomeModel selects how infected individuals leave the infected pool.
+type OutcomeModel int
+
+const (
+	// OutcomeMemoryless applies the effective death probability to every
+	// infected individual on every tick.
+	OutcomeMemoryless OutcomeModel = iota
+	// OutcomeScheduled draws each infection's outcome (die or recover) when it
+	// starts. Deaths land on a uniformly drawn tick within the infectious
+	// period; survivors recover when the period ends.
+	OutcomeScheduled
+)
+
+// RoundingMode controls how the memoryless model turns the expected number of
+// deaths (infected * death probability) into a whole count.
+type RoundingMode int
+
+const (
+	// RoundStochastic rounds up with probability equal to the fractional
+	// part, so the long-run average equals the expected count.
+	RoundStochastic RoundingMode = iota
+	// RoundTruncate drops the fractional part. It systematically
+	// under-counts deaths when probabilities are small.
+	RoundTruncate
+	// RoundNearest rounds half away from zero.
+	RoundNearest
+)
+
+// SetRoundingMode selects how expected deaths become a whole count per tick.
+func (s *Simulation) SetRoundingMode(mode RoundingMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roundingMode = mode
+}
+
+// RoundingMode reports the active death rounding mode.
+func (s *Simulation) RoundingMode() RoundingMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.roundingMode
+}
+
+// SetRecoveryRate sets the per-tick probability that an infected individual
+// recovers under the memoryless model, before any care bonus. Recovered
+// individuals leave the infected pool for good and are reported as
+// CurrentRecovered. Values are clamped to [0, 1]; the default of 0 keeps
+// everyone infected until they die.
+func (s *Simulation) SetRecoveryRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recoveryRate = math.Min(math.Max(rate, 0), 1)
+}
+
+// RecoveryRate returns the memoryless per-tick recovery probability.
+func (s *Simulation) RecoveryRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.recoveryRate
+}
+
+// resolveMemorylessLocked gives every infected individual exactly one outcome
+// this tick: die, recover, or stay infected. Deaths are resolved first and
+// recoveries are drawn only from the survivors, using the recovery chance
+// conditional on not dying. This is the sequential form of a single
+// multinomial draw, so nobody both dies and recovers and the resolved total
+// never exceeds the infected count.
+func (s *Simulation) resolveMemorylessLocked() (deaths, recoveries int) {
+	if len(s.ageBrackets) > 0 {
+		return s.resolveAgesLocked()
+	}
+	deathProbability, _ := s.deathProbabilityLocked()
+	return s.resolveOutcomesLocked(s.currentInfected, deathProbability, s.recoveryProbabilityLocked())
+}
+
+// resolveOutcomesLocked draws this tick's deaths and recoveries among infected
+// people who die and recover with the given per-tick probabilities.
+func (s *Simulation) resolveOutcomesLocked(infected int, deathProbability, recoveryProbability float64) (deaths, recoveries int) {
+	deaths = min(roundCount(float64(infected)*deathProbability, s.roundingMode, s.rng), infected)
+
+	if recoveryProbability > 0 && deathProbability < 1 {
+		survivors := infected - deaths
+		conditional := math.Min(recoveryProbability/(1-deathProbability), 1)
+		recoveries = min(roundCount(float64(survivors)*conditional, s.roundingMode, s.rng), survivors)
+	}
+	return deaths, recoveries
+}
+
+func roundCount(expected float64, mode RoundingMode, rng *rand.Rand) int {
+	switch mode {
+	case RoundTruncate:
+		return int(math.Floor(expected))
+	case RoundNearest:
+		return int(math.Round(expected))
+	default:
+		whole := math.Floor(expected)
+		if rng.Float64() < expected-whole {
+			whole++
+		}
+		return int(whole)
+	}
+}
+
+type scheduledOutcome struct {
+	deaths     int
+	recoveries int
+}
+
+// SetOutcomeModel switches how deaths are resolved. infectiousPeriod is the
+// number of days an infection lasts under OutcomeScheduled, converted to
+// ticks with DaysPerTick; non-positive values fall back to 14. Switching to
+// the scheduled model schedules everyone currently infected as if they had
+// just been infected.
+func (s *Simulation) SetOutcomeModel(model OutcomeModel, infectiousPeriod int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if infectiousPeriod <= 0 {
+		infectiousPeriod = defaultInfectiousPeriod
+	}
+
+	previous := s.outcomeModel
+	s.outcomeModel = model
+	s.infectiousPeriod = infectiousPeriod
+	s.outcomes = nil
+	if model == OutcomeScheduled && previous != OutcomeScheduled {
+		s.scheduleInitialOutcomesLocked(s.currentInfected)
+	}
+}
+
+// SetInitialInfectionJitter staggers the initial cohort's outcome timers
+// under OutcomeScheduled. Each infection present when outcomes are first
+// scheduled, by SetOutcomeModel or ApplyConfig, is treated as having started
+// a uniformly drawn 0 to ticks ticks earlier, so the cohort resolves over a
+// window instead of in one synchronized wave. The offset is capped so every
+// infection keeps at least one tick to run. Zero or negative values disable
+// the jitter, which is the default.
+func (s *Simulation) SetInitialInfectionJitter(ticks int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.initialJitter = max(ticks, 0)
+}
+
+// InitialInfectionJitter returns the initial cohort's jitter window in ticks.
+func (s *Simulation) InitialInfectionJitter() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.initialJitter
+}
+
+// OutcomeModel reports the active outcome model.
+func (s *Simulation) OutcomeModel() OutcomeModel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.outcomeModel
+}
+
+// scheduleOutcomesLocked draws outcomes for count new infections. The chance
+// of dying is the probability of at least one death draw succeeding across the
+// infectious period at the current effective death probability.
+func (s *Simulation) scheduleOutcomesLocked(count int) {
+	s.scheduleAgedOutcomesLocked(count, 0)
+}
+
+// scheduleInitialOutcomesLocked schedules the initial cohort, staggered by the
+// configured jitter.
+func (s *Simulation) scheduleInitialOutcomesLocked(count int) {
+	s.scheduleAgedOutcomesLocked(count, s.initialJitter)
+}
+
+// scheduleAgedOutcomesLocked draws outcomes for count infections that each
+// started a uniformly drawn 0 to jitter ticks ago, leaving them only the rest
+// of their infectious period.
+func (s *Simulation) scheduleAgedOutcomesLocked(count, jitter int) {
+	if count <= 0 {
+		return
+	}
+
+	period := s.infectiousTicksLocked()
+	for len(s.outcomes) < period {
+		s.outcomes = append(s.outcomes, scheduledOutcome{})
+	}
+	jitter = min(jitter, period-1)
+
+	deathProbability, _ := s.deathProbabilityLocked()
+	for i := 0; i < count; i++ {
+		remaining := period
+		if jitter > 0 {
+			remaining -= s.rng.Intn(jitter + 1)
+		}
+		fatality := 1 - math.Pow(1-deathProbability, float64(remaining))
+		if s.rng.Float64() < fatality {
+			s.outcomes[s.rng.Intn(remaining)].deaths++
+		} else {
+			s.outcomes[remaining-1].recoveries++
+		}
+	}
+}
+
+// resolveScheduledOutcomesLocked removes the outcomes due this tick from the
+// infected pool.
+func (s *Simulation) resolveScheduledOutcomesLocked() {
+	if len(s.outcomes) == 0 {
+		return
+	}
+
+	due := s.outcomes[0]
+	s.outcomes = s.outcomes[1:]
+	deaths := min(due.deaths, s.currentInfected)
+	s.currentInfected -= deaths
+	s.totalDeaths += deaths
+	recoveries := min(due.recoveries, s.currentInfected)
+	s.currentInfected -= recoveries
+	s.totalRecoveries += recoveries
+	s.currentRecovered += recoveries
+}
+
+func (s *Simulation) scheduledDeathsLocked() int {
+	total := 0
+	for _, outcome := range s.outcomes {
+		total += outcome.deaths
+	}
+	return total
+}
@@ -0,0 +1,194 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestScheduledDeathsSpreadAcrossInfectiousPeriod(t *testing.T) {
+	s := New(0.2)
+	s.rng = rand.New(rand.NewSource(1))
+	s.UpdateTransmissionModifier(0)
+	s.SetHospitalCapacity(0)
+	s.baseDeathRate = 0.2
+	s.currentInfected = 200
+
+	const period = 10
+	s.SetOutcomeModel(OutcomeScheduled, period)
+
+	pending := s.Snapshot().ScheduledDeaths
+	if pending == 0 {
+		t.Fatal("expected deaths to be scheduled for the current infected")
+	}
+
+	deathsPerTick := make([]int, 0, period)
+	for tick := 0; tick < period; tick++ {
+		s.stepEpidemic()
+		remaining := s.Snapshot().ScheduledDeaths
+		deathsPerTick = append(deathsPerTick, pending-remaining)
+		pending = remaining
+	}
+
+	if pending != 0 {
+		t.Fatalf("expected every scheduled death to resolve within the period, %d left", pending)
+	}
+	if got := s.CurrentInfected(); got != 0 {
+		t.Fatalf("expected survivors to recover at the end of the period, %d still infected", got)
+	}
+
+	ticksWithDeaths, total, largest := 0, 0, 0
+	for _, deaths := range deathsPerTick {
+		if deaths > 0 {
+			ticksWithDeaths++
+		}
+		total += deaths
+		largest = max(largest, deaths)
+	}
+	if ticksWithDeaths < period/2 {
+		t.Fatalf("expected deaths on at least %d ticks, got %v", period/2, deathsPerTick)
+	}
+	if largest*2 > total {
+		t.Fatalf("expected no single tick to hold most deaths, got %v", deathsPerTick)
+	}
+}
+
+func TestMemorylessOutcomeIsDefault(t *testing.T) {
+	s := New(0.2)
+	if s.OutcomeModel() != OutcomeMemoryless {
+		t.Fatalf("expected memoryless outcome model by default, got %v", s.OutcomeModel())
+	}
+	if got := s.Snapshot().ScheduledDeaths; got != 0 {
+		t.Fatalf("expected no scheduled deaths by default, got %d", got)
+	}
+}
+
+func TestRoundingModesLongRunDeaths(t *testing.T) {
+	const (
+		infected  = 7
+		deathRate = 0.3
+		trials    = 20000
+	)
+	expected := infected * deathRate
+
+	cases := []struct {
+		name string
+		mode RoundingMode
+		want float64
+		tol  float64
+	}{
+		{name: "stochastic", mode: RoundStochastic, want: expected, tol: 0.02},
+		{name: "truncate", mode: RoundTruncate, want: 2, tol: 0},
+		{name: "nearest", mode: RoundNearest, want: 2, tol: 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(0.2)
+			s.rng = rand.New(rand.NewSource(7))
+			s.UpdateTransmissionModifier(0)
+			s.SetHospitalCapacity(0)
+			s.baseDeathRate = deathRate
+			s.SetRoundingMode(tc.mode)
+
+			for i := 0; i < trials; i++ {
+				s.currentInfected = infected
+				s.stepEpidemic()
+			}
+
+			average := float64(s.Snapshot().TotalDeaths) / trials
+			if math.Abs(average-tc.want) > tc.tol {
+				t.Fatalf("expected average deaths %.3f (±%.2f), got %.3f", tc.want, tc.tol, average)
+			}
+		})
+	}
+}
+
+func TestMemorylessOutcomesResolveEachInfectionOnce(t *testing.T) {
+	s := New(0.3)
+	s.rng = rand.New(rand.NewSource(5))
+	s.baseDeathRate = 0.6
+	s.SetHospitalCapacity(0)
+	s.SetRecoveryRate(0.6)
+
+	for infected := 0; infected <= 200; infected++ {
+		s.currentInfected = infected
+		deaths, recoveries := s.resolveMemorylessLocked()
+		if deaths < 0 || recoveries < 0 || deaths+recoveries > infected {
+			t.Fatalf("infected=%d resolved %d deaths and %d recoveries", infected, deaths, recoveries)
+		}
+	}
+
+	s.currentInfected = 1000
+	s.baseDeathRate = 0.05
+	s.SetRecoveryRate(0.1)
+	for i := 0; i < 20; i++ {
+		before := s.Snapshot()
+		after := s.Step()
+		newInfections := after.TotalInfections - before.TotalInfections
+		died := after.TotalDeaths - before.TotalDeaths
+		recovered := after.TotalRecoveries - before.TotalRecoveries
+		resolved := before.CurrentInfected + newInfections - after.CurrentInfected
+		if resolved != died+recovered {
+			t.Fatalf("tick %d: resolved %d but recorded %d deaths and %d recoveries", after.Tick, resolved, died, recovered)
+		}
+		if resolved > before.CurrentInfected+newInfections {
+			t.Fatalf("tick %d: resolved %d of only %d infected", after.Tick, resolved, before.CurrentInfected+newInfections)
+		}
+	}
+}
+
+func TestInitialInfectionJitterStaggersRecoveries(t *testing.T) {
+	s := New(0.2)
+	s.SetSeed(6)
+	s.UpdateTransmissionModifier(0)
+	s.baseDeathRate = 0
+	s.currentInfected = 400
+
+	const period, jitter = 10, 4
+	s.SetInitialInfectionJitter(jitter)
+	s.SetOutcomeModel(OutcomeScheduled, period)
+
+	for tick, outcome := range s.outcomes {
+		inWindow := tick >= period-1-jitter
+		if inWindow && outcome.recoveries == 0 {
+			t.Fatalf("expected recoveries on every tick of the jitter window, got none at slot %d", tick)
+		}
+		if !inWindow && outcome.recoveries != 0 {
+			t.Fatalf("expected no recoveries before the jitter window, got %d at slot %d", outcome.recoveries, tick)
+		}
+	}
+
+	// Infections after the initial cohort keep the full period.
+	s.outcomes = nil
+	s.scheduleOutcomesLocked(50)
+	if got := s.outcomes[period-1].recoveries; got != 50 {
+		t.Fatalf("expected new infections to recover at the end of the period, got %d", got)
+	}
+}
+
+func TestRecoveredLeaveTheInfectedPool(t *testing.T) {
+	s := New(0.2)
+	s.SetSeed(8)
+	s.UpdateTransmissionModifier(0)
+	s.baseDeathRate = 0
+	s.SetRecoveryRate(3)
+	if got := s.RecoveryRate(); got != 1 {
+		t.Fatalf("expected recovery rate clamped to 1, got %v", got)
+	}
+	s.SetRecoveryRate(0.2)
+	s.currentInfected = 500
+
+	previous := s.Snapshot().CurrentRecovered
+	for i := 0; i < 10; i++ {
+		state := s.Step()
+		if state.CurrentInfected+state.CurrentRecovered != 500 {
+			t.Fatalf("tick %d: expected infected plus recovered to stay 500, got %d + %d",
+				state.Tick, state.CurrentInfected, state.CurrentRecovered)
+		}
+		if state.CurrentRecovered <= previous {
+			t.Fatalf("tick %d: expected recoveries, recovered stayed at %d", state.Tick, state.CurrentRecovered)
+		}
+		previous = state.CurrentRecovered
+	}
+}
@@ -0,0 +1,117 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// SetParallelism spreads spatial-mode contact drawing across n worker
+// goroutines, each taking the infectious agents in one vertical strip of the
+// world. Every tick each worker gets its own random stream seeded from the
+// simulation's, so a run is reproducible for a given seed and worker count,
+// though different worker counts give different runs. Zero or negative n
+// uses one worker per CPU; the default of 1 draws serially on the
+// simulation's own stream.
+func (s *Simulation) SetParallelism(n int) {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.parallelism = n
+}
+
+// Parallelism returns the number of workers drawing spatial contacts.
+func (s *Simulation) Parallelism() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return max(s.parallelism, 1)
+}
+
+// drawAgentContactsLocked draws the infections passed on this tick by the
+// infectious agents in sources, which are in ascending order, returning the
+// agents infected and the number of contacts made.
+func (s *Simulation) drawAgentContactsLocked(grid *spatialGrid, sources []int, probability float64) ([]int, int) {
+	workers := min(s.parallelism, len(sources))
+	if workers <= 1 {
+		return s.agentContactsLocked(grid, sources, probability, s.rng)
+	}
+
+	strips := s.stripsLocked(sources, workers)
+	seeds := make([]int64, len(strips))
+	for i := range seeds {
+		seeds[i] = s.rng.Int63()
+	}
+
+	infections := make([][]int, len(strips))
+	contacts := make([]int, len(strips))
+	var wg sync.WaitGroup
+	for w, strip := range strips {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seeds[w]))
+			infections[w], contacts[w] = s.agentContactsLocked(grid, strip, probability, rng)
+		}()
+	}
+	wg.Wait()
+
+	var merged []int
+	total := 0
+	for w := range strips {
+		merged = append(merged, infections[w]...)
+		total += contacts[w]
+	}
+	return merged, total
+}
+
+// agentContactsLocked draws, on rng, the infections passed on by the agents
+// in sources. It only reads the agents, so workers may share them.
+func (s *Simulation) agentContactsLocked(grid *spatialGrid, sources []int, probability float64, rng *rand.Rand) ([]int, int) {
+	var infections, candidates []int
+	contacts := 0
+	radiusSquared := s.infectionRadius * s.infectionRadius
+	for _, i := range sources {
+		candidates = s.candidatesLocked(grid, i, candidates[:0])
+		for _, j := range candidates {
+			target := &s.agents[j]
+			if !target.susceptible() {
+				continue
+			}
+			dx, dy := target.X-s.agents[i].X, target.Y-s.agents[i].Y
+			if dx*dx+dy*dy > radiusSquared {
+				continue
+			}
+			contacts++
+			if rng.Float64() < probability {
+				infections = append(infections, j)
+			}
+		}
+	}
+	return infections, contacts
+}
+
+// stripsLocked splits sources into n equally wide vertical strips of the
+// area they span, keeping each strip in ascending order.
+func (s *Simulation) stripsLocked(sources []int, n int) [][]int {
+	left, right := math.Inf(1), math.Inf(-1)
+	for _, i := range sources {
+		left, right = math.Min(left, s.agents[i].X), math.Max(right, s.agents[i].X)
+	}
+
+	strips := make([][]int, n)
+	width := (right - left) / float64(n)
+	for _, i := range sources {
+		strip := 0
+		if width > 0 {
+			strip = min(int((s.agents[i].X-left)/width), n-1)
+		}
+		strips[strip] = append(strips[strip], i)
+	}
+	return strips
+}
@@ -0,0 +1,54 @@
+package sim
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParallelSpatialStepIsReproducible(t *testing.T) {
+	run := func(workers int) (Snapshot, []Agent) {
+		s := newCrowdSimulation(2000, false)
+		s.SetParallelism(workers)
+		var state Snapshot
+		for i := 0; i < 20; i++ {
+			state = s.Step()
+		}
+		return state, s.Agents()
+	}
+
+	first, firstAgents := run(4)
+	second, secondAgents := run(4)
+	if !reflect.DeepEqual(first, second) || !reflect.DeepEqual(firstAgents, secondAgents) {
+		t.Fatal("expected the same seed and worker count to replay identically")
+	}
+
+	if first.TotalInfections == 0 {
+		t.Fatal("expected the crowd to spread the infection")
+	}
+}
+
+func TestSetParallelismDefaultsToCPUs(t *testing.T) {
+	s := New(0.2)
+	if got := s.Parallelism(); got != 1 {
+		t.Fatalf("expected serial stepping by default, got %d workers", got)
+	}
+	s.SetParallelism(0)
+	if got := s.Parallelism(); got < 1 {
+		t.Fatalf("expected at least one worker per CPU, got %d", got)
+	}
+}
+
+func BenchmarkParallelSpatialStep(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("agents=50000/workers=%d", workers), func(b *testing.B) {
+			s := newCrowdSimulation(50000, false)
+			s.SetLogger(nil)
+			s.SetParallelism(workers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Step()
+			}
+		})
+	}
+}
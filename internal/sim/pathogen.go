@@ -0,0 +1,100 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultPathogen names the profile built from the parameters passed to New.
+const DefaultPathogen = "default"
+
+// Profile bundles the disease parameters that distinguish one pathogen from
+// another.
+type Profile struct {
+	// BaseTransmission is the per-contact infection probability before the
+	// transmission modifier is applied.
+	BaseTransmission float64
+	// BaseDeathRate is the per-tick death probability while hospitals cope.
+	BaseDeathRate float64
+	// InfectiousPeriod is the length of an infection in days under the
+	// scheduled outcome model.
+	InfectiousPeriod int
+}
+
+func sanitizeProfile(profile Profile) Profile {
+	if profile.BaseTransmission < 0 {
+		profile.BaseTransmission = 0
+	} else if profile.BaseTransmission > 1 {
+		profile.BaseTransmission = 1
+	}
+	if profile.BaseDeathRate < 0 {
+		profile.BaseDeathRate = 0
+	} else if profile.BaseDeathRate > 1 {
+		profile.BaseDeathRate = 1
+	}
+	if profile.InfectiousPeriod <= 0 {
+		profile.InfectiousPeriod = defaultInfectiousPeriod
+	}
+	return profile
+}
+
+// AddPathogen registers or replaces a named profile. Replacing the active
+// profile applies the new parameters immediately.
+func (s *Simulation) AddPathogen(name string, profile Profile) error {
+	if name == "" {
+		return fmt.Errorf("pathogen name must not be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pathogens[name] = sanitizeProfile(profile)
+	if name == s.activePathogen {
+		s.applyProfileLocked(s.pathogens[name])
+	}
+	return nil
+}
+
+// SetActivePathogen switches the simulation to a registered profile. Current
+// compartment counts are kept; only the disease parameters change.
+func (s *Simulation) SetActivePathogen(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile, ok := s.pathogens[name]
+	if !ok {
+		return fmt.Errorf("unknown pathogen %q", name)
+	}
+
+	s.activePathogen = name
+	s.applyProfileLocked(profile)
+	s.version++
+	return nil
+}
+
+// ActivePathogen returns the name of the profile currently in effect.
+func (s *Simulation) ActivePathogen() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.activePathogen
+}
+
+// Pathogens lists the registered profile names in sorted order.
+func (s *Simulation) Pathogens() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.pathogens))
+	for name := range s.pathogens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Simulation) applyProfileLocked(profile Profile) {
+	s.baseTransmission = profile.BaseTransmission
+	s.baseDeathRate = profile.BaseDeathRate
+	s.infectiousPeriod = profile.InfectiousPeriod
+}
@@ -0,0 +1,50 @@
+package sim
+
+import "testing"
+
+func TestSetActivePathogenSwitchesParameters(t *testing.T) {
+	s := New(0.2)
+	s.currentInfected = 42
+
+	if got := s.ActivePathogen(); got != DefaultPathogen {
+		t.Fatalf("expected %q to be active, got %q", DefaultPathogen, got)
+	}
+
+	if err := s.AddPathogen("measles", Profile{BaseTransmission: 0.9, BaseDeathRate: 0.03, InfectiousPeriod: 10}); err != nil {
+		t.Fatalf("add pathogen: %v", err)
+	}
+	if err := s.SetActivePathogen("measles"); err != nil {
+		t.Fatalf("activate pathogen: %v", err)
+	}
+
+	snap := s.Snapshot()
+	if snap.ActivePathogen != "measles" {
+		t.Fatalf("expected measles to be active, got %q", snap.ActivePathogen)
+	}
+	if snap.InfectionProbability != 0.9 {
+		t.Fatalf("expected infection probability 0.9, got %v", snap.InfectionProbability)
+	}
+	if snap.EffectiveDeathProbability != 0.03 {
+		t.Fatalf("expected death probability 0.03, got %v", snap.EffectiveDeathProbability)
+	}
+	if snap.CurrentInfected != 42 {
+		t.Fatalf("expected infected count to be kept, got %d", snap.CurrentInfected)
+	}
+
+	if err := s.SetActivePathogen(DefaultPathogen); err != nil {
+		t.Fatalf("restore default pathogen: %v", err)
+	}
+	if got := s.InfectionProbability(); got != 0.2 {
+		t.Fatalf("expected default infection probability 0.2, got %v", got)
+	}
+}
+
+func TestSetActivePathogenRejectsUnknownName(t *testing.T) {
+	s := New(0.2)
+	if err := s.SetActivePathogen("smallpox"); err == nil {
+		t.Fatal("expected an error for an unregistered pathogen")
+	}
+	if got := s.ActivePathogen(); got != DefaultPathogen {
+		t.Fatalf("expected active pathogen to stay %q, got %q", DefaultPathogen, got)
+	}
+}
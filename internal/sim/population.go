@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// defaultPopulation is the population New starts with. New used to leave the
+// population unbounded, which let every run grow exponentially forever; 1000
+// matches the built-in scenarios. SetPopulation(0) restores the old
+// behaviour.
+const defaultPopulation = 1000
+
+// ErrPopulationTooSmall is returned when a population change would need to
+// remove people who are not susceptible.
+var ErrPopulationTooSmall = errors.New("population smaller than the people accounted for")
+
+// SetPopulation sets the total population and recomputes the susceptible pool
+// as everyone not exposed, infected, recovered, immune, vaccinated, or dead.
+// New infections are drawn from that pool, so the epidemic slows as it runs
+// out. Populations too small for the people already accounted for are raised
+// to fit; zero or negative values remove the limit.
+func (s *Simulation) SetPopulation(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setPopulationLocked(n)
+	s.start.population = s.population
+}
+
+// Population returns the total population, or 0 when it is unbounded.
+func (s *Simulation) Population() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.population
+}
+
+// SetInitialImmuneFraction sets the share of the population that starts the
+// run immune, from a prior epidemic or vaccination campaign. The immune count
+// is worked out from the starting population and capped by the people not
+// already starting infected, exposed, or recovered. It takes effect whenever
+// the run starts over with Reset, and straight away before the first tick.
+// Values are clamped to [0, 1]; an unbounded population has nobody to make
+// immune. ApplyConfig replaces it with the config's initial_immune count.
+func (s *Simulation) SetInitialImmuneFraction(f float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if math.IsNaN(f) {
+		f = 0
+	}
+	f = math.Min(math.Max(f, 0), 1)
+	available := max(s.start.population-s.start.infected-s.start.exposed-s.start.recovered, 0)
+	s.start.immune = min(int(math.Round(f*float64(s.start.population))), available)
+	if s.tick == 0 {
+		s.currentImmune = s.start.immune
+		s.setPopulationLocked(s.population)
+	}
+}
+
+func (s *Simulation) setPopulationLocked(n int) {
+	if n <= 0 {
+		s.population = 0
+		s.currentSusceptible = 0
+		return
+	}
+
+	accounted := s.accountedLocked()
+	s.population = max(n, accounted)
+	s.currentSusceptible = s.population - accounted
+}
+
+// accountedLocked counts everyone who is not susceptible: exposed, infected,
+// recovered, immune, vaccinated, quarantined, or dead.
+func (s *Simulation) accountedLocked() int {
+	return s.currentExposed + s.currentInfected + s.currentRecovered + s.currentImmune + s.currentVaccinated +
+		s.totalDeaths + s.quarantinedLocked()
+}
+
+// checkPopulationLocked reports ErrPopulationTooSmall if shrinking to n would
+// take more than the susceptible pool.
+func (s *Simulation) checkPopulationLocked(n int) error {
+	if accounted := s.accountedLocked(); n > 0 && n < accounted {
+		return fmt.Errorf("%w: %d people are not susceptible, population %d is too small",
+			ErrPopulationTooSmall, accounted, n)
+	}
+	return nil
+}
+
+// susceptibleFractionLocked is the chance that a contact reaches someone who
+// can still be infected, counting vaccinated people at the rate the vaccine
+// lets through.
+func (s *Simulation) susceptibleFractionLocked() float64 {
+	if s.population <= 0 {
+		return 1
+	}
+	return (float64(s.currentSusceptible) + s.breakthroughWeightLocked()) / float64(s.population)
+}
+
+// infectSusceptiblesLocked removes up to count new infections from the
+// susceptible and vaccinated pools, split in proportion to their exposure,
+// and returns how many actually happened.
+func (s *Simulation) infectSusceptiblesLocked(count int) int {
+	if s.population <= 0 {
+		return count
+	}
+
+	fromVaccinated := 0
+	if breakthrough := s.breakthroughWeightLocked(); breakthrough > 0 {
+		share := breakthrough / (float64(s.currentSusceptible) + breakthrough)
+		fromVaccinated = min(s.binomialLocked(count, share), s.currentVaccinated)
+	}
+	fromSusceptible := min(count-fromVaccinated, s.currentSusceptible)
+	s.currentSusceptible -= fromSusceptible
+	s.currentVaccinated -= fromVaccinated
+	s.countReinfectionsLocked(fromSusceptible)
+	return fromSusceptible + fromVaccinated
+}
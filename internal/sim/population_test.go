@@ -0,0 +1,162 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+)
+
+// The default moved from unbounded to 1000; this pins it so that changing it
+// again is a deliberate decision.
+func TestNewStartsWithDefaultPopulation(t *testing.T) {
+	state := New(0.25).Snapshot()
+	if state.Population != 1000 || state.CurrentSusceptible != 990 {
+		t.Fatalf("expected 990 of 1000 susceptible, got %d of %d", state.CurrentSusceptible, state.Population)
+	}
+
+	s := New(0.25)
+	s.SetPopulation(0)
+	if state := s.Snapshot(); state.Population != 0 || state.CurrentSusceptible != 0 {
+		t.Fatalf("expected SetPopulation(0) to restore the unbounded pool, got %d of %d",
+			state.CurrentSusceptible, state.Population)
+	}
+}
+
+func TestSusceptiblePoolLimitsTheEpidemic(t *testing.T) {
+	s := New(0.9)
+	s.SetSeed(12)
+	s.SetHospitalCapacity(0)
+	s.baseDeathRate = 0.01
+	s.SetRecoveryRate(0.1)
+	s.SetPopulation(300)
+
+	var state Snapshot
+	for i := 0; i < 300; i++ {
+		state = s.Step()
+		accounted := state.CurrentSusceptible + state.CurrentInfected + state.CurrentRecovered + state.TotalDeaths
+		if state.CurrentSusceptible < 0 || accounted != 300 {
+			t.Fatalf("tick %d: expected compartments to add up to 300, got %+v", state.Tick, state)
+		}
+	}
+	if state.TotalInfections > 290 {
+		t.Fatalf("expected at most the 290 initial susceptibles to be infected, got %d", state.TotalInfections)
+	}
+
+	// Once the pool is drained the curve is flat.
+	before := state.TotalInfections
+	for i := 0; i < 20; i++ {
+		state = s.Step()
+	}
+	if state.TotalInfections-before > 2 {
+		t.Fatalf("expected almost no new infections late in the epidemic, got %d", state.TotalInfections-before)
+	}
+}
+
+func TestSetPopulationCoversEveryoneAccountedFor(t *testing.T) {
+	s := New(0.25)
+	s.currentRecovered = 5
+
+	s.SetPopulation(3)
+	if got := s.Snapshot(); got.Population != 15 || got.CurrentSusceptible != 0 {
+		t.Fatalf("expected population raised to 15 with none susceptible, got %d with %d susceptible",
+			got.Population, got.CurrentSusceptible)
+	}
+
+	s.SetPopulation(0)
+	if got := s.Population(); got != 0 {
+		t.Fatalf("expected an unbounded population, got %d", got)
+	}
+}
+
+func TestControlPopulationResizesTheSusceptiblePool(t *testing.T) {
+	s := NewWithSeed(0.5, 3)
+	s.SetRecoveryRate(0.1)
+	for i := 0; i < 10; i++ {
+		s.Step()
+	}
+	conserved := func(state Snapshot) {
+		t.Helper()
+		total := state.CurrentSusceptible + state.CurrentExposed + state.CurrentInfected + state.CurrentRecovered +
+			state.CurrentImmune + state.CurrentVaccinated + state.Quarantined + state.TotalDeaths
+		if total != state.Population {
+			t.Fatalf("expected compartments to add up to %d, got %d", state.Population, total)
+		}
+	}
+	resize := func(n int) (Snapshot, error) {
+		current := s.Snapshot()
+		state, _, err := s.ApplyControlSettings(ControlSettings{
+			TransmissionModifier:        current.TransmissionModifier,
+			HospitalCapacity:            current.HospitalCapacity,
+			DeathRateOverloadMultiplier: current.DeathRateOverloadMultiplier,
+			Population:                  &n,
+		})
+		return state, err
+	}
+
+	before := s.Snapshot()
+	grown, err := resize(before.Population + 500)
+	if err != nil {
+		t.Fatalf("grow: %v", err)
+	}
+	if grown.CurrentSusceptible != before.CurrentSusceptible+500 || grown.CurrentInfected != before.CurrentInfected {
+		t.Fatalf("expected 500 more susceptibles and nothing else changed, got %+v from %+v", grown, before)
+	}
+	conserved(grown)
+
+	shrunk, err := resize(grown.Population - 200)
+	if err != nil {
+		t.Fatalf("shrink: %v", err)
+	}
+	if shrunk.CurrentSusceptible != grown.CurrentSusceptible-200 || shrunk.CurrentRecovered != grown.CurrentRecovered {
+		t.Fatalf("expected 200 fewer susceptibles and nothing else changed, got %+v from %+v", shrunk, grown)
+	}
+	conserved(shrunk)
+
+	rejected, err := resize(shrunk.Population - shrunk.CurrentSusceptible - 1)
+	if !errors.Is(err, ErrPopulationTooSmall) {
+		t.Fatalf("expected ErrPopulationTooSmall, got %v", err)
+	}
+	if rejected.Population != shrunk.Population || rejected.StateVersion != shrunk.StateVersion {
+		t.Fatalf("expected a rejected resize to leave the state alone, got %+v", rejected)
+	}
+}
+
+func TestInitialImmunityBluntsThePeak(t *testing.T) {
+	peak := func(fraction float64) (int, Snapshot) {
+		s := NewWithSeed(0.3, 4)
+		s.SetRecoveryRate(0.1)
+		s.SetInitialImmuneFraction(fraction)
+		start := s.Snapshot()
+		highest := 0
+		for i := 0; i < 200; i++ {
+			highest = max(highest, s.Step().CurrentInfected)
+		}
+		return highest, start
+	}
+
+	naivePeak, naive := peak(0)
+	immunePeak, immune := peak(0.5)
+	if naive.InitialImmune != 0 || immune.InitialImmune != 500 || immune.CurrentImmune != 500 {
+		t.Fatalf("expected 0 and 500 people to start immune, got %d and %d", naive.InitialImmune, immune.InitialImmune)
+	}
+	if immune.CurrentSusceptible != 490 {
+		t.Fatalf("expected 490 susceptible beside 500 immune and 10 infected, got %d", immune.CurrentSusceptible)
+	}
+	if immunePeak*2 > naivePeak {
+		t.Fatalf("expected 50%% initial immunity to at least halve the peak: %d without, %d with", naivePeak, immunePeak)
+	}
+}
+
+func TestInitialImmuneFractionAppliesOnReset(t *testing.T) {
+	s := New(0.25)
+	s.Step()
+	s.SetInitialImmuneFraction(2)
+	if got := s.Snapshot(); got.CurrentImmune != 0 || got.InitialImmune != 990 {
+		t.Fatalf("expected a running epidemic to keep its immune count until reset, got %+v", got)
+	}
+
+	s.Reset()
+	if got := s.Snapshot(); got.CurrentImmune != 990 || got.CurrentSusceptible != 0 {
+		t.Fatalf("expected everyone but the initial infected to restart immune, got %d immune and %d susceptible",
+			got.CurrentImmune, got.CurrentSusceptible)
+	}
+}
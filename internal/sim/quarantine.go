@@ -0,0 +1,72 @@
+package sim
+
+import "math"
+
+// SetDetectionRate sets the fraction of undetected infectious people found
+// each tick. Detected people are quarantined until they recover or die, and
+// quarantine cuts their onward transmission by the quarantine effectiveness.
+// Unlike a lockdown, which slows everyone, this targets only the infectious.
+// Values are clamped to [0, 1]; the default of 0 disables detection.
+// Detection applies to the single pool, not to regions or spatial mode.
+func (s *Simulation) SetDetectionRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.detectionRate = math.Min(math.Max(rate, 0), 1)
+}
+
+// DetectionRate returns the fraction of undetected infectious people found
+// each tick.
+func (s *Simulation) DetectionRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.detectionRate
+}
+
+// SetQuarantineEffectiveness sets how much of their onward transmission
+// quarantined people lose: 1, the default, stops it entirely and 0 leaves it
+// untouched. Values are clamped to [0, 1].
+func (s *Simulation) SetQuarantineEffectiveness(e float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quarantineEffectiveness = math.Min(math.Max(e, 0), 1)
+}
+
+// QuarantineEffectiveness returns the share of onward transmission that
+// quarantine prevents.
+func (s *Simulation) QuarantineEffectiveness() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.quarantineEffectiveness
+}
+
+// detectInfectiousLocked quarantines this tick's newly detected infectious
+// people, drawn from those neither isolated nor already quarantined.
+func (s *Simulation) detectInfectiousLocked() {
+	if s.detectionRate == 0 {
+		return
+	}
+
+	undetected := max(s.currentInfected-s.isolated-s.currentQuarantined, 0)
+	s.currentQuarantined += s.binomialLocked(undetected, s.detectionRate)
+}
+
+// quarantineLeakLocked is how many quarantined people still transmit, in
+// whole-person equivalents.
+func (s *Simulation) quarantineLeakLocked() int {
+	return int(math.Round(float64(s.currentQuarantined) * (1 - s.quarantineEffectiveness)))
+}
+
+// settleQuarantineLocked shrinks the quarantined count in proportion when
+// infectious people leave the pool, since quarantined people recover and die
+// at the same rate as everyone else.
+func (s *Simulation) settleQuarantineLocked(infectedBefore int) {
+	if s.currentQuarantined == 0 || infectedBefore == 0 {
+		return
+	}
+	settled := int(math.Round(float64(s.currentQuarantined) * float64(s.currentInfected) / float64(infectedBefore)))
+	s.currentQuarantined = min(settled, s.currentInfected-s.isolated)
+}
@@ -0,0 +1,62 @@
+package sim
+
+import "testing"
+
+func TestQuarantineOfDetectedCasesCutsTransmission(t *testing.T) {
+	run := func(detection, effectiveness float64) (infections, quarantined int) {
+		for seed := int64(1); seed <= 5; seed++ {
+			s := NewWithSeed(0.3, seed)
+			s.SetRecoveryRate(0.1)
+			s.SetDetectionRate(detection)
+			s.SetQuarantineEffectiveness(effectiveness)
+			for i := 0; i < 40; i++ {
+				state := s.Step()
+				quarantined = max(quarantined, state.CurrentQuarantined)
+				if state.CurrentQuarantined+state.Isolated > state.CurrentInfected {
+					t.Fatalf("tick %d: %d quarantined and %d isolated out of %d infected",
+						state.Tick, state.CurrentQuarantined, state.Isolated, state.CurrentInfected)
+				}
+			}
+			infections += s.Snapshot().TotalInfections
+		}
+		return infections, quarantined
+	}
+
+	undetected, none := run(0, 1)
+	leaky, _ := run(0.5, 0)
+	detected, some := run(0.5, 1)
+	if none != 0 {
+		t.Fatalf("expected nobody quarantined with detection off, got %d", none)
+	}
+	if some == 0 {
+		t.Fatal("expected detected cases to be quarantined")
+	}
+	if leaky < detected*2 {
+		t.Fatalf("expected an ineffective quarantine to barely slow transmission, got %d vs %d", leaky, detected)
+	}
+	if detected*2 > undetected {
+		t.Fatalf("expected an effective quarantine to at least halve infections, got %d vs %d", detected, undetected)
+	}
+}
+
+func TestQuarantinedCasesStillResolve(t *testing.T) {
+	s := NewWithSeed(0.3, 4)
+	s.SetRecoveryRate(0.2)
+	s.SetContactsPerInfected(0, 1)
+	s.SetDetectionRate(1)
+	if state := s.Step(); state.CurrentQuarantined != state.CurrentInfected {
+		t.Fatalf("expected every case detected, got %d quarantined out of %d", state.CurrentQuarantined, state.CurrentInfected)
+	}
+	for i := 0; i < 60; i++ {
+		s.Step()
+	}
+
+	state := s.Snapshot()
+	if state.CurrentInfected != 0 || state.CurrentQuarantined != 0 {
+		t.Fatalf("expected quarantined cases to recover or die, got %d infected and %d quarantined",
+			state.CurrentInfected, state.CurrentQuarantined)
+	}
+	if state.TotalRecoveries == 0 {
+		t.Fatal("expected quarantined cases to recover")
+	}
+}
@@ -0,0 +1,256 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// gammaSample draws from a gamma distribution with the given shape and scale
+// using the Marsaglia-Tsang method.
+func gammaSample(rng *rand.Rand, shape, scale float64) float64 {
+	if shape <= 0 || scale <= 0 {
+		return 0
+	}
+	if shape < 1 {
+		// Boost small shapes: Gamma(k) = Gamma(k+1) * U^(1/k).
+		return gammaSample(rng, shape+1, scale) * math.Pow(rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3.0
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v * scale
+		}
+	}
+}
+
+// recordingSource passes draws through from rng while writing each one to w
+// as a little-endian uint64.
+type recordingSource struct {
+	rng *rand.Rand
+	w   *bufio.Writer
+	err error
+	buf [8]byte
+}
+
+func (r *recordingSource) Int63() int64 {
+	v := r.rng.Int63()
+	r.record(uint64(v))
+	return v
+}
+
+func (r *recordingSource) Uint64() uint64 {
+	v := r.rng.Uint64()
+	r.record(v)
+	return v
+}
+
+func (r *recordingSource) Seed(seed int64) {
+	r.rng.Seed(seed)
+}
+
+func (r *recordingSource) record(v uint64) {
+	if r.err != nil {
+		return
+	}
+	binary.LittleEndian.PutUint64(r.buf[:], v)
+	_, r.err = r.w.Write(r.buf[:])
+}
+
+// replaySource serves draws captured by a recordingSource. Once the
+// recording runs out it falls back to a fixed-seed generator.
+type replaySource struct {
+	draws    []uint64
+	next     int
+	fallback rand.Source64
+	onEmpty  func()
+}
+
+func (r *replaySource) Int63() int64 {
+	return int64(r.Uint64() & (1<<63 - 1))
+}
+
+func (r *replaySource) Uint64() uint64 {
+	if r.next < len(r.draws) {
+		v := r.draws[r.next]
+		r.next++
+		return v
+	}
+	if r.onEmpty != nil {
+		r.onEmpty()
+		r.onEmpty = nil
+	}
+	return r.fallback.Uint64()
+}
+
+func (r *replaySource) Seed(int64) {}
+
+// RecordRand writes every subsequent random draw to w so the run can be
+// reproduced with ReplayRand, independent of the Go runtime's generator. The
+// returned stop function ends the recording, flushes w, and reports the first
+// write error.
+func (s *Simulation) RecordRand(w io.Writer) (stop func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source := &recordingSource{rng: s.rng, w: bufio.NewWriter(w)}
+	recorder := rand.New(source)
+	s.rng = recorder
+
+	return func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.rng == recorder {
+			s.rng = source.rng
+		}
+		if source.err != nil {
+			return source.err
+		}
+		return source.w.Flush()
+	}
+}
+
+// ReplayRand loads a recording written by RecordRand and serves the
+// simulation's random draws from it. Starting from the same state as the
+// recorded run, subsequent ticks reproduce it exactly. Draws beyond the end
+// of the recording come from a fixed-seed generator and are logged once.
+func (s *Simulation) ReplayRand(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(data)%8 != 0 {
+		return fmt.Errorf("rand recording length %d is not a multiple of 8", len(data))
+	}
+
+	draws := make([]uint64, len(data)/8)
+	for i := range draws {
+		draws[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logger := s.logger
+	s.draws = nil
+	s.rng = rand.New(&replaySource{
+		draws:    draws,
+		fallback: rand.NewSource(0).(rand.Source64),
+		onEmpty: func() {
+			logger.Printf("rand replay exhausted after %d draws; continuing with a fixed seed", len(draws))
+		},
+	})
+	return nil
+}
+
+// countingSource wraps a seeded source and counts the draws taken from it so
+// a run's random state can be reported as a seed plus a position.
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func (c *countingSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.draws++
+	return c.src.Uint64()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.draws = 0
+}
+
+// RandState describes where the simulation's random stream currently stands.
+type RandState struct {
+	Seed int64 `json:"seed"`
+	// Draws counts the values taken from the seeded stream so far.
+	Draws uint64 `json:"draws"`
+	// Tracked is false while draws come from a ReplayRand recording, in
+	// which case Seed and Draws no longer describe the stream.
+	Tracked bool `json:"tracked"`
+}
+
+// SetSeed restarts the random stream from seed, making later ticks
+// reproducible from the same starting state.
+func (s *Simulation) SetSeed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seedLocked(seed)
+	s.seedPhrase = ""
+}
+
+// SetSeedPhrase seeds the random stream from a human-friendly phrase, hashed
+// with 64-bit FNV-1a, so a class can share "measles-monday" instead of a raw
+// number. The same phrase always yields the same run from the same starting
+// state.
+func (s *Simulation) SetSeedPhrase(phrase string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seedLocked(seedFromPhrase(phrase))
+	s.seedPhrase = phrase
+}
+
+func seedFromPhrase(phrase string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(phrase))
+	return int64(h.Sum64())
+}
+
+func (s *Simulation) seedLocked(seed int64) {
+	s.seed = seed
+	s.draws = &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	s.rng = rand.New(s.draws)
+}
+
+// RandState reports the current seed and how many draws have been made
+// since it was set.
+func (s *Simulation) RandState() RandState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := RandState{Seed: s.seed, Tracked: s.draws != nil}
+	if s.draws != nil {
+		state.Draws = s.draws.draws
+	}
+	return state
+}
+
+// poissonSample draws from a Poisson distribution with mean lambda. Small
+// means use Knuth's multiplication method; large means fall back to a rounded
+// normal approximation, which is accurate well beyond the needs of the model.
+func poissonSample(rng *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		return max(int(math.Round(lambda+math.Sqrt(lambda)*rng.NormFloat64())), 0)
+	}
+
+	limit := math.Exp(-lambda)
+	count := 0
+	for product := rng.Float64(); product > limit; product *= rng.Float64() {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,142 @@
+package sim
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestReplayRandReproducesRecordedRun(t *testing.T) {
+	newRun := func() *Simulation {
+		s := New(0.3)
+		s.SetInteractionVariance(0.8)
+		s.SetOutcomeModel(OutcomeScheduled, 5)
+		return s
+	}
+
+	original := newRun()
+	original.rng = rand.New(rand.NewSource(11))
+	// Outcomes for the starting infections were drawn before recording
+	// began, so replay from a state that already has them.
+	replayed := newRun()
+	replayed.outcomes = append([]scheduledOutcome(nil), original.outcomes...)
+
+	var recording bytes.Buffer
+	stop := original.RecordRand(&recording)
+	var want []Snapshot
+	for i := 0; i < 20; i++ {
+		want = append(want, original.Step())
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("stop recording: %v", err)
+	}
+	if recording.Len() == 0 {
+		t.Fatal("expected draws to be recorded")
+	}
+
+	if err := replayed.ReplayRand(&recording); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	for i := range want {
+		if got := replayed.Step(); !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("tick %d diverged:\n got %+v\nwant %+v", i+1, got, want[i])
+		}
+	}
+}
+
+func TestReplayRandRejectsTruncatedRecording(t *testing.T) {
+	s := New(0.3)
+	if err := s.ReplayRand(bytes.NewReader([]byte{1, 2, 3})); err == nil {
+		t.Fatal("expected an error for a recording that is not whole draws")
+	}
+}
+
+func TestRandStateReportsSeedAndDraws(t *testing.T) {
+	s := New(0.3)
+	s.SetSeed(42)
+
+	if got := s.RandState(); got != (RandState{Seed: 42, Tracked: true}) {
+		t.Fatalf("expected fresh seed 42 with no draws, got %+v", got)
+	}
+
+	first := s.Step()
+	state := s.RandState()
+	if state.Seed != 42 || state.Draws == 0 {
+		t.Fatalf("expected draws to be counted against seed 42, got %+v", state)
+	}
+
+	s.SetSeed(42)
+	if got := s.RandState().Draws; got != 0 {
+		t.Fatalf("expected reseeding to reset the draw count, got %d", got)
+	}
+
+	if err := s.ReplayRand(bytes.NewReader(nil)); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if s.RandState().Tracked {
+		t.Fatal("expected replayed draws to be reported as untracked")
+	}
+
+	again := New(0.3)
+	again.SetSeed(42)
+	if second := again.Step(); second.CurrentInfected != first.CurrentInfected {
+		t.Fatalf("expected the same seed to reproduce the tick, got %d and %d infected", first.CurrentInfected, second.CurrentInfected)
+	}
+}
+
+func TestSeedPhraseReproducesTrajectory(t *testing.T) {
+	run := func(phrase string) []Snapshot {
+		s := New(0.3)
+		s.SetInteractionVariance(0.5)
+		s.SetSeedPhrase(phrase)
+		states := make([]Snapshot, 20)
+		for i := range states {
+			states[i] = s.Step()
+		}
+		return states
+	}
+
+	first, second := run("measles-monday"), run("measles-monday")
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("expected the same phrase to reproduce the run")
+	}
+	if first[0].SeedPhrase != "measles-monday" {
+		t.Fatalf("expected the phrase in the snapshot, got %q", first[0].SeedPhrase)
+	}
+	if reflect.DeepEqual(first, run("flu-friday")) {
+		t.Fatal("expected a different phrase to produce a different run")
+	}
+}
+
+func TestNewWithSeedProducesIdenticalRuns(t *testing.T) {
+	run := func(seed int64) ([]Snapshot, []Agent) {
+		s := NewWithSeed(0.3, seed)
+		s.SetRecoveryRate(0.05)
+		for i := 0; i < 50; i++ {
+			s.AddAgent(Agent{X: float64(i)})
+		}
+		s.VaccinateRegion(25, 0, 20, 0.5)
+
+		states := make([]Snapshot, 30)
+		for i := range states {
+			if i == 10 {
+				s.UpdateTransmissionModifier(0.5)
+			}
+			states[i] = s.Step()
+		}
+		return states, s.Agents()
+	}
+
+	firstStates, firstAgents := run(42)
+	secondStates, secondAgents := run(42)
+	if !reflect.DeepEqual(firstStates, secondStates) {
+		t.Fatal("expected the same seed and inputs to produce identical snapshots")
+	}
+	if !reflect.DeepEqual(firstAgents, secondAgents) {
+		t.Fatal("expected the same seed to vaccinate the same agents")
+	}
+	if otherStates, _ := run(43); reflect.DeepEqual(firstStates, otherStates) {
+		t.Fatal("expected a different seed to produce a different run")
+	}
+}
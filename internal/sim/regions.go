@@ -0,0 +1,231 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrUnknownRegion is returned when a region name has not been added.
+var ErrUnknownRegion = errors.New("unknown region")
+
+// region is one geographic pool with its own people and hospital.
+type region struct {
+	name             string
+	population       int
+	susceptible      int
+	infected         int
+	recovered        int
+	immune           int
+	deaths           int
+	hospitalCapacity int
+	lockdown         bool
+	// travelled counts this tick's outbound travellers by destination.
+	travelled map[string]int
+}
+
+// RegionSnapshot captures one region's state. HospitalCapacity of 0 means
+// the region's hospitals never overload. Travel counts the infected people
+// who left for each destination region during the last tick.
+type RegionSnapshot struct {
+	Name               string         `json:"name"`
+	Population         int            `json:"population"`
+	CurrentSusceptible int            `json:"current_susceptible"`
+	CurrentInfected    int            `json:"current_infected"`
+	CurrentRecovered   int            `json:"current_recovered"`
+	CurrentImmune      int            `json:"current_immune"`
+	TotalDeaths        int            `json:"total_deaths"`
+	HospitalCapacity   int            `json:"hospital_capacity"`
+	Overloaded         bool           `json:"overloaded"`
+	Lockdown           bool           `json:"lockdown"`
+	Travel             map[string]int `json:"travel,omitempty"`
+}
+
+// AddRegion splits the world into separately stepped regions. Each region
+// runs its own transmission and outcomes against its own susceptible pool
+// and hospital, so an outbreak in one region does not by itself reach
+// another. The first region takes over everyone already in the simulation,
+// with its population raised to cover them; later regions start fully
+// susceptible. Use SeedRegion to start an outbreak in them.
+//
+// Once regions exist, the top-level Snapshot counts are totals across all
+// regions and imported infections land in the first region. Regions use the
+// memoryless outcome model without incubation, contact tracing, a transition
+// matrix, or dispersion. Reset and ApplyConfig return to a single pool and
+// drop any travel matrix.
+func (s *Simulation) AddRegion(name string, population int) error {
+	if name == "" {
+		return errors.New("region name must not be empty")
+	}
+	if population <= 0 {
+		return fmt.Errorf("region %q population must be positive, got %d", name, population)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.regionLocked(name) != nil {
+		return fmt.Errorf("region %q already exists", name)
+	}
+
+	r := region{name: name, population: population, susceptible: population}
+	if len(s.regions) == 0 {
+		// People held back by incubation or quarantine are still infected,
+		// so they join the region's infectious pool.
+		r.infected = s.currentInfected + s.currentExposed + s.quarantinedLocked()
+		r.recovered = s.currentRecovered
+		r.immune = s.currentImmune + s.currentVaccinated
+		r.deaths = s.totalDeaths
+		r.hospitalCapacity = s.hospitalCapacity
+		accounted := r.infected + r.recovered + r.immune + r.deaths
+		r.population = max(population, accounted)
+		r.susceptible = r.population - accounted
+		s.currentExposed = 0
+		s.currentVaccinated = 0
+		s.clearWaningLocked()
+		s.quarantine = nil
+		s.isolated = 0
+		s.currentQuarantined = 0
+		s.outcomes = nil
+	}
+	s.regions = append(s.regions, r)
+	s.syncRegionsLocked()
+	return nil
+}
+
+// SetRegionHospitalCapacity sets a region's hospital capacity. Negative
+// values are clamped to 0, which means the region never overloads.
+func (s *Simulation) SetRegionHospitalCapacity(name string, capacity int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.regionLocked(name)
+	if r == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownRegion, name)
+	}
+	r.hospitalCapacity = sanitizeCapacity(capacity)
+	return nil
+}
+
+// SeedRegion infects up to count susceptible people in the named region and
+// returns how many were infected. They count towards TotalInfections.
+func (s *Simulation) SeedRegion(name string, count int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.regionLocked(name)
+	if r == nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownRegion, name)
+	}
+	count = min(max(count, 0), r.susceptible)
+	r.susceptible -= count
+	r.infected += count
+	s.totalInfections += count
+	s.syncRegionsLocked()
+	return count, nil
+}
+
+// Regions returns the current state of every region in the order they were
+// added, or nil when the world is a single pool.
+func (s *Simulation) Regions() []RegionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.regionSnapshotsLocked()
+}
+
+func (s *Simulation) regionLocked(name string) *region {
+	for i := range s.regions {
+		if s.regions[i].name == name {
+			return &s.regions[i]
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) regionSnapshotsLocked() []RegionSnapshot {
+	if len(s.regions) == 0 {
+		return nil
+	}
+
+	snapshots := make([]RegionSnapshot, len(s.regions))
+	for i, r := range s.regions {
+		_, overloaded := s.deathProbabilityForLocked(r.infected, r.hospitalCapacity)
+		snapshots[i] = RegionSnapshot{
+			Name:               r.name,
+			Population:         r.population,
+			CurrentSusceptible: r.susceptible,
+			CurrentInfected:    r.infected,
+			CurrentRecovered:   r.recovered,
+			CurrentImmune:      r.immune,
+			TotalDeaths:        r.deaths,
+			HospitalCapacity:   r.hospitalCapacity,
+			Overloaded:         overloaded,
+			Lockdown:           r.lockdown,
+			Travel:             maps.Clone(r.travelled),
+		}
+	}
+	return snapshots
+}
+
+// stepRegionsLocked advances every region by one tick. imported infections
+// have already been added to the top-level counts and are placed in the
+// first region.
+func (s *Simulation) stepRegionsLocked(imported int) {
+	s.regions[0].infected += imported
+	s.regions[0].population += imported
+	s.travelLocked()
+
+	probability := s.advanceSmoothedProbabilityLocked()
+	infectedBefore, deathsBefore := s.currentInfected, s.totalDeaths
+	newInfections, contacts := 0, 0
+	for i := range s.regions {
+		r := &s.regions[i]
+		// The base contact rate keeps the single pool going; a region with
+		// nobody infectious must not start an outbreak of its own.
+		if r.infected > 0 {
+			interactions := s.varyContactsLocked(s.contactsLocked(r.infected))
+			contacts += interactions
+			susceptibleFraction := float64(r.susceptible) / float64(r.population)
+			infections := min(s.binomialLocked(interactions, probability*susceptibleFraction), r.susceptible)
+			r.susceptible -= infections
+			r.infected += infections
+			newInfections += infections
+		}
+
+		deathProbability, _ := s.deathProbabilityForLocked(r.infected, r.hospitalCapacity)
+		deaths, recoveries := s.resolveOutcomesLocked(r.infected, deathProbability,
+			s.recoveryProbabilityForLocked(r.infected, r.hospitalCapacity))
+		r.infected -= deaths + recoveries
+		r.recovered += recoveries
+		r.deaths += deaths
+		s.totalDeaths += deaths
+		s.totalRecoveries += recoveries
+	}
+	s.contacts = contacts
+
+	s.updateEffectiveRLocked(infectedBefore, newInfections)
+	s.totalInfections += newInfections + imported
+	s.syncRegionsLocked()
+	s.accrueBurdenLocked(s.totalDeaths - deathsBefore)
+}
+
+// clearRegionsLocked returns the world to a single pool.
+func (s *Simulation) clearRegionsLocked() {
+	s.regions = nil
+	s.travelMatrix = nil
+}
+
+// syncRegionsLocked sets the top-level compartments to the totals across
+// regions.
+func (s *Simulation) syncRegionsLocked() {
+	s.population, s.currentSusceptible, s.currentInfected = 0, 0, 0
+	s.currentRecovered, s.currentImmune = 0, 0
+	for _, r := range s.regions {
+		s.population += r.population
+		s.currentSusceptible += r.susceptible
+		s.currentInfected += r.infected
+		s.currentRecovered += r.recovered
+		s.currentImmune += r.immune
+	}
+}
@@ -0,0 +1,88 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddRegionKeepsTopLevelTotals(t *testing.T) {
+	s := NewWithSeed(0.3, 5)
+	before := s.Snapshot()
+
+	if err := s.AddRegion("north", 1000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.AddRegion("south", 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state := s.Snapshot()
+	if state.CurrentInfected != before.CurrentInfected || state.Population != 1500 {
+		t.Fatalf("expected %d infected in a population of 1500, got %d in %d",
+			before.CurrentInfected, state.CurrentInfected, state.Population)
+	}
+	if len(state.Regions) != 2 || state.Regions[0].CurrentInfected != before.CurrentInfected ||
+		state.Regions[1].CurrentInfected != 0 || state.Regions[1].CurrentSusceptible != 500 {
+		t.Fatalf("expected the existing infections in the first region only, got %+v", state.Regions)
+	}
+
+	for i := 0; i < 30; i++ {
+		state = s.Step()
+		infected, susceptible, population := 0, 0, 0
+		for _, r := range state.Regions {
+			infected += r.CurrentInfected
+			susceptible += r.CurrentSusceptible
+			population += r.Population
+		}
+		if infected != state.CurrentInfected || susceptible != state.CurrentSusceptible || population != state.Population {
+			t.Fatalf("tick %d: top-level counts %+v do not match the regions %+v", state.Tick, state, state.Regions)
+		}
+	}
+	if south := state.Regions[1]; south.CurrentInfected != 0 || south.CurrentSusceptible != 500 {
+		t.Fatalf("expected the unseeded region to stay untouched, got %+v", south)
+	}
+}
+
+func TestRegionsOverloadIndependently(t *testing.T) {
+	s := NewWithSeed(0.3, 5)
+	for _, name := range []string{"east", "west"} {
+		if err := s.AddRegion(name, 1000); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.SetRegionHospitalCapacity(name, 20); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got, err := s.SeedRegion("east", 50); err != nil || got != 50 {
+		t.Fatalf("expected 50 seeded infections, got %d (%v)", got, err)
+	}
+
+	regions := s.Regions()
+	if !regions[0].Overloaded || regions[1].Overloaded {
+		t.Fatalf("expected only the seeded region to be overloaded, got %+v", regions)
+	}
+}
+
+func TestAddRegionRejectsBadInput(t *testing.T) {
+	s := NewWithSeed(0.3, 5)
+	if err := s.AddRegion("", 100); err == nil {
+		t.Fatal("expected an empty name to be rejected")
+	}
+	if err := s.AddRegion("north", 0); err == nil {
+		t.Fatal("expected a zero population to be rejected")
+	}
+	if err := s.AddRegion("north", 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.AddRegion("north", 100); err == nil {
+		t.Fatal("expected a duplicate name to be rejected")
+	}
+	if _, err := s.SeedRegion("nowhere", 1); !errors.Is(err, ErrUnknownRegion) {
+		t.Fatalf("expected ErrUnknownRegion, got %v", err)
+	}
+
+	s.Reset()
+	if s.Regions() != nil {
+		t.Fatal("expected reset to return to a single pool")
+	}
+}
@@ -0,0 +1,78 @@
+package sim
+
+// startingPoint holds the compartments a run starts from, so Reset can return
+// to them.
+type startingPoint struct {
+	infected   int
+	exposed    int
+	recovered  int
+	immune     int
+	population int
+}
+
+func (s *Simulation) markStartLocked() {
+	s.start = startingPoint{
+		infected:   s.currentInfected,
+		exposed:    s.currentExposed,
+		recovered:  s.currentRecovered,
+		immune:     s.currentImmune,
+		population: s.population,
+	}
+}
+
+// Reset restarts the run in place: the tick, compartments, and running totals
+// return to their starting values, the random stream is reseeded from its
+// last seed, and the history and event log are cleared. The starting values
+// are those from construction or the last ApplyConfig. Disease, hospital,
+// and intervention settings, pending imports, and the paused state are kept;
+// regions are removed. Snapshots from the restarted run carry the next
+// Generation. It is safe to call while Run is active; the next tick proceeds
+// from tick 1.
+func (s *Simulation) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.restartLocked()
+}
+
+// restartLocked returns the run to tick 0 from the starting point, as Reset
+// describes.
+func (s *Simulation) restartLocked() {
+	s.tick = 0
+	s.simulatedDays = 0
+	s.generation++
+	s.quarantine = nil
+	s.isolated = 0
+	s.currentQuarantined = 0
+	s.traced = 0
+	s.imported = 0
+	s.contacts = 0
+	s.clearRegionsLocked()
+	s.agents = nil
+	s.currentInfected = s.start.infected
+	s.currentExposed = s.start.exposed
+	s.currentRecovered = s.start.recovered
+	s.currentImmune = s.start.immune
+	s.currentVaccinated = 0
+	s.clearWaningLocked()
+	s.totalDeaths = 0
+	s.totalInfections = 0
+	s.totalRecoveries = 0
+	s.setPopulationLocked(s.start.population)
+	s.resetVariantsLocked()
+	s.resetAgesLocked()
+
+	s.effectiveR = 0
+	s.ticksBelowOne = 0
+	s.burden = 0
+	s.offspring = offspringSummary{}
+	s.smoothedProbability = s.infectionProbabilityLocked()
+	s.history.clear()
+	s.events = nil
+
+	s.seedLocked(s.seed)
+	s.outcomes = nil
+	if s.outcomeModel == OutcomeScheduled {
+		s.scheduleInitialOutcomesLocked(s.currentInfected)
+	}
+}
@@ -0,0 +1,72 @@
+package sim
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResetReturnsToTheInitialState(t *testing.T) {
+	s := NewWithSeed(0.4, 17)
+	s.SetHospitalCapacity(30)
+	s.SetRecoveryRate(0.1)
+	s.SetIncubationPeriod(2)
+	initial := s.Snapshot()
+
+	run := make([]Snapshot, 15)
+	for i := range run {
+		run[i] = s.Step()
+	}
+
+	s.Reset()
+	// Everything but the generation matches the freshly built simulation.
+	initial.Generation = 1
+	if got := s.Snapshot(); !reflect.DeepEqual(got, initial) {
+		t.Fatalf("expected the initial state after reset:\nwant %+v\ngot  %+v", initial, got)
+	}
+	if len(s.History()) != 0 {
+		t.Fatal("expected reset to clear the history")
+	}
+	if got := s.HospitalCapacity(); got != 30 {
+		t.Fatalf("expected hospital capacity 30 to survive reset, got %d", got)
+	}
+
+	rerun := make([]Snapshot, 15)
+	for i := range rerun {
+		rerun[i] = s.Step()
+		rerun[i].Generation = 0
+	}
+	if !reflect.DeepEqual(run, rerun) {
+		t.Fatal("expected the run after reset to replay the original run")
+	}
+}
+
+func TestResetIsSafeDuringRun(t *testing.T) {
+	s := New(0.3)
+	s.SetLogger(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Run(ctx, time.Millisecond, nil)
+	}()
+
+	for i := 0; i < 20; i++ {
+		s.Reset()
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	s.Reset()
+	if state := s.Snapshot(); state.Tick != 0 || state.CurrentInfected != 10 {
+		t.Fatalf("expected tick 0 with 10 infected after reset, got tick %d with %d infected", state.Tick, state.CurrentInfected)
+	}
+	if next := s.Step(); next.Tick != 1 {
+		t.Fatalf("expected the next tick to be 1, got %d", next.Tick)
+	}
+}
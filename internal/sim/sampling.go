@@ -0,0 +1,79 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+)
+
+// exactSamplingLimit is the largest number of trials SamplingFast still
+// resolves one by one.
+const exactSamplingLimit = 100
+
+// SamplingMode selects how a batch of independent chances, such as a tick's
+// contacts each transmitting with the infection probability, becomes a count.
+type SamplingMode int
+
+const (
+	// SamplingExact draws every trial separately. It costs one random draw
+	// per contact, which dominates a tick once contact counts run large.
+	SamplingExact SamplingMode = iota
+	// SamplingFast draws batches of up to 100 trials exactly and larger ones
+	// in constant time from a Poisson or normal approximation with the same
+	// mean and variance.
+	SamplingFast
+)
+
+// SetSamplingMode selects how binomial counts are drawn. Both modes give the
+// same expected counts, but they consume the random stream differently, so a
+// seeded run only replays identically in the mode it was recorded in.
+func (s *Simulation) SetSamplingMode(mode SamplingMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.samplingMode = mode
+}
+
+// SamplingMode reports the active sampling mode.
+func (s *Simulation) SamplingMode() SamplingMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.samplingMode
+}
+
+// binomialLocked draws how many of n trials succeed with probability p.
+func (s *Simulation) binomialLocked(n int, p float64) int {
+	if s.samplingMode == SamplingFast && n > exactSamplingLimit {
+		return binomialSample(s.rng, n, p)
+	}
+
+	count := 0
+	for i := 0; i < n; i++ {
+		if s.rng.Float64() < p {
+			count++
+		}
+	}
+	return count
+}
+
+// binomialSample approximates a binomial draw in constant time. Counts with
+// a small variance come from a Poisson draw on the rarer outcome; the rest
+// from a rounded normal, clamped to [0, n].
+func binomialSample(rng *rand.Rand, n int, p float64) int {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return n
+	}
+
+	mean := float64(n) * p
+	variance := mean * (1 - p)
+	if variance < 10 {
+		if p <= 0.5 {
+			return min(poissonSample(rng, mean), n)
+		}
+		return n - min(poissonSample(rng, float64(n)*(1-p)), n)
+	}
+	return min(max(int(math.Round(mean+math.Sqrt(variance)*rng.NormFloat64())), 0), n)
+}
@@ -0,0 +1,69 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFastSamplingMatchesExactDistribution(t *testing.T) {
+	const draws = 4000
+	moments := func(mode SamplingMode, n int, p float64) (mean, variance float64) {
+		s := NewWithSeed(0.2, 11)
+		s.SetSamplingMode(mode)
+		sum, sumSquares := 0.0, 0.0
+		for i := 0; i < draws; i++ {
+			x := float64(s.binomialLocked(n, p))
+			sum += x
+			sumSquares += x * x
+		}
+		mean = sum / draws
+		return mean, sumSquares/draws - mean*mean
+	}
+
+	for _, c := range []struct {
+		n int
+		p float64
+	}{{1000, 0.004}, {1000, 0.05}, {500, 0.4}, {2000, 0.997}} {
+		exactMean, exactVariance := moments(SamplingExact, c.n, c.p)
+		fastMean, fastVariance := moments(SamplingFast, c.n, c.p)
+		want := float64(c.n) * c.p
+		wantVariance := want * (1 - c.p)
+		// Five standard errors of the mean, and 15% on the variance.
+		tolerance := 5 * math.Sqrt(wantVariance/draws)
+		if math.Abs(exactMean-want) > tolerance || math.Abs(fastMean-want) > tolerance {
+			t.Fatalf("n=%d p=%v: expected means near %v, got exact %v and fast %v", c.n, c.p, want, exactMean, fastMean)
+		}
+		if math.Abs(exactVariance-wantVariance) > 0.15*wantVariance || math.Abs(fastVariance-wantVariance) > 0.15*wantVariance {
+			t.Fatalf("n=%d p=%v: expected variances near %v, got exact %v and fast %v",
+				c.n, c.p, wantVariance, exactVariance, fastVariance)
+		}
+	}
+}
+
+func TestFastSamplingKeepsSmallBatchesExact(t *testing.T) {
+	exact, fast := NewWithSeed(0.2, 3), NewWithSeed(0.2, 3)
+	fast.SetSamplingMode(SamplingFast)
+	for i := 0; i < 50; i++ {
+		if a, b := exact.binomialLocked(exactSamplingLimit, 0.3), fast.binomialLocked(exactSamplingLimit, 0.3); a != b {
+			t.Fatalf("draw %d: expected identical counts at the threshold, got %d and %d", i, a, b)
+		}
+	}
+}
+
+func benchmarkSampling(b *testing.B, mode SamplingMode) {
+	s := newBenchmarkSimulation()
+	s.SetSamplingMode(mode)
+	s.SetPopulation(0)
+	s.SetContactsPerInfected(0, 10)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.Step()
+		s.currentInfected = 10000
+	}
+}
+
+func BenchmarkStepExactSampling(b *testing.B) { benchmarkSampling(b, SamplingExact) }
+
+func BenchmarkStepFastSampling(b *testing.B) { benchmarkSampling(b, SamplingFast) }
@@ -0,0 +1,651 @@
+package sim
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// saveFormatVersion is the first byte of every checkpoint written by Save.
+// Bump it whenever savedState changes incompatibly.
+const saveFormatVersion byte = 1
+
+// ErrUnsupportedCheckpoint is returned by Load for a checkpoint written in a
+// format version it does not understand.
+var ErrUnsupportedCheckpoint = errors.New("unsupported checkpoint version")
+
+// ErrInvalidCheckpoint is returned by Load for a checkpoint whose state is
+// out of range or inconsistent, such as negative counts or compartments that
+// do not add up to the population.
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+
+// maxCheckpointDraws bounds the random stream position Load will restore.
+// Restoring replays every draw, so a corrupt count must not stall Load; 2^32
+// draws take tens of seconds to replay and are far beyond any real run.
+const maxCheckpointDraws = 1 << 32
+
+// ErrUntrackedRand is returned by Save while draws come from a ReplayRand
+// recording, whose position cannot be checkpointed.
+var ErrUntrackedRand = errors.New("random stream is replaying a recording and cannot be saved")
+
+// savedState is the JSON body of a checkpoint: every field that evolves or
+// can be configured, with the random stream stored as its seed and position.
+type savedState struct {
+	TransmissionModifier        float64                       `json:"transmission_modifier"`
+	ModifierSet                 bool                          `json:"modifier_set"`
+	BaseTransmission            float64                       `json:"base_transmission"`
+	BaseDeathRate               float64                       `json:"base_death_rate"`
+	HospitalCapacity            int                           `json:"hospital_capacity"`
+	DeathRateOverloadMultiplier float64                       `json:"death_rate_overload_multiplier"`
+	CurrentInfected             int                           `json:"current_infected"`
+	CurrentExposed              int                           `json:"current_exposed"`
+	CurrentRecovered            int                           `json:"current_recovered"`
+	CurrentImmune               int                           `json:"current_immune"`
+	CurrentSusceptible          int                           `json:"current_susceptible"`
+	Population                  int                           `json:"population"`
+	Regions                     []savedRegion                 `json:"regions,omitempty"`
+	TravelRate                  float64                       `json:"travel_rate"`
+	TravelMatrix                map[string]map[string]float64 `json:"travel_matrix,omitempty"`
+	Agents                      []Agent                       `json:"agents,omitempty"`
+	InfectionRadius             float64                       `json:"infection_radius"`
+	WorldWidth                  float64                       `json:"world_width"`
+	WorldHeight                 float64                       `json:"world_height"`
+	SpeedModifier               float64                       `json:"speed_modifier"`
+	CurrentVaccinated           int                           `json:"current_vaccinated"`
+	VaccinationDoses            int                           `json:"vaccination_doses"`
+	VaccineEfficacy             float64                       `json:"vaccine_efficacy"`
+	Variants                    []savedVariant                `json:"variants,omitempty"`
+	AgeBrackets                 []savedAgeBracket             `json:"age_brackets,omitempty"`
+	ImmunityDuration            float64                       `json:"immunity_duration"`
+	WanedSusceptible            int                           `json:"waned_susceptible"`
+	Waned                       int                           `json:"waned"`
+	Reinfections                int                           `json:"reinfections"`
+	Start                       savedStart                    `json:"start"`
+	TracingEffectiveness        float64                       `json:"tracing_effectiveness"`
+	TracingWindow               int                           `json:"tracing_window"`
+	Quarantine                  []int                         `json:"quarantine,omitempty"`
+	Isolated                    int                           `json:"isolated"`
+	DetectionRate               float64                       `json:"detection_rate"`
+	QuarantineEffectiveness     float64                       `json:"quarantine_effectiveness"`
+	CurrentQuarantined          int                           `json:"current_quarantined"`
+	Traced                      int                           `json:"traced"`
+	Generation                  int                           `json:"generation"`
+	Transitions                 *TransitionMatrix             `json:"transitions,omitempty"`
+	ContactBase                 int                           `json:"contact_base"`
+	ContactsPerInfected         float64                       `json:"contacts_per_infected"`
+	Contacts                    int                           `json:"contacts"`
+	IncubationPeriod            float64                       `json:"incubation_period"`
+	Rand                        RandState                     `json:"rand"`
+	SeedPhrase                  string                        `json:"seed_phrase,omitempty"`
+	LockdownEnabled             bool                          `json:"lockdown_enabled"`
+	InteractionVariance         float64                       `json:"interaction_variance"`
+	TickInterval                time.Duration                 `json:"tick_interval"`
+	OutcomeModel                OutcomeModel                  `json:"outcome_model"`
+	InfectiousPeriod            int                           `json:"infectious_period"`
+	Outcomes                    []savedOutcome                `json:"outcomes,omitempty"`
+	InitialJitter               int                           `json:"initial_jitter"`
+	Pathogens                   map[string]Profile            `json:"pathogens"`
+	ActivePathogen              string                        `json:"active_pathogen"`
+	Tick                        int                           `json:"tick"`
+	Paused                      bool                          `json:"paused"`
+	Strict                      bool                          `json:"strict"`
+	Imports                     map[int]int                   `json:"imports,omitempty"`
+	Timeline                    []TimelineEvent               `json:"timeline,omitempty"`
+	ImportRate                  float64                       `json:"import_rate"`
+	Imported                    int                           `json:"imported"`
+	TotalDeaths                 int                           `json:"total_deaths"`
+	RoundingMode                RoundingMode                  `json:"rounding_mode"`
+	SamplingMode                SamplingMode                  `json:"sampling_mode"`
+	Parallelism                 int                           `json:"parallelism"`
+	ReportPolicy                ReportPolicy                  `json:"report_policy"`
+	BurdenWeights               BurdenWeights                 `json:"burden_weights"`
+	Burden                      float64                       `json:"burden"`
+	BreakpointRepeat            bool                          `json:"breakpoint_repeat"`
+	ProbabilitySmoothing        float64                       `json:"probability_smoothing"`
+	SmoothedProbability         float64                       `json:"smoothed_probability"`
+	Behavior                    BehavioralResponse            `json:"behavior"`
+	Combination                 CombinationMode               `json:"combination"`
+	LockdownEffect              float64                       `json:"lockdown_effect"`
+	Dispersion                  float64                       `json:"dispersion"`
+	Offspring                   savedOffspring                `json:"offspring"`
+	Version                     uint64                        `json:"version"`
+	DaysPerTick                 float64                       `json:"days_per_tick"`
+	SimulatedDays               float64                       `json:"simulated_days"`
+	TotalInfections             int                           `json:"total_infections"`
+	EffectiveR                  float64                       `json:"effective_r"`
+	TicksBelowOne               int                           `json:"ticks_below_one"`
+	HerdStopTicks               int                           `json:"herd_stop_ticks"`
+	RecoveryRate                float64                       `json:"recovery_rate"`
+	CareBonus                   float64                       `json:"care_bonus"`
+	TotalRecoveries             int                           `json:"total_recoveries"`
+	Events                      []Event                       `json:"events,omitempty"`
+	History                     []Snapshot                    `json:"history,omitempty"`
+	HistoryCapacity             int                           `json:"history_capacity"`
+}
+
+type savedStart struct {
+	Infected   int `json:"infected"`
+	Exposed    int `json:"exposed"`
+	Recovered  int `json:"recovered"`
+	Immune     int `json:"immune"`
+	Population int `json:"population"`
+}
+
+type savedOutcome struct {
+	Deaths     int `json:"deaths"`
+	Recoveries int `json:"recoveries"`
+}
+
+type savedOffspring struct {
+	Mean         float64 `json:"mean"`
+	Variance     float64 `json:"variance"`
+	Max          int     `json:"max"`
+	CoreFraction float64 `json:"core_fraction"`
+}
+
+type savedRegion struct {
+	Name             string         `json:"name"`
+	Population       int            `json:"population"`
+	Susceptible      int            `json:"susceptible"`
+	Infected         int            `json:"infected"`
+	Recovered        int            `json:"recovered"`
+	Immune           int            `json:"immune"`
+	Deaths           int            `json:"deaths"`
+	HospitalCapacity int            `json:"hospital_capacity"`
+	Lockdown         bool           `json:"lockdown"`
+	Travelled        map[string]int `json:"travelled,omitempty"`
+}
+
+type savedVariant struct {
+	Name                   string  `json:"name"`
+	TransmissionMultiplier float64 `json:"transmission_multiplier"`
+	DeathMultiplier        float64 `json:"death_multiplier"`
+	Infected               int     `json:"infected"`
+	Infections             int     `json:"infections"`
+}
+
+type savedAgeBracket struct {
+	Name            string  `json:"name"`
+	Fraction        float64 `json:"fraction"`
+	DeathMultiplier float64 `json:"death_multiplier"`
+	Infected        int     `json:"infected"`
+	Deaths          int     `json:"deaths"`
+}
+
+// Save writes a checkpoint of the simulation to w: a format version byte
+// followed by a JSON document. Load restores it, and the restored run
+// continues with exactly the snapshots the original would have produced.
+// The logger, subscribers, breakpoint function, and Run loops are not part
+// of the checkpoint. Save fails with ErrUntrackedRand while draws are being
+// replayed from a recording.
+func (s *Simulation) Save(w io.Writer) error {
+	data, err := s.encodeState()
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	if err := out.WriteByte(saveFormatVersion); err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// encodeState encodes the checkpoint while holding the read lock. The
+// saved state shares the simulation's maps and slices, so it must not be
+// encoded after a Step could change them.
+func (s *Simulation) encodeState() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, err := s.savedStateLocked()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Load replaces the simulation's state with a checkpoint written by Save.
+// The logger, subscribers, and breakpoint function are kept. A checkpoint
+// whose values are out of range or inconsistent is rejected with
+// ErrInvalidCheckpoint. On error the simulation is left unchanged.
+func (s *Simulation) Load(r io.Reader) error {
+	in := bufio.NewReader(r)
+	version, err := in.ReadByte()
+	if err != nil {
+		return fmt.Errorf("read checkpoint version: %w", err)
+	}
+	if version != saveFormatVersion {
+		return fmt.Errorf("%w %d", ErrUnsupportedCheckpoint, version)
+	}
+	var state savedState
+	if err := json.NewDecoder(in).Decode(&state); err != nil {
+		return fmt.Errorf("decode checkpoint: %w", err)
+	}
+	if err := state.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.restoreLocked(state)
+	return nil
+}
+
+// validate checks a decoded checkpoint the way the setters and
+// Config.Validate check their inputs, so that Load never restores a state the
+// simulation could not have reached. Compartments must be non-negative and,
+// for the single pool, add up to the population.
+func (state savedState) validate() error {
+	if _, ok := state.Pathogens[state.ActivePathogen]; !ok {
+		return fmt.Errorf("active pathogen %q is not among the saved pathogens", state.ActivePathogen)
+	}
+	for name, profile := range state.Pathogens {
+		if sanitizeProfile(profile) != profile {
+			return fmt.Errorf("pathogen %q has out-of-range parameters %+v", name, profile)
+		}
+	}
+	for _, check := range []struct {
+		name  string
+		value float64
+		valid bool
+	}{
+		{"base_transmission", state.BaseTransmission, inUnitInterval(state.BaseTransmission)},
+		{"base_death_rate", state.BaseDeathRate, inUnitInterval(state.BaseDeathRate)},
+		{"transmission_modifier", state.TransmissionModifier, inUnitInterval(state.TransmissionModifier)},
+		{"recovery_rate", state.RecoveryRate, inUnitInterval(state.RecoveryRate)},
+		{"vaccine_efficacy", state.VaccineEfficacy, inUnitInterval(state.VaccineEfficacy)},
+		{"death_rate_overload_multiplier", state.DeathRateOverloadMultiplier,
+			state.DeathRateOverloadMultiplier >= 1 && !math.IsInf(state.DeathRateOverloadMultiplier, 1)},
+		{"care_bonus", state.CareBonus, state.CareBonus >= 1 && !math.IsInf(state.CareBonus, 1)},
+		{"interaction_variance", state.InteractionVariance,
+			sanitizeInteractionVariance(state.InteractionVariance) == state.InteractionVariance},
+		{"import_rate", state.ImportRate, sanitizeImportRate(state.ImportRate) == state.ImportRate},
+		{"infection_radius", state.InfectionRadius, sanitizeRadius(state.InfectionRadius) == state.InfectionRadius},
+		{"days_per_tick", state.DaysPerTick, state.DaysPerTick > 0 && !math.IsInf(state.DaysPerTick, 1)},
+	} {
+		if !check.valid {
+			return fmt.Errorf("%s %v is out of range", check.name, check.value)
+		}
+	}
+	for _, v := range state.Variants {
+		if !validMultiplier(v.TransmissionMultiplier) || !validMultiplier(v.DeathMultiplier) || v.Infected < 0 {
+			return fmt.Errorf("variant %q is out of range", v.Name)
+		}
+	}
+	for _, b := range state.AgeBrackets {
+		if !validMultiplier(b.Fraction) || !validMultiplier(b.DeathMultiplier) || b.Infected < 0 || b.Deaths < 0 {
+			return fmt.Errorf("age bracket %q is out of range", b.Name)
+		}
+	}
+	for _, r := range state.Regions {
+		if min(r.Population, r.Susceptible, r.Infected, r.Recovered, r.Immune, r.Deaths, r.HospitalCapacity) < 0 {
+			return fmt.Errorf("region %q has a negative count", r.Name)
+		}
+	}
+
+	quarantined := 0
+	for _, count := range state.Quarantine {
+		if count < 0 {
+			return errors.New("quarantine has a negative count")
+		}
+		quarantined += count
+	}
+	counts := []int{
+		state.CurrentInfected, state.CurrentExposed, state.CurrentRecovered, state.CurrentImmune,
+		state.CurrentSusceptible, state.CurrentVaccinated, state.TotalDeaths, state.Population,
+		state.HospitalCapacity, state.Isolated, state.CurrentQuarantined, state.VaccinationDoses,
+		state.Start.Infected, state.Start.Exposed, state.Start.Recovered, state.Start.Immune, state.Start.Population,
+	}
+	if slices.Min(counts) < 0 {
+		return errors.New("a compartment or capacity is negative")
+	}
+	spatial := state.InfectionRadius > 0 && len(state.Agents) > 0
+	if state.Population > 0 && len(state.Regions) == 0 && !spatial {
+		total := state.CurrentSusceptible + state.CurrentExposed + state.CurrentInfected + state.CurrentRecovered +
+			state.CurrentImmune + state.CurrentVaccinated + state.TotalDeaths + quarantined
+		if total != state.Population {
+			return fmt.Errorf("compartments add up to %d, not the population %d", total, state.Population)
+		}
+	}
+	if start := state.Start; start.Population > 0 &&
+		start.Infected+start.Exposed+start.Recovered+start.Immune > start.Population {
+		return fmt.Errorf("starting compartments exceed the starting population %d", start.Population)
+	}
+	for i, e := range state.Timeline {
+		if err := e.validate(i); err != nil {
+			return err
+		}
+	}
+
+	if !state.Rand.Tracked {
+		return errors.New("random stream position is missing")
+	}
+	if state.Rand.Draws > maxCheckpointDraws {
+		return fmt.Errorf("random stream position %d is beyond %d draws", state.Rand.Draws, uint64(maxCheckpointDraws))
+	}
+	return nil
+}
+
+// inUnitInterval reports whether p is a probability.
+func inUnitInterval(p float64) bool {
+	return p >= 0 && p <= 1
+}
+
+func (s *Simulation) savedStateLocked() (savedState, error) {
+	if s.draws == nil {
+		return savedState{}, ErrUntrackedRand
+	}
+
+	state := s.modelStateLocked()
+	state.Rand = RandState{Seed: s.seed, Draws: s.draws.draws, Tracked: true}
+	return state, nil
+}
+
+// modelStateLocked captures everything savedStateLocked does except the
+// random stream, which may be replaying a recording. The result shares the
+// simulation's maps and slices.
+func (s *Simulation) modelStateLocked() savedState {
+	state := savedState{
+		TransmissionModifier:        s.transmissionMod,
+		ModifierSet:                 s.modifierSet,
+		BaseTransmission:            s.baseTransmission,
+		BaseDeathRate:               s.baseDeathRate,
+		HospitalCapacity:            s.hospitalCapacity,
+		DeathRateOverloadMultiplier: s.deathRateOverloadMultiplier,
+		CurrentInfected:             s.currentInfected,
+		CurrentExposed:              s.currentExposed,
+		CurrentRecovered:            s.currentRecovered,
+		CurrentImmune:               s.currentImmune,
+		CurrentSusceptible:          s.currentSusceptible,
+		Population:                  s.population,
+		TravelRate:                  s.travelRate,
+		TravelMatrix:                s.travelMatrix,
+		Agents:                      s.agents,
+		InfectionRadius:             s.infectionRadius,
+		WorldWidth:                  s.worldWidth,
+		WorldHeight:                 s.worldHeight,
+		SpeedModifier:               s.speedModifier,
+		CurrentVaccinated:           s.currentVaccinated,
+		VaccinationDoses:            s.vaccinationDoses,
+		VaccineEfficacy:             s.vaccineEfficacy,
+		ImmunityDuration:            s.immunityDuration,
+		WanedSusceptible:            s.wanedSusceptible,
+		Waned:                       s.waned,
+		Reinfections:                s.reinfections,
+		Start: savedStart{
+			Infected:   s.start.infected,
+			Exposed:    s.start.exposed,
+			Recovered:  s.start.recovered,
+			Immune:     s.start.immune,
+			Population: s.start.population,
+		},
+		TracingEffectiveness:    s.tracingEffectiveness,
+		TracingWindow:           s.tracingWindow,
+		Quarantine:              s.quarantine,
+		Isolated:                s.isolated,
+		DetectionRate:           s.detectionRate,
+		QuarantineEffectiveness: s.quarantineEffectiveness,
+		CurrentQuarantined:      s.currentQuarantined,
+		Traced:                  s.traced,
+		Generation:              s.generation,
+		Transitions:             s.transitions,
+		ContactBase:             s.contactBase,
+		ContactsPerInfected:     s.contactsPerInfected,
+		Contacts:                s.contacts,
+		IncubationPeriod:        s.incubationPeriod,
+		SeedPhrase:              s.seedPhrase,
+		LockdownEnabled:         s.lockdownEnabled,
+		InteractionVariance:     s.interactionVariance,
+		TickInterval:            s.tickInterval,
+		OutcomeModel:            s.outcomeModel,
+		InfectiousPeriod:        s.infectiousPeriod,
+		InitialJitter:           s.initialJitter,
+		Pathogens:               s.pathogens,
+		ActivePathogen:          s.activePathogen,
+		Tick:                    s.tick,
+		Paused:                  s.paused,
+		Strict:                  s.strict,
+		Imports:                 s.imports,
+		Timeline:                s.timeline,
+		ImportRate:              s.importRate,
+		Imported:                s.imported,
+		TotalDeaths:             s.totalDeaths,
+		RoundingMode:            s.roundingMode,
+		SamplingMode:            s.samplingMode,
+		Parallelism:             s.parallelism,
+		ReportPolicy:            s.reportPolicy,
+		BurdenWeights:           s.burdenWeights,
+		Burden:                  s.burden,
+		BreakpointRepeat:        s.breakpointRepeat,
+		ProbabilitySmoothing:    s.probabilitySmoothing,
+		SmoothedProbability:     s.smoothedProbability,
+		Behavior:                s.behavior,
+		Combination:             s.combination,
+		LockdownEffect:          s.lockdownEffect,
+		Dispersion:              s.dispersion,
+		Offspring: savedOffspring{
+			Mean:         s.offspring.mean,
+			Variance:     s.offspring.variance,
+			Max:          s.offspring.max,
+			CoreFraction: s.offspring.coreFraction,
+		},
+		Version:         s.version,
+		DaysPerTick:     s.daysPerTick,
+		SimulatedDays:   s.simulatedDays,
+		TotalInfections: s.totalInfections,
+		EffectiveR:      s.effectiveR,
+		TicksBelowOne:   s.ticksBelowOne,
+		HerdStopTicks:   s.herdStopTicks,
+		RecoveryRate:    s.recoveryRate,
+		CareBonus:       s.careBonus,
+		TotalRecoveries: s.totalRecoveries,
+		Events:          s.events,
+		History:         s.history.snapshots(),
+		HistoryCapacity: s.historyCapacity,
+	}
+	for _, r := range s.regions {
+		state.Regions = append(state.Regions, savedRegion{
+			Name:             r.name,
+			Population:       r.population,
+			Susceptible:      r.susceptible,
+			Infected:         r.infected,
+			Recovered:        r.recovered,
+			Immune:           r.immune,
+			Deaths:           r.deaths,
+			HospitalCapacity: r.hospitalCapacity,
+			Lockdown:         r.lockdown,
+			Travelled:        r.travelled,
+		})
+	}
+	for _, v := range s.variants {
+		state.Variants = append(state.Variants, savedVariant{
+			Name:                   v.name,
+			TransmissionMultiplier: v.transmissionMultiplier,
+			DeathMultiplier:        v.deathMultiplier,
+			Infected:               v.infected,
+			Infections:             v.infections,
+		})
+	}
+	for _, b := range s.ageBrackets {
+		state.AgeBrackets = append(state.AgeBrackets, savedAgeBracket{
+			Name:            b.name,
+			Fraction:        b.fraction,
+			DeathMultiplier: b.deathMultiplier,
+			Infected:        b.infected,
+			Deaths:          b.deaths,
+		})
+	}
+	for _, o := range s.outcomes {
+		state.Outcomes = append(state.Outcomes, savedOutcome{Deaths: o.deaths, Recoveries: o.recoveries})
+	}
+	return state
+}
+
+func (s *Simulation) restoreLocked(state savedState) {
+	s.transmissionMod = state.TransmissionModifier
+	s.modifierSet = state.ModifierSet
+	s.baseTransmission = state.BaseTransmission
+	s.baseDeathRate = state.BaseDeathRate
+	s.hospitalCapacity = state.HospitalCapacity
+	s.deathRateOverloadMultiplier = state.DeathRateOverloadMultiplier
+	s.currentInfected = state.CurrentInfected
+	s.currentExposed = state.CurrentExposed
+	s.currentRecovered = state.CurrentRecovered
+	s.currentImmune = state.CurrentImmune
+	s.currentSusceptible = state.CurrentSusceptible
+	s.population = state.Population
+	s.travelRate = state.TravelRate
+	s.travelMatrix = state.TravelMatrix
+	s.agents = state.Agents
+	s.infectionRadius = state.InfectionRadius
+	s.worldWidth = state.WorldWidth
+	s.worldHeight = state.WorldHeight
+	s.speedModifier = state.SpeedModifier
+	SetCurrentSpeedModifier(s.speedModifier)
+	s.currentVaccinated = state.CurrentVaccinated
+	s.vaccinationDoses = state.VaccinationDoses
+	s.vaccineEfficacy = state.VaccineEfficacy
+	s.immunityDuration = state.ImmunityDuration
+	s.wanedSusceptible = state.WanedSusceptible
+	s.waned = state.Waned
+	s.reinfections = state.Reinfections
+	s.start = startingPoint{
+		infected:   state.Start.Infected,
+		exposed:    state.Start.Exposed,
+		recovered:  state.Start.Recovered,
+		immune:     state.Start.Immune,
+		population: state.Start.Population,
+	}
+	s.tracingEffectiveness = state.TracingEffectiveness
+	s.tracingWindow = state.TracingWindow
+	s.quarantine = state.Quarantine
+	s.isolated = state.Isolated
+	s.detectionRate = state.DetectionRate
+	s.quarantineEffectiveness = state.QuarantineEffectiveness
+	s.currentQuarantined = state.CurrentQuarantined
+	s.traced = state.Traced
+	s.generation = state.Generation
+	s.transitions = state.Transitions
+	s.contactBase = state.ContactBase
+	s.contactsPerInfected = state.ContactsPerInfected
+	s.contacts = state.Contacts
+	s.incubationPeriod = state.IncubationPeriod
+	s.restoreRandLocked(state.Rand)
+	s.seedPhrase = state.SeedPhrase
+	s.lockdownEnabled = state.LockdownEnabled
+	s.interactionVariance = state.InteractionVariance
+	s.tickInterval = state.TickInterval
+	s.outcomeModel = state.OutcomeModel
+	s.infectiousPeriod = state.InfectiousPeriod
+	s.initialJitter = state.InitialJitter
+	s.pathogens = state.Pathogens
+	s.activePathogen = state.ActivePathogen
+	s.tick = state.Tick
+	s.paused = state.Paused
+	s.strict = state.Strict
+	s.imports = state.Imports
+	s.timeline = state.Timeline
+	s.importRate = state.ImportRate
+	s.imported = state.Imported
+	s.totalDeaths = state.TotalDeaths
+	s.roundingMode = state.RoundingMode
+	s.samplingMode = state.SamplingMode
+	s.parallelism = state.Parallelism
+	s.reportPolicy = state.ReportPolicy
+	s.burdenWeights = state.BurdenWeights
+	s.burden = state.Burden
+	s.breakpointRepeat = state.BreakpointRepeat
+	s.probabilitySmoothing = state.ProbabilitySmoothing
+	s.smoothedProbability = state.SmoothedProbability
+	s.behavior = state.Behavior
+	s.combination = state.Combination
+	s.lockdownEffect = state.LockdownEffect
+	s.dispersion = state.Dispersion
+	s.offspring = offspringSummary{
+		mean:         state.Offspring.Mean,
+		variance:     state.Offspring.Variance,
+		max:          state.Offspring.Max,
+		coreFraction: state.Offspring.CoreFraction,
+	}
+	s.version = state.Version
+	s.daysPerTick = state.DaysPerTick
+	s.simulatedDays = state.SimulatedDays
+	s.totalInfections = state.TotalInfections
+	s.effectiveR = state.EffectiveR
+	s.ticksBelowOne = state.TicksBelowOne
+	s.herdStopTicks = state.HerdStopTicks
+	s.recoveryRate = state.RecoveryRate
+	s.careBonus = state.CareBonus
+	s.totalRecoveries = state.TotalRecoveries
+	s.events = state.Events
+	s.historyCapacity = state.HistoryCapacity
+	s.history = snapshotRing{}
+	for _, snapshot := range state.History {
+		s.recordHistoryLocked(snapshot)
+	}
+
+	s.regions = nil
+	for _, r := range state.Regions {
+		s.regions = append(s.regions, region{
+			name:             r.Name,
+			population:       r.Population,
+			susceptible:      r.Susceptible,
+			infected:         r.Infected,
+			recovered:        r.Recovered,
+			immune:           r.Immune,
+			deaths:           r.Deaths,
+			hospitalCapacity: r.HospitalCapacity,
+			lockdown:         r.Lockdown,
+			travelled:        r.Travelled,
+		})
+	}
+	s.variants = nil
+	for _, v := range state.Variants {
+		s.variants = append(s.variants, variant{
+			name:                   v.Name,
+			transmissionMultiplier: v.TransmissionMultiplier,
+			deathMultiplier:        v.DeathMultiplier,
+			infected:               v.Infected,
+			infections:             v.Infections,
+		})
+	}
+	s.ageBrackets = nil
+	for _, b := range state.AgeBrackets {
+		s.ageBrackets = append(s.ageBrackets, ageBracket{
+			name:            b.Name,
+			fraction:        b.Fraction,
+			deathMultiplier: b.DeathMultiplier,
+			infected:        b.Infected,
+			deaths:          b.Deaths,
+		})
+	}
+	s.outcomes = nil
+	for _, o := range state.Outcomes {
+		s.outcomes = append(s.outcomes, scheduledOutcome{deaths: o.Deaths, recoveries: o.Recoveries})
+	}
+}
+
+// restoreRandLocked reseeds the random stream and skips the draws already
+// taken, putting it exactly where the saved run left off.
+func (s *Simulation) restoreRandLocked(state RandState) {
+	s.seedLocked(state.Seed)
+	for i := uint64(0); i < state.Draws; i++ {
+		s.draws.src.Uint64()
+	}
+	s.draws.draws = state.Draws
+}
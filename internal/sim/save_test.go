@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLoadResumesAnIdenticalRun(t *testing.T) {
+	configurations := map[string]func(s *Simulation){
+		"single pool": func(s *Simulation) {
+			s.SetIncubationPeriod(2)
+			s.SetTracingEffectiveness(0.3)
+			s.SetDetectionRate(0.2)
+			s.SetImportRate(0.5)
+			s.SetQuarantineEffectiveness(0.8)
+			s.SetInteractionVariance(0.5)
+			s.SetImmunityDuration(10)
+			s.SetVaccineEfficacy(0.6)
+			s.StartVaccination(5)
+			if err := s.AddVariant("original", 1, 1); err != nil {
+				t.Fatalf("add variant: %v", err)
+			}
+			if err := s.AddVariant("delta", 1.5, 2); err != nil {
+				t.Fatalf("add variant: %v", err)
+			}
+			if _, err := s.IntroduceVariant("delta", 3); err != nil {
+				t.Fatalf("introduce variant: %v", err)
+			}
+			if err := s.SetAgeBrackets([]AgeBracket{{"young", 0.7, 0.5}, {"old", 0.3, 4}}); err != nil {
+				t.Fatalf("set age brackets: %v", err)
+			}
+		},
+		"regions": func(s *Simulation) {
+			for _, name := range []string{"north", "south"} {
+				if err := s.AddRegion(name, 500); err != nil {
+					t.Fatalf("add region: %v", err)
+				}
+			}
+			s.SetTravelRate(0.1)
+		},
+		"spatial": func(s *Simulation) {
+			for i := 0; i < 40; i++ {
+				s.AddAgent(Agent{X: float64(i % 8), Y: float64(i / 8), DirectionX: 1, BaseSpeed: 0.5, Infected: i == 0})
+			}
+			s.SetWorldBounds(10, 10)
+			s.SetInfectionRadius(1.5)
+		},
+	}
+
+	for name, configure := range configurations {
+		t.Run(name, func(t *testing.T) {
+			original := NewWithSeed(0.3, 21)
+			configure(original)
+			for i := 0; i < 15; i++ {
+				original.Step()
+			}
+
+			var checkpoint bytes.Buffer
+			if err := original.Save(&checkpoint); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			restored := NewWithSeed(0.9, 1)
+			if err := restored.Load(&checkpoint); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if !reflect.DeepEqual(restored.Snapshot(), original.Snapshot()) {
+				t.Fatalf("expected the restored snapshot to match\n got %+v\nwant %+v", restored.Snapshot(), original.Snapshot())
+			}
+
+			for i := 0; i < 15; i++ {
+				want, got := original.Step(), restored.Step()
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("tick %d diverged after loading\n got %+v\nwant %+v", want.Tick, got, want)
+				}
+			}
+			if got, want := restored.RandState(), original.RandState(); got != want {
+				t.Fatalf("expected rand state %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownVersions(t *testing.T) {
+	s := New(0.25)
+	before := s.Snapshot()
+
+	err := s.Load(bytes.NewReader([]byte{saveFormatVersion + 1, '{', '}'}))
+	if !errors.Is(err, ErrUnsupportedCheckpoint) {
+		t.Fatalf("expected ErrUnsupportedCheckpoint, got %v", err)
+	}
+	if !reflect.DeepEqual(s.Snapshot(), before) {
+		t.Fatal("expected a failed load to leave the simulation unchanged")
+	}
+}
+
+func TestLoadRejectsCorruptCheckpoints(t *testing.T) {
+	source := NewWithSeed(0.3, 4)
+	for i := 0; i < 5; i++ {
+		source.Step()
+	}
+	var checkpoint bytes.Buffer
+	if err := source.Save(&checkpoint); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	for name, corrupt := range map[string]func(state map[string]any){
+		"no pathogens":        func(state map[string]any) { state["pathogens"] = nil },
+		"zero multiplier":     func(state map[string]any) { state["death_rate_overload_multiplier"] = 0 },
+		"negative infected":   func(state map[string]any) { state["current_infected"] = -5 },
+		"leaky compartments":  func(state map[string]any) { state["current_recovered"] = 1e6 },
+		"endless draws":       func(state map[string]any) { state["rand"].(map[string]any)["draws"] = uint64(1) << 62 },
+		"probability above 1": func(state map[string]any) { state["base_transmission"] = 7 },
+	} {
+		var state map[string]any
+		if err := json.Unmarshal(checkpoint.Bytes()[1:], &state); err != nil {
+			t.Fatalf("decode checkpoint: %v", err)
+		}
+		corrupt(state)
+		body, err := json.Marshal(state)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", name, err)
+		}
+
+		s := NewWithSeed(0.25, 1)
+		before := s.Snapshot()
+		if err := s.Load(bytes.NewReader(append([]byte{saveFormatVersion}, body...))); !errors.Is(err, ErrInvalidCheckpoint) {
+			t.Fatalf("%s: expected ErrInvalidCheckpoint, got %v", name, err)
+		}
+		if !reflect.DeepEqual(s.Snapshot(), before) {
+			t.Fatalf("%s: expected a rejected load to leave the simulation unchanged", name)
+		}
+	}
+}
+
+func TestSaveRefusesAReplayedStream(t *testing.T) {
+	s := New(0.25)
+	if err := s.ReplayRand(bytes.NewReader(nil)); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if err := s.Save(&bytes.Buffer{}); !errors.Is(err, ErrUntrackedRand) {
+		t.Fatalf("expected ErrUntrackedRand, got %v", err)
+	}
+}
+
+func TestSaveDuringStepsDoesNotRace(t *testing.T) {
+	s := NewWithSeed(0.3, 21)
+	for tick := 1; tick <= 50; tick++ {
+		if err := s.ScheduleImport(tick, 1); err != nil {
+			t.Fatalf("schedule import: %v", err)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		s.AddAgent(Agent{X: float64(i % 5), Y: float64(i / 5), DirectionX: 1, BaseSpeed: 0.5, Infected: i == 0})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			s.Step()
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if err := s.Save(io.Discard); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package sim
+
+import "math"
+
+// SetIncubationPeriod adds an exposed stage between infection and
+// infectiousness. New infections enter the exposed pool and each exposed
+// individual becomes infectious with probability 1/ticks per tick, so the
+// mean incubation period is ticks. Only infectious individuals spread the
+// pathogen, count against hospital capacity, or can die. Periods below one
+// tick are raised to one; zero, negative, or non-finite periods disable the
+// exposed stage, which is the default.
+func (s *Simulation) SetIncubationPeriod(ticks float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ticks <= 0 || math.IsNaN(ticks) || math.IsInf(ticks, 0) {
+		ticks = 0
+	} else if ticks < 1 {
+		ticks = 1
+	}
+	if ticks == 0 {
+		// Without an exposed stage everyone waiting out incubation is
+		// infectious straight away.
+		s.currentInfected += s.currentExposed
+		s.currentExposed = 0
+	}
+	s.incubationPeriod = ticks
+}
+
+// IncubationPeriod returns the mean incubation period in ticks, or 0 when the
+// exposed stage is disabled.
+func (s *Simulation) IncubationPeriod() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.incubationPeriod
+}
+
+// progressExposedLocked moves exposed individuals who finish incubating this
+// tick into the infectious pool and returns how many did.
+func (s *Simulation) progressExposedLocked() int {
+	if s.incubationPeriod == 0 || s.transitions != nil {
+		return 0
+	}
+
+	chance := 1 / s.incubationPeriod
+	progressed := 0
+	for i := 0; i < s.currentExposed; i++ {
+		if s.rng.Float64() < chance {
+			progressed++
+		}
+	}
+	s.currentExposed -= progressed
+	s.currentInfected += progressed
+	return progressed
+}
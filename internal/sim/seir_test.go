@@ -0,0 +1,61 @@
+package sim
+
+import "testing"
+
+func TestNewInfectionsIncubateBeforeBecomingInfectious(t *testing.T) {
+	s := New(0.5)
+	s.SetSeed(5)
+	s.baseDeathRate = 0
+	s.SetIncubationPeriod(4)
+
+	s.Step()
+	state := s.Snapshot()
+	if state.TotalInfections == 0 {
+		t.Fatal("expected new infections on the first tick")
+	}
+	if state.CurrentExposed != state.TotalInfections || state.CurrentInfected != 10 {
+		t.Fatalf("expected %d exposed and 10 infectious, got %d exposed and %d infectious",
+			state.TotalInfections, state.CurrentExposed, state.CurrentInfected)
+	}
+
+	s.UpdateTransmissionModifier(0)
+	for i := 0; i < 200; i++ {
+		s.Step()
+	}
+	state = s.Snapshot()
+	if state.CurrentExposed != 0 || state.CurrentInfected != 10+state.TotalInfections {
+		t.Fatalf("expected every exposed case to become infectious, got %d exposed and %d infectious",
+			state.CurrentExposed, state.CurrentInfected)
+	}
+}
+
+func TestExposedProgressAtOneOverIncubationPerTick(t *testing.T) {
+	s := New(0.5)
+	s.SetSeed(9)
+	s.baseDeathRate = 0
+	s.UpdateTransmissionModifier(0)
+	s.SetIncubationPeriod(4)
+	s.currentExposed = 4000
+
+	s.Step()
+	progressed := s.Snapshot().CurrentInfected - 10
+	if progressed < 900 || progressed > 1100 {
+		t.Fatalf("expected about a quarter of 4000 exposed to progress, got %d", progressed)
+	}
+}
+
+func TestDisablingIncubationReleasesExposed(t *testing.T) {
+	s := New(0.5)
+	s.SetIncubationPeriod(0.2)
+	if got := s.IncubationPeriod(); got != 1 {
+		t.Fatalf("expected a sub-tick incubation period to be raised to 1, got %v", got)
+	}
+	s.currentExposed = 7
+
+	s.SetIncubationPeriod(0)
+	state := s.Snapshot()
+	if state.CurrentExposed != 0 || state.CurrentInfected != 17 {
+		t.Fatalf("expected exposed cases to become infectious, got %d exposed and %d infectious",
+			state.CurrentExposed, state.CurrentInfected)
+	}
+}
@@ -2,28 +2,114 @@ package sim
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"math"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
 )
 
 const defaultBaseDeathRate = 0.01
 
+// lockdownSpeedModifier is the movement multiplier while a lockdown is on.
+const lockdownSpeedModifier = 0.1
+
+// MinTickInterval is the shortest tick interval Run will honor. Faster
+// intervals would busy-spin the loop and peg a CPU.
+const MinTickInterval = time.Millisecond
+
+// MinControlTickInterval is the shortest tick interval a control update can
+// set. Operators drag a slider, so out-of-range values are raised rather than
+// rejected unless the simulation is strict.
+const MinControlTickInterval = 10 * time.Millisecond
+
+// ErrTickIntervalTooShort is returned when a requested tick interval is below
+// MinTickInterval.
+var ErrTickIntervalTooShort = errors.New("tick interval below minimum")
+
+// ErrOutOfRange is returned by ApplyControlSettings in strict mode when a
+// control value falls outside its valid range.
+var ErrOutOfRange = errors.New("control value out of range")
+
+// ErrVersionConflict is returned by ApplyControlSettings when the update was
+// based on a state version that another writer has since moved past.
+var ErrVersionConflict = errors.New("control state changed since it was read")
+
 // Snapshot captures the current state of the simulation at a single point in
-// time.
+// time. Generation counts calls to Reset; ticks restart from zero in each.
+// Waned and Reinfections count, for the last tick, the people who lost their
+// immunity and the infections of people who had lost it. CurrentQuarantined
+// counts detected infectious people in quarantine, whereas Quarantined counts
+// traced susceptible contacts. Imported counts the outside infections that
+// arrived on the last tick, scheduled or drawn from ImportRate. InitialImmune
+// is how many people the run started immune. SimulatedDay adds up DaysPerTick
+// tick by tick, so changing the ratio affects only later ticks.
 type Snapshot struct {
-	TransmissionModifier        float64
-	InfectionProbability        float64
-	LockdownEnabled             bool
-	SpeedModifier               float64
-	HospitalCapacity            int
-	DeathRateOverloadMultiplier float64
-	CurrentInfected             int
-	EffectiveDeathProbability   float64
-	Overloaded                  bool
-	CapacityUtilization         float64
+	Tick                        int     `json:"tick"`
+	Generation                  int     `json:"generation"`
+	Paused                      bool    `json:"paused"`
+	SimulatedDay                float64 `json:"simulated_day"`
+	BaseTransmission            float64 `json:"base_transmission"`
+	TransmissionModifier        float64 `json:"transmission_modifier"`
+	InfectionProbability        float64 `json:"infection_probability"`
+	SmoothedProbability         float64 `json:"smoothed_probability"`
+	LockdownEnabled             bool    `json:"lockdown_enabled"`
+	SpeedModifier               float64 `json:"speed_modifier"`
+	TickIntervalMs              int64   `json:"tick_interval_ms"`
+	HospitalCapacity            int     `json:"hospital_capacity"`
+	DeathRateOverloadMultiplier float64 `json:"death_rate_overload_multiplier"`
+	CurrentInfected             int     `json:"current_infected"`
+	CurrentExposed              int     `json:"current_exposed"`
+	CurrentRecovered            int     `json:"current_recovered"`
+	CurrentImmune               int     `json:"current_immune"`
+	CurrentVaccinated           int     `json:"current_vaccinated"`
+	CurrentSusceptible          int     `json:"current_susceptible"`
+	Population                  int     `json:"population"`
+	InitialImmune               int     `json:"initial_immune"`
+	Traced                      int     `json:"traced"`
+	Isolated                    int     `json:"isolated"`
+	Quarantined                 int     `json:"quarantined"`
+	CurrentQuarantined          int     `json:"current_quarantined"`
+	EffectiveDeathProbability   float64 `json:"effective_death_probability"`
+	EffectiveRecoveryRate       float64 `json:"effective_recovery_rate"`
+	Overloaded                  bool    `json:"overloaded"`
+	CapacityUtilization         float64 `json:"capacity_utilization"`
+	InteractionVariance         float64 `json:"interaction_variance"`
+	Contacts                    int     `json:"contacts"`
+	ImportRate                  float64 `json:"import_rate"`
+	InfectionRadius             float64 `json:"infection_radius"`
+	Imported                    int     `json:"imported"`
+	Waned                       int     `json:"waned"`
+	Reinfections                int     `json:"reinfections"`
+	ScheduledDeaths             int     `json:"scheduled_deaths"`
+	ActivePathogen              string  `json:"active_pathogen"`
+	TotalDeaths                 int     `json:"total_deaths"`
+	TotalInfections             int     `json:"total_infections"`
+	TotalRecoveries             int     `json:"total_recoveries"`
+	EffectiveR                  float64 `json:"effective_r"`
+	DoublingTime                float64 `json:"doubling_time"`
+	Burden                      float64 `json:"burden"`
+	VoluntaryReduction          float64 `json:"voluntary_reduction"`
+	SeedPhrase                  string  `json:"seed_phrase,omitempty"`
+	StateVersion                uint64  `json:"state_version"`
+	LockdownFactor              float64 `json:"lockdown_factor"`
+	BehaviorFactor              float64 `json:"behavior_factor"`
+	CombinedFactor              float64 `json:"combined_factor"`
+	SecondaryMean               float64 `json:"secondary_mean"`
+	SecondaryVariance           float64 `json:"secondary_variance"`
+	SecondaryMax                int     `json:"secondary_max"`
+	CoreSpreaderFraction        float64 `json:"core_spreader_fraction"`
+
+	// Regions is empty unless AddRegion has split the world into regions.
+	Regions []RegionSnapshot `json:"regions,omitempty"`
+	// Variants is empty unless AddVariant has registered variants.
+	Variants []VariantSnapshot `json:"variants,omitempty"`
+	// AgeBrackets is empty unless SetAgeBrackets has split the population.
+	AgeBrackets []AgeBracketSnapshot `json:"age_brackets,omitempty"`
 }
 
 // ControlSettings groups together the tunable parameters driven by the UI.
@@ -32,6 +118,24 @@ type ControlSettings struct {
 	LockdownEnabled             bool
 	HospitalCapacity            int
 	DeathRateOverloadMultiplier float64
+	// InteractionVariance is optional; nil leaves the current variance intact.
+	InteractionVariance *float64
+	// ExpectedVersion is the StateVersion the writer last saw. When set, the
+	// update is rejected with ErrVersionConflict if the version has moved on;
+	// nil keeps last-writer-wins.
+	ExpectedVersion *uint64
+	// TickInterval is optional; nil leaves the Run interval unchanged.
+	TickInterval *time.Duration
+	// ImportRate is optional; nil leaves the import rate unchanged.
+	ImportRate *float64
+	// InfectionRadius is optional; nil leaves the spatial contact radius
+	// unchanged. A larger radius means more contacts between agents.
+	InfectionRadius *float64
+	// Population is optional; nil leaves the population unchanged. Growing it
+	// adds susceptible people and shrinking it removes them; a population too
+	// small to hold everyone who is not susceptible is rejected with
+	// ErrPopulationTooSmall. Zero or negative removes the limit.
+	Population *int
 }
 
 // Simulation tracks transmission probabilities and exposes knobs to adjust the
@@ -45,18 +149,117 @@ type Simulation struct {
 	hospitalCapacity            int
 	deathRateOverloadMultiplier float64
 	currentInfected             int
+	currentExposed              int
+	currentRecovered            int
+	currentImmune               int
+	currentSusceptible          int
+	population                  int
+	regions                     []region
+	travelRate                  float64
+	travelMatrix                map[string]map[string]float64
+	agents                      []Agent
+	infectionRadius             float64
+	bruteForceNeighbours        bool
+	worldWidth                  float64
+	worldHeight                 float64
+	speedModifier               float64
+	currentVaccinated           int
+	vaccinationDoses            int
+	vaccineEfficacy             float64
+	variants                    []variant
+	ageBrackets                 []ageBracket
+	immunityDuration            float64
+	wanedSusceptible            int
+	waned                       int
+	reinfections                int
+	start                       startingPoint
+	tracingEffectiveness        float64
+	tracingWindow               int
+	quarantine                  []int
+	isolated                    int
+	detectionRate               float64
+	quarantineEffectiveness     float64
+	currentQuarantined          int
+	traced                      int
+	generation                  int
+	transitions                 *TransitionMatrix
+	contactBase                 int
+	contactsPerInfected         float64
+	contacts                    int
+	incubationPeriod            float64
 	rng                         *rand.Rand
+	seed                        int64
+	seedPhrase                  string
+	draws                       *countingSource
 	lockdownEnabled             bool
+	interactionVariance         float64
+	tickInterval                time.Duration
+	logger                      Logger
+	outcomeModel                OutcomeModel
+	infectiousPeriod            int
+	outcomes                    []scheduledOutcome
+	initialJitter               int
+	pathogens                   map[string]Profile
+	activePathogen              string
+	tick                        int
+	paused                      bool
+	strict                      bool
+	imports                     map[int]int
+	timeline                    []TimelineEvent
+	importRate                  float64
+	imported                    int
+	totalDeaths                 int
+	roundingMode                RoundingMode
+	samplingMode                SamplingMode
+	parallelism                 int
+	runReporters                int
+	reportPolicy                ReportPolicy
+	burdenWeights               BurdenWeights
+	burden                      float64
+	breakpoint                  func(Snapshot) bool
+	breakpointRepeat            bool
+	probabilitySmoothing        float64
+	smoothedProbability         float64
+	behavior                    BehavioralResponse
+	combination                 CombinationMode
+	lockdownEffect              float64
+	dispersion                  float64
+	offspring                   offspringSummary
+	version                     uint64
+	daysPerTick                 float64
+	simulatedDays               float64
+	totalInfections             int
+	effectiveR                  float64
+	ticksBelowOne               int
+	herdStopTicks               int
+	recoveryRate                float64
+	careBonus                   float64
+	totalRecoveries             int
+	events                      []Event
+	history                     snapshotRing
+	historyCapacity             int
+
+	subMu       sync.Mutex
+	subscribers map[chan Snapshot]struct{}
 }
 
 // New creates a simulation with the provided base transmission probability.
-// If baseTransmission is zero, a default of 0.25 is used.
+// If baseTransmission is zero, a default of 0.25 is used. The random stream
+// is seeded from the clock; use NewWithSeed for a reproducible run.
 func New(baseTransmission float64) *Simulation {
+	return NewWithSeed(baseTransmission, time.Now().UnixNano())
+}
+
+// NewWithSeed is like New but seeds the random stream with seed. Every
+// random draw, including agent sampling such as VaccinateRegion and the
+// choice of agents that imported infections reach, comes from this one
+// stream, so two simulations created with the same seed and given the same
+// inputs produce identical snapshots.
+func NewWithSeed(baseTransmission float64, seed int64) *Simulation {
 	if baseTransmission <= 0 {
 		baseTransmission = 0.25
 	}
-	SetCurrentSpeedModifier(1.0)
-	return &Simulation{
+	s := &Simulation{
 		transmissionMod:             1.0,
 		modifierSet:                 false,
 		baseTransmission:            baseTransmission,
@@ -64,8 +267,30 @@ func New(baseTransmission float64) *Simulation {
 		hospitalCapacity:            50,
 		deathRateOverloadMultiplier: 2.0,
 		currentInfected:             10,
-		rng:                         rand.New(rand.NewSource(time.Now().UnixNano())),
+		tickInterval:                time.Second,
+		speedModifier:               1.0,
+		vaccineEfficacy:             1.0,
+		quarantineEffectiveness:     1.0,
+		careBonus:                   1.0,
+		logger:                      log.Default(),
+		infectiousPeriod:            defaultInfectiousPeriod,
+		daysPerTick:                 defaultDaysPerTick,
+		contactBase:                 defaultContactBase,
+		contactsPerInfected:         defaultContactsPerInfected,
+		burdenWeights:               DefaultBurdenWeights,
+		pathogens: map[string]Profile{
+			DefaultPathogen: {
+				BaseTransmission: baseTransmission,
+				BaseDeathRate:    defaultBaseDeathRate,
+				InfectiousPeriod: defaultInfectiousPeriod,
+			},
+		},
+		activePathogen: DefaultPathogen,
 	}
+	s.setPopulationLocked(defaultPopulation)
+	s.markStartLocked()
+	s.seedLocked(seed)
+	return s
 }
 
 // SetLockdown applies a reduced movement speed when enabled and restores the
@@ -85,6 +310,15 @@ func (s *Simulation) LockdownEnabled() bool {
 	return s.lockdownEnabled
 }
 
+// SpeedModifier returns the movement multiplier for this simulation's
+// agents: 1.0 normally and lower during a lockdown.
+func (s *Simulation) SpeedModifier() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.speedModifier
+}
+
 // UpdateTransmissionModifier records a UI-driven transmission modifier.
 func (s *Simulation) UpdateTransmissionModifier(modifier float64) {
 	s.mu.Lock()
@@ -106,8 +340,10 @@ func (s *Simulation) CurrentTransmissionModifier() float64 {
 	return s.transmissionMod
 }
 
-// InfectionProbability applies the modifier to the base transmission rate and
-// returns a capped probability used in the simulation loop.
+// InfectionProbability applies the intervention factors to the base
+// transmission rate and returns the capped per-contact probability they aim
+// for. With probability smoothing enabled the value in use may still lag
+// behind; see PerContactProbability.
 func (s *Simulation) InfectionProbability() float64 {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -115,15 +351,105 @@ func (s *Simulation) InfectionProbability() float64 {
 	return s.infectionProbabilityLocked()
 }
 
+// PerContactProbability is the chance that a single contact between an
+// infected and a susceptible individual transmits the infection. It is the
+// value the epidemic step draws against once per contact.
+func (s *Simulation) PerContactProbability() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.smoothedProbabilityLocked()
+}
+
+// PerTickInfectionHazard is the chance that an individual with the given
+// number of contacts in one tick is infected by at least one of them:
+// 1 - (1 - p)^contacts, where p is PerContactProbability.
+func (s *Simulation) PerTickInfectionHazard(contacts int) float64 {
+	if contacts <= 0 {
+		return 0
+	}
+	p := s.PerContactProbability()
+	return 1 - math.Pow(1-p, float64(contacts))
+}
+
 // StepPair simulates the chance that one agent infects another during a step.
 func (s *Simulation) StepPair() bool {
-	chance := s.InfectionProbability()
+	chance := s.PerContactProbability()
 	return s.rng.Float64() < chance
 }
 
+// Logger receives the simulation's internal log messages, such as Run's step
+// log and recovered report panics. *log.Logger satisfies it, and so can an
+// adapter onto any other logging stack.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// SetLogger routes the simulation's internal logging to logger, which
+// defaults to the standard logger. Passing nil discards all output, which
+// suits embedders that do their own reporting.
+func (s *Simulation) SetLogger(logger Logger) {
+	if l, ok := logger.(*log.Logger); logger == nil || ok && l == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger = logger
+}
+
+func (s *Simulation) currentLogger() Logger {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.logger
+}
+
+// SetTickInterval changes how often Run advances the epidemic. Intervals below
+// MinTickInterval are rejected and leave the current interval unchanged.
+func (s *Simulation) SetTickInterval(interval time.Duration) error {
+	if interval < MinTickInterval {
+		return fmt.Errorf("%w: %v < %v", ErrTickIntervalTooShort, interval, MinTickInterval)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tickInterval = interval
+	return nil
+}
+
+// TickInterval returns the interval Run uses between ticks.
+func (s *Simulation) TickInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tickInterval
+}
+
 // Run executes a simple loop that repeatedly samples infection events and
-// forwards the computed probability back to the caller for monitoring.
+// forwards the computed probability back to the caller for monitoring. The
+// loop starts at interval (raised to MinTickInterval if shorter) and picks up
+// later SetTickInterval changes on the next tick.
 func (s *Simulation) Run(ctx context.Context, interval time.Duration, report func(state Snapshot)) {
+	if interval < MinTickInterval {
+		interval = MinTickInterval
+	}
+	s.mu.Lock()
+	s.tickInterval = interval
+	if report != nil {
+		s.runReporters++
+	}
+	s.mu.Unlock()
+	if report != nil {
+		defer func() {
+			s.mu.Lock()
+			s.runReporters--
+			s.mu.Unlock()
+		}()
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -132,12 +458,17 @@ func (s *Simulation) Run(ctx context.Context, interval time.Duration, report fun
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.stepEpidemic()
-			state := s.Snapshot()
-			if report != nil {
-				report(state)
+			if next := s.TickInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+			state, stop := s.advance()
+			s.checkBreakpoint(state)
+			s.publish(state)
+			if report != nil && !s.invokeReport(report, state) && s.ReportPolicy() == ReportStop {
+				return
 			}
-			log.Printf(
+			s.currentLogger().Printf(
 				"simulation step: modifier=%.2f probability=%.3f infected=%d overloaded=%t death_prob=%.3f",
 				state.TransmissionModifier,
 				state.InfectionProbability,
@@ -145,10 +476,167 @@ func (s *Simulation) Run(ctx context.Context, interval time.Duration, report fun
 				state.Overloaded,
 				state.EffectiveDeathProbability,
 			)
+			if stop {
+				s.currentLogger().Printf("herd immunity reached at tick %d; stopping run", state.Tick)
+				return
+			}
 		}
 	}
 }
 
+// ReportPolicy decides what Run does when its report callback panics.
+type ReportPolicy int
+
+const (
+	// ReportContinue logs the panic and keeps the loop running.
+	ReportContinue ReportPolicy = iota
+	// ReportStop logs the panic and returns from Run.
+	ReportStop
+)
+
+// SetReportPolicy configures how Run reacts to a panicking report callback.
+func (s *Simulation) SetReportPolicy(policy ReportPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reportPolicy = policy
+}
+
+// ReportPolicy returns the configured report callback policy.
+func (s *Simulation) ReportPolicy() ReportPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.reportPolicy
+}
+
+// invokeReport calls report, recovering from a panic so a buggy callback
+// cannot take down the simulation goroutine. It reports whether the callback
+// returned normally.
+func (s *Simulation) invokeReport(report func(Snapshot), state Snapshot) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.currentLogger().Printf("report callback panicked at tick %d: %v", state.Tick, r)
+			ok = false
+		}
+	}()
+
+	report(state)
+	return true
+}
+
+// Pause stops Run from advancing the epidemic. Run keeps reporting snapshots
+// while paused so observers stay in sync, and Step can still advance the model
+// manually.
+func (s *Simulation) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = true
+}
+
+// Resume lets Run advance the epidemic again.
+func (s *Simulation) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = false
+}
+
+// Paused reports whether Run is currently holding the epidemic still.
+func (s *Simulation) Paused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.paused
+}
+
+// Step advances the epidemic by exactly one tick, regardless of the paused
+// flag, and returns the resulting snapshot.
+func (s *Simulation) Step() Snapshot {
+	s.mu.Lock()
+	state := s.stepLocked()
+	s.mu.Unlock()
+
+	s.publish(state)
+	return state
+}
+
+// StepN advances the epidemic by exactly n ticks, as n calls to Step would,
+// and returns the final snapshot. It runs synchronously, without goroutines
+// or timers, so with a fixed seed it gives fast, deterministic tests of the
+// model's dynamics. Non-positive n returns the current snapshot.
+func (s *Simulation) StepN(n int) Snapshot {
+	if n <= 0 {
+		return s.Snapshot()
+	}
+
+	var state Snapshot
+	for i := 0; i < n; i++ {
+		state = s.Step()
+	}
+	return state
+}
+
+// StopReason tells why RunUntil returned.
+type StopReason int
+
+const (
+	// StopConditionMet means the stopping condition held for the final
+	// snapshot.
+	StopConditionMet StopReason = iota
+	// StopCancelled means the context was cancelled first.
+	StopCancelled
+)
+
+// String returns a short name for the reason, for logs and reports.
+func (r StopReason) String() string {
+	if r == StopCancelled {
+		return "cancelled"
+	}
+	return "condition met"
+}
+
+// RunUntil steps the epidemic as fast as it can until condition holds for
+// the latest snapshot, such as "infected below 1" or "tick 10000", or until
+// ctx is cancelled, and returns the final snapshot and the reason it stopped.
+// The current snapshot is checked before any step, and like Step it ignores
+// the pause flag and the tick interval, so headless batch experiments run
+// without a WebSocket or timers.
+func (s *Simulation) RunUntil(ctx context.Context, condition func(Snapshot) bool) (Snapshot, StopReason) {
+	state := s.Snapshot()
+	for !condition(state) {
+		if ctx.Err() != nil {
+			return state, StopCancelled
+		}
+		state = s.Step()
+	}
+	return state, StopConditionMet
+}
+
+// stepLocked advances the epidemic by one tick and records the resulting
+// snapshot in the history. Step and Run both tick through it.
+func (s *Simulation) stepLocked() Snapshot {
+	s.stepEpidemicLocked()
+	state := s.snapshotLocked()
+	s.recordHistoryLocked(state)
+	return state
+}
+
+// advance performs one Run tick: the epidemic steps unless paused. It also
+// reports whether a stop condition fired and Run should return.
+func (s *Simulation) advance() (Snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused {
+		return s.snapshotLocked(), false
+	}
+
+	state := s.stepLocked()
+	return state, s.declineEstablishedLocked()
+}
+
 // SetHospitalCapacity configures the maximum number of concurrent infections
 // that can be treated. Non-positive values disable overload effects.
 func (s *Simulation) SetHospitalCapacity(capacity int) {
@@ -175,18 +663,91 @@ func (s *Simulation) SetDeathRateOverloadMultiplier(multiplier float64) {
 	s.deathRateOverloadMultiplier = sanitizeOverloadMultiplier(multiplier)
 }
 
+// SetInteractionVariance configures the variance of the random multiplier
+// applied to the per-tick contact count. Zero keeps contacts deterministic;
+// larger values make superspreading ticks more likely. Negative and
+// non-finite values are clamped to zero.
+func (s *Simulation) SetInteractionVariance(variance float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.interactionVariance = sanitizeInteractionVariance(variance)
+}
+
+// InteractionVariance returns the configured contact variance.
+func (s *Simulation) InteractionVariance() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.interactionVariance
+}
+
+// SetStrict selects how ApplyControlSettings treats out-of-range input. By
+// default values are clamped into range; in strict mode the whole update is
+// rejected with an ErrOutOfRange error instead.
+func (s *Simulation) SetStrict(strict bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.strict = strict
+}
+
+// Strict reports whether out-of-range control input is rejected.
+func (s *Simulation) Strict() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.strict
+}
+
 // ApplyControlSettings atomically updates all UI-driven parameters and returns
-// a fresh snapshot reflecting the applied state.
-func (s *Simulation) ApplyControlSettings(settings ControlSettings) Snapshot {
+// a fresh snapshot reflecting the applied state, along with a warning for
+// each out-of-range value that was clamped. In strict mode an out-of-range
+// value rejects the update instead and nothing is applied.
+func (s *Simulation) ApplyControlSettings(settings ControlSettings) (Snapshot, []ClampWarning, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if settings.ExpectedVersion != nil && *settings.ExpectedVersion != s.version {
+		return s.snapshotLocked(), nil, fmt.Errorf("%w: update based on version %d, current version is %d",
+			ErrVersionConflict, *settings.ExpectedVersion, s.version)
+	}
+
+	if s.strict {
+		if err := validateControlSettings(settings); err != nil {
+			return s.snapshotLocked(), nil, err
+		}
+	}
+	if settings.Population != nil {
+		if err := s.checkPopulationLocked(*settings.Population); err != nil {
+			return s.snapshotLocked(), nil, err
+		}
+	}
+	warnings := clampWarnings(settings)
+
 	s.applyTransmissionModifierLocked(settings.TransmissionModifier)
 	s.applyLockdownLocked(settings.LockdownEnabled)
 	s.hospitalCapacity = sanitizeCapacity(settings.HospitalCapacity)
 	s.deathRateOverloadMultiplier = sanitizeOverloadMultiplier(settings.DeathRateOverloadMultiplier)
+	if settings.InteractionVariance != nil {
+		s.interactionVariance = sanitizeInteractionVariance(*settings.InteractionVariance)
+	}
+	if settings.TickInterval != nil {
+		s.tickInterval = max(*settings.TickInterval, MinControlTickInterval)
+	}
+	if settings.ImportRate != nil {
+		s.importRate = sanitizeImportRate(*settings.ImportRate)
+	}
+	if settings.InfectionRadius != nil {
+		s.applyInfectionRadiusLocked(*settings.InfectionRadius)
+	}
+	if settings.Population != nil {
+		s.setPopulationLocked(*settings.Population)
+		s.start.population = s.population
+	}
+	s.version++
 
-	return s.snapshotLocked()
+	return s.snapshotLocked(), warnings, nil
 }
 
 // DeathRateOverloadMultiplier returns the overload multiplier.
@@ -234,48 +795,203 @@ func (s *Simulation) Snapshot() Snapshot {
 
 func (s *Simulation) snapshotLocked() Snapshot {
 	deathProb, overloaded := s.deathProbabilityLocked()
+	factors := s.interventionFactorsLocked()
 	capacityUtilization := 0.0
 	if s.hospitalCapacity > 0 {
 		capacityUtilization = float64(s.currentInfected) / float64(s.hospitalCapacity)
 	}
 	return Snapshot{
+		Tick:                        s.tick,
+		SimulatedDay:                s.simulatedDays,
+		BaseTransmission:            s.baseTransmission,
 		TransmissionModifier:        s.currentTransmissionModifierLocked(),
 		InfectionProbability:        s.infectionProbabilityLocked(),
+		SmoothedProbability:         s.smoothedProbabilityLocked(),
 		LockdownEnabled:             s.lockdownEnabled,
-		SpeedModifier:               SpeedModifier(),
+		SpeedModifier:               s.speedModifier,
+		TickIntervalMs:              s.tickInterval.Milliseconds(),
 		HospitalCapacity:            s.hospitalCapacity,
 		DeathRateOverloadMultiplier: s.deathRateOverloadMultiplier,
 		CurrentInfected:             s.currentInfected,
+		CurrentExposed:              s.currentExposed,
+		CurrentRecovered:            s.currentRecovered,
+		CurrentImmune:               s.currentImmune,
+		CurrentVaccinated:           s.currentVaccinated,
+		CurrentSusceptible:          s.currentSusceptible,
+		Population:                  s.population,
+		InitialImmune:               s.start.immune,
+		Traced:                      s.traced,
+		Isolated:                    s.isolated,
+		Quarantined:                 s.quarantinedLocked(),
+		CurrentQuarantined:          s.currentQuarantined,
+		Generation:                  s.generation,
+		Paused:                      s.paused,
 		EffectiveDeathProbability:   deathProb,
+		EffectiveRecoveryRate:       s.recoveryProbabilityLocked(),
 		Overloaded:                  overloaded,
 		CapacityUtilization:         capacityUtilization,
+		InteractionVariance:         s.interactionVariance,
+		Waned:                       s.waned,
+		Reinfections:                s.reinfections,
+		Contacts:                    s.contacts,
+		ImportRate:                  s.importRate,
+		InfectionRadius:             s.infectionRadius,
+		Imported:                    s.imported,
+		ScheduledDeaths:             s.scheduledDeathsLocked(),
+		ActivePathogen:              s.activePathogen,
+		TotalDeaths:                 s.totalDeaths,
+		TotalInfections:             s.totalInfections,
+		TotalRecoveries:             s.totalRecoveries,
+		EffectiveR:                  s.effectiveR,
+		DoublingTime:                s.doublingTimeLocked(),
+		Burden:                      s.burden,
+		VoluntaryReduction:          s.voluntaryReductionLocked(),
+		SeedPhrase:                  s.seedPhrase,
+		StateVersion:                s.version,
+		LockdownFactor:              factors.lockdown,
+		BehaviorFactor:              factors.behavior,
+		CombinedFactor:              factors.combined,
+		SecondaryMean:               s.offspring.mean,
+		SecondaryVariance:           s.offspring.variance,
+		SecondaryMax:                s.offspring.max,
+		CoreSpreaderFraction:        s.offspring.coreFraction,
+		Regions:                     s.regionSnapshotsLocked(),
+		Variants:                    s.variantSnapshotsLocked(),
+		AgeBrackets:                 s.ageSnapshotsLocked(),
 	}
 }
 
 func (s *Simulation) infectionProbabilityLocked() float64 {
-	modifier := s.currentTransmissionModifierLocked()
-	probability := s.baseTransmission * modifier
+	probability := s.baseTransmission * s.interventionFactorsLocked().combined *
+		s.variantMultiplierLocked(transmissionMultiplier)
 	return math.Min(probability, 1.0)
 }
 
 func (s *Simulation) applyTransmissionModifierLocked(modifier float64) {
-	if modifier < 0 {
-		modifier = 0
-	} else if modifier > 1 {
-		modifier = 1
-	}
-
-	s.transmissionMod = modifier
+	s.transmissionMod = sanitizeTransmissionModifier(modifier)
 	s.modifierSet = true
 }
 
 func (s *Simulation) applyLockdownLocked(enabled bool) {
 	s.lockdownEnabled = enabled
+	s.speedModifier = 1.0
 	if enabled {
-		SetCurrentSpeedModifier(0.1)
-	} else {
-		SetCurrentSpeedModifier(1.0)
+		s.speedModifier = lockdownSpeedModifier
+	}
+	// Keep the deprecated package-level modifier in step for old callers.
+	SetCurrentSpeedModifier(s.speedModifier)
+}
+
+// FieldError describes one invalid control value. Field is the path of the
+// matching ControlUpdate field, such as "hospital.capacity".
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every out-of-range value in a rejected update so a UI
+// can flag all of them at once. It matches ErrOutOfRange with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Reason
 	}
+	return fmt.Sprintf("%v: %s", ErrOutOfRange, strings.Join(reasons, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrOutOfRange
+}
+
+// ClampWarning reports a control value that was out of range and clamped
+// rather than rejected. Field uses the same paths as FieldError; tick
+// intervals are given in milliseconds.
+type ClampWarning struct {
+	Field     string  `json:"field"`
+	Requested float64 `json:"requested"`
+	Applied   float64 `json:"applied"`
+}
+
+// clampWarnings lists the values in settings that the sanitizers will change.
+func clampWarnings(settings ControlSettings) []ClampWarning {
+	var warnings []ClampWarning
+	check := func(field string, requested, applied float64) {
+		if requested != applied {
+			warnings = append(warnings, ClampWarning{Field: field, Requested: requested, Applied: applied})
+		}
+	}
+
+	check("transmission_rate", settings.TransmissionModifier, sanitizeTransmissionModifier(settings.TransmissionModifier))
+	check("hospital.capacity", float64(settings.HospitalCapacity), float64(sanitizeCapacity(settings.HospitalCapacity)))
+	check("hospital.death_rate_overload_multiplier", settings.DeathRateOverloadMultiplier,
+		sanitizeOverloadMultiplier(settings.DeathRateOverloadMultiplier))
+	if settings.InteractionVariance != nil {
+		check("interaction_variance", *settings.InteractionVariance, sanitizeInteractionVariance(*settings.InteractionVariance))
+	}
+	if settings.TickInterval != nil {
+		applied := max(*settings.TickInterval, MinControlTickInterval)
+		check("tick_interval_ms", float64(settings.TickInterval.Milliseconds()), float64(applied.Milliseconds()))
+	}
+	if settings.ImportRate != nil {
+		check("import_rate", *settings.ImportRate, sanitizeImportRate(*settings.ImportRate))
+	}
+	if settings.InfectionRadius != nil {
+		check("infection_radius", *settings.InfectionRadius, sanitizeRadius(*settings.InfectionRadius))
+	}
+	return warnings
+}
+
+// validateControlSettings collects every out-of-range value in settings and
+// returns them as a *ValidationError, or nil if all values are in range.
+func validateControlSettings(settings ControlSettings) error {
+	var fields []FieldError
+	if settings.TransmissionModifier < 0 || settings.TransmissionModifier > 1 {
+		fields = append(fields, FieldError{"transmission_rate",
+			fmt.Sprintf("transmission modifier %v not in [0, 1]", settings.TransmissionModifier)})
+	}
+	if settings.HospitalCapacity < 0 {
+		fields = append(fields, FieldError{"hospital.capacity",
+			fmt.Sprintf("hospital capacity %d is negative", settings.HospitalCapacity)})
+	}
+	if settings.DeathRateOverloadMultiplier < 1 {
+		fields = append(fields, FieldError{"hospital.death_rate_overload_multiplier",
+			fmt.Sprintf("overload multiplier %v is below 1", settings.DeathRateOverloadMultiplier)})
+	}
+	if settings.InteractionVariance != nil &&
+		sanitizeInteractionVariance(*settings.InteractionVariance) != *settings.InteractionVariance {
+		fields = append(fields, FieldError{"interaction_variance",
+			fmt.Sprintf("interaction variance %v is not a finite, non-negative number", *settings.InteractionVariance)})
+	}
+	if settings.TickInterval != nil && *settings.TickInterval < MinControlTickInterval {
+		fields = append(fields, FieldError{"tick_interval_ms",
+			fmt.Sprintf("tick interval %v is below %v", *settings.TickInterval, MinControlTickInterval)})
+	}
+	if settings.ImportRate != nil && sanitizeImportRate(*settings.ImportRate) != *settings.ImportRate {
+		fields = append(fields, FieldError{"import_rate",
+			fmt.Sprintf("import rate %v is not a finite, non-negative number", *settings.ImportRate)})
+	}
+	if settings.InfectionRadius != nil && *settings.InfectionRadius != 0 &&
+		sanitizeRadius(*settings.InfectionRadius) != *settings.InfectionRadius {
+		fields = append(fields, FieldError{"infection_radius",
+			fmt.Sprintf("infection radius %v is not a finite, non-negative number", *settings.InfectionRadius)})
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func sanitizeTransmissionModifier(modifier float64) float64 {
+	if modifier < 0 {
+		return 0
+	} else if modifier > 1 {
+		return 1
+	}
+	return modifier
 }
 
 func sanitizeCapacity(capacity int) int {
@@ -292,6 +1008,15 @@ func sanitizeOverloadMultiplier(multiplier float64) float64 {
 	return multiplier
 }
 
+// sanitizeInteractionVariance turns negative and non-finite variances into 0,
+// which keeps contacts deterministic.
+func sanitizeInteractionVariance(variance float64) float64 {
+	if !(variance > 0) || math.IsInf(variance, 1) {
+		return 0
+	}
+	return variance
+}
+
 func (s *Simulation) currentTransmissionModifierLocked() float64 {
 	if !s.modifierSet {
 		return 1.0
@@ -300,8 +1025,27 @@ func (s *Simulation) currentTransmissionModifierLocked() float64 {
 }
 
 func (s *Simulation) deathProbabilityLocked() (float64, bool) {
-	overloaded := s.hospitalCapacity > 0 && s.currentInfected > s.hospitalCapacity
+	return s.deathProbabilityAtLocked(s.ageMultiplierLocked())
+}
+
+// deathProbabilityAtLocked is the per-tick death probability for infected
+// people whose age scales their risk by ageMultiplier.
+func (s *Simulation) deathProbabilityAtLocked(ageMultiplier float64) (float64, bool) {
+	probability, overloaded := s.deathProbabilityForLocked(s.currentInfected, s.hospitalCapacity)
+	if multiplier := ageMultiplier * s.variantMultiplierLocked(deathMultiplier); multiplier != 1 {
+		probability = math.Min(probability*multiplier, 1.0)
+	}
+	return probability, overloaded
+}
+
+// deathProbabilityForLocked is the per-tick death probability for infected
+// people sharing a hospital of the given capacity.
+func (s *Simulation) deathProbabilityForLocked(infected, capacity int) (float64, bool) {
+	overloaded := capacity > 0 && infected > capacity
 	probability := s.baseDeathRate
+	if s.transitions != nil {
+		probability = s.transitions[Infectious][Dead]
+	}
 	if overloaded {
 		probability *= s.deathRateOverloadMultiplier
 	}
@@ -314,27 +1058,87 @@ func (s *Simulation) stepEpidemic() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	infectionProbability := s.infectionProbabilityLocked()
-	interactions := 5 + s.currentInfected/3
+	s.stepEpidemicLocked()
+}
+
+// varyContactsLocked applies the contact variance to a tick's contact count.
+func (s *Simulation) varyContactsLocked(interactions int) int {
+	if s.interactionVariance <= 0 {
+		return interactions
+	}
+	// Gamma-distributed multiplier with mean 1 and the configured variance.
+	multiplier := gammaSample(s.rng, 1/s.interactionVariance, s.interactionVariance)
+	return int(math.Round(float64(interactions) * multiplier))
+}
+
+func (s *Simulation) stepEpidemicLocked() {
+	s.tick++
+	s.simulatedDays += s.daysPerTickLocked()
+	s.applyTimelineLocked()
+	imported := s.applyImportsLocked()
+	if len(s.regions) > 0 {
+		s.stepRegionsLocked(imported)
+		return
+	}
+	if s.spatialLocked() {
+		s.stepAgentsLocked(imported)
+		return
+	}
+	s.waneImmunityLocked()
+	s.vaccinateLocked()
+	progressed := s.progressExposedLocked()
+	s.detectInfectiousLocked()
+
+	// Only contacts with susceptible people can transmit.
+	infectionProbability := s.advanceSmoothedProbabilityLocked() * s.susceptibleFractionLocked()
+	transmitting := s.transmittingLocked()
+	interactions := s.varyContactsLocked(s.contactsLocked(transmitting))
+	s.contacts = interactions
 	newInfections := 0
-	for i := 0; i < interactions; i++ {
-		if s.rng.Float64() < infectionProbability {
-			newInfections++
-		}
+	if s.dispersion > 0 {
+		mean := float64(interactions) * infectionProbability / float64(max(transmitting, 1))
+		newInfections = s.drawOffspringLocked(transmitting, mean)
+	} else {
+		newInfections = s.binomialLocked(interactions, infectionProbability)
 	}
 
-	s.currentInfected += newInfections
+	newInfections = s.infectSusceptiblesLocked(newInfections)
 
-	deathProbability, _ := s.deathProbabilityLocked()
-	deaths := 0
-	for i := 0; i < s.currentInfected; i++ {
-		if s.rng.Float64() < deathProbability {
-			deaths++
-		}
+	s.updateEffectiveRLocked(s.currentInfected, newInfections)
+	s.totalInfections += newInfections + imported
+	// Outcomes are scheduled from the tick an infection becomes infectious.
+	becameInfectious := progressed + imported
+	exposes := s.incubationPeriod > 0
+	if s.transitions != nil {
+		exposes = s.matrixExposesLocked()
+	}
+	if exposes {
+		s.currentExposed += newInfections
+	} else {
+		s.currentInfected += newInfections
+		becameInfectious += newInfections
 	}
+	s.traceContactsLocked(interactions, becameInfectious-imported)
+	s.reconcileVariantsLocked(0)
+	s.reconcileAgesLocked(0)
 
-	s.currentInfected -= deaths
-	if s.currentInfected < 0 {
-		s.currentInfected = 0
+	deathsBefore, infectedBefore := s.totalDeaths, s.currentInfected
+	if s.transitions != nil {
+		s.applyTransitionsLocked()
+	} else if s.outcomeModel == OutcomeScheduled {
+		s.resolveScheduledOutcomesLocked()
+		s.scheduleOutcomesLocked(becameInfectious)
+	} else {
+		deaths, recoveries := s.resolveMemorylessLocked()
+		s.currentInfected -= deaths + recoveries
+		s.totalDeaths += deaths
+		s.totalRecoveries += recoveries
+		s.currentRecovered += recoveries
 	}
+	s.settleIsolationLocked(infectedBefore)
+	s.settleQuarantineLocked(infectedBefore)
+	s.reconcileVariantsLocked(s.totalDeaths - deathsBefore)
+	s.reconcileAgesLocked(s.totalDeaths - deathsBefore)
+
+	s.accrueBurdenLocked(s.totalDeaths - deathsBefore)
 }
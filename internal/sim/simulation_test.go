@@ -1,7 +1,14 @@
 package sim
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -89,15 +96,11 @@ func TestOverloadBoostsDeathProbability(t *testing.T) {
 
 func TestLockdownTogglesSpeedModifier(t *testing.T) {
 	s := New(0.2)
-	t.Cleanup(func() {
-		SetCurrentSpeedModifier(1.0)
-	})
-
 	s.SetLockdown(true)
 	if !s.LockdownEnabled() {
 		t.Fatal("expected lockdown to be enabled")
 	}
-	if got := SpeedModifier(); got != 0.1 {
+	if got := s.SpeedModifier(); got != 0.1 {
 		t.Fatalf("expected speed modifier to drop to 0.1, got %v", got)
 	}
 
@@ -105,23 +108,22 @@ func TestLockdownTogglesSpeedModifier(t *testing.T) {
 	if s.LockdownEnabled() {
 		t.Fatal("expected lockdown to be disabled")
 	}
-	if got := SpeedModifier(); got != 1.0 {
+	if got := s.SpeedModifier(); got != 1.0 {
 		t.Fatalf("expected speed modifier to reset to 1.0, got %v", got)
 	}
 }
 
 func TestApplyControlSettings(t *testing.T) {
 	s := New(0.3)
-	t.Cleanup(func() {
-		SetCurrentSpeedModifier(1.0)
-	})
-
-	snapshot := s.ApplyControlSettings(ControlSettings{
+	snapshot, warnings, err := s.ApplyControlSettings(ControlSettings{
 		TransmissionModifier:        0.75,
 		LockdownEnabled:             true,
 		HospitalCapacity:            -5,
 		DeathRateOverloadMultiplier: 0.5,
 	})
+	if err != nil {
+		t.Fatalf("expected clamping mode to accept the update, got %v", err)
+	}
 
 	if snapshot.TransmissionModifier != 0.75 {
 		t.Fatalf("expected transmission modifier 0.75, got %v", snapshot.TransmissionModifier)
@@ -135,27 +137,486 @@ func TestApplyControlSettings(t *testing.T) {
 	if snapshot.DeathRateOverloadMultiplier != 1 {
 		t.Fatalf("expected overload multiplier to clamp to 1, got %v", snapshot.DeathRateOverloadMultiplier)
 	}
-	if SpeedModifier() != 0.1 {
-		t.Fatalf("expected lockdown to adjust speed modifier to 0.1, got %v", SpeedModifier())
+	if s.SpeedModifier() != 0.1 {
+		t.Fatalf("expected lockdown to adjust speed modifier to 0.1, got %v", s.SpeedModifier())
+	}
+
+	want := []ClampWarning{
+		{Field: "hospital.capacity", Requested: -5, Applied: 0},
+		{Field: "hospital.death_rate_overload_multiplier", Requested: 0.5, Applied: 1},
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Fatalf("expected clamp warnings %+v, got %+v", want, warnings)
 	}
 }
 
-func TestSnapshotIncludesIndicators(t *testing.T) {
+func TestApplyControlSettingsWarnsOnlyWhenClamping(t *testing.T) {
 	s := New(0.3)
-	t.Cleanup(func() {
-		SetCurrentSpeedModifier(1.0)
+	interval := time.Millisecond
+	_, warnings, err := s.ApplyControlSettings(ControlSettings{
+		TransmissionModifier:        0.5,
+		HospitalCapacity:            10,
+		DeathRateOverloadMultiplier: 2,
+		TickInterval:                &interval,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ClampWarning{{
+		Field:     "tick_interval_ms",
+		Requested: 1,
+		Applied:   float64(MinControlTickInterval.Milliseconds()),
+	}}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Fatalf("expected clamp warnings %+v, got %+v", want, warnings)
+	}
+
+	_, warnings, err = s.ApplyControlSettings(ControlSettings{TransmissionModifier: 0.5, DeathRateOverloadMultiplier: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings for in-range settings, got %+v", warnings)
+	}
+}
+
+func TestApplyControlSettingsStrictRejectsOutOfRange(t *testing.T) {
+	outOfRange := ControlSettings{
+		TransmissionModifier:        0.75,
+		HospitalCapacity:            -5,
+		DeathRateOverloadMultiplier: 2,
+	}
+
+	lenient := New(0.3)
+	snapshot, _, err := lenient.ApplyControlSettings(outOfRange)
+	if err != nil {
+		t.Fatalf("expected clamping mode to accept the update, got %v", err)
+	}
+	if snapshot.HospitalCapacity != 0 {
+		t.Fatalf("expected capacity clamped to 0, got %d", snapshot.HospitalCapacity)
+	}
+
+	strict := New(0.3)
+	strict.SetStrict(true)
+	snapshot, _, err = strict.ApplyControlSettings(outOfRange)
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("expected ErrOutOfRange in strict mode, got %v", err)
+	}
+	if snapshot.HospitalCapacity != 50 || snapshot.TransmissionModifier != 1 {
+		t.Fatalf("expected rejected update to leave settings untouched, got capacity=%d modifier=%v",
+			snapshot.HospitalCapacity, snapshot.TransmissionModifier)
+	}
+}
+
+func TestStrictValidationReportsEveryBadField(t *testing.T) {
+	variance := -1.0
+	s := New(0.3)
+	s.SetStrict(true)
+
+	_, _, err := s.ApplyControlSettings(ControlSettings{
+		TransmissionModifier:        1.5,
+		HospitalCapacity:            -5,
+		DeathRateOverloadMultiplier: 0.5,
+		InteractionVariance:         &variance,
+	})
+
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	var fields []string
+	for _, field := range validation.Fields {
+		fields = append(fields, field.Field)
+	}
+	want := []string{"transmission_rate", "hospital.capacity", "hospital.death_rate_overload_multiplier", "interaction_variance"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("expected fields %v, got %v", want, fields)
+	}
+}
+
+func TestNonFiniteInteractionVarianceIsRejectedOrClamped(t *testing.T) {
+	for _, variance := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		strict := New(0.3)
+		strict.SetStrict(true)
+		_, _, err := strict.ApplyControlSettings(ControlSettings{
+			TransmissionModifier: 1, HospitalCapacity: 50, DeathRateOverloadMultiplier: 2, InteractionVariance: &variance,
+		})
+		if !errors.Is(err, ErrOutOfRange) {
+			t.Fatalf("variance %v: expected strict mode to reject it, got %v", variance, err)
+		}
+
+		s := NewWithSeed(0.3, 1)
+		state, warnings, err := s.ApplyControlSettings(ControlSettings{
+			TransmissionModifier: 1, HospitalCapacity: 50, DeathRateOverloadMultiplier: 2, InteractionVariance: &variance,
+		})
+		if err != nil {
+			t.Fatalf("variance %v: %v", variance, err)
+		}
+		if state.InteractionVariance != 0 || len(warnings) != 1 || warnings[0].Field != "interaction_variance" {
+			t.Fatalf("variance %v: expected a clamp to 0 with a warning, got %v and %+v", variance, state.InteractionVariance, warnings)
+		}
+		if contacts := s.Step().Contacts; contacts < 0 {
+			t.Fatalf("variance %v: expected a non-negative contact count, got %d", variance, contacts)
+		}
+	}
+}
+
+func TestApplyControlSettingsRejectsStaleVersion(t *testing.T) {
+	s := New(0.3)
+	seen := s.Snapshot().StateVersion
+
+	first, _, err := s.ApplyControlSettings(ControlSettings{
+		TransmissionModifier: 0.5, HospitalCapacity: 40, DeathRateOverloadMultiplier: 2, ExpectedVersion: &seen,
+	})
+	if err != nil {
+		t.Fatalf("expected the first writer to succeed, got %v", err)
+	}
+	if first.StateVersion == seen {
+		t.Fatal("expected the applied update to advance the state version")
+	}
+
+	_, _, err = s.ApplyControlSettings(ControlSettings{
+		TransmissionModifier: 0.9, HospitalCapacity: 10, DeathRateOverloadMultiplier: 2, ExpectedVersion: &seen,
 	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict for a stale writer, got %v", err)
+	}
+	if got := s.CurrentTransmissionModifier(); got != 0.5 {
+		t.Fatalf("expected the first writer's modifier 0.5 to survive, got %v", got)
+	}
+
+	if _, _, err := s.ApplyControlSettings(ControlSettings{TransmissionModifier: 0.9, DeathRateOverloadMultiplier: 2}); err != nil {
+		t.Fatalf("expected an unversioned update to keep last-writer-wins, got %v", err)
+	}
+}
+
+func TestLockdownSpeedIsPerSimulation(t *testing.T) {
+	locked, open := New(0.2), New(0.2)
+	locked.SetLockdown(true)
 
+	if got := open.SpeedModifier(); got != 1.0 {
+		t.Fatalf("expected another simulation's lockdown to leave the speed at 1.0, got %v", got)
+	}
+	if got := open.Snapshot().SpeedModifier; got != 1.0 {
+		t.Fatalf("expected the snapshot speed to stay at 1.0, got %v", got)
+	}
+	if got := locked.SpeedModifier(); got != 0.1 {
+		t.Fatalf("expected the locked-down simulation at 0.1, got %v", got)
+	}
+}
+
+func TestSnapshotIncludesIndicators(t *testing.T) {
+	s := New(0.3)
 	s.currentInfected = 25
 	s.hospitalCapacity = 50
 	s.SetLockdown(true)
 
 	snap := s.Snapshot()
-	if snap.SpeedModifier != SpeedModifier() {
-		t.Fatalf("expected speed modifier %v, got %v", SpeedModifier(), snap.SpeedModifier)
+	if snap.SpeedModifier != s.SpeedModifier() {
+		t.Fatalf("expected speed modifier %v, got %v", s.SpeedModifier(), snap.SpeedModifier)
 	}
 	expectedUtilization := 0.5
 	if snap.CapacityUtilization != expectedUtilization {
 		t.Fatalf("expected capacity utilization %.2f, got %.2f", expectedUtilization, snap.CapacityUtilization)
 	}
 }
+
+func TestSetTickIntervalRejectsBelowMinimum(t *testing.T) {
+	s := New(0.2)
+	if err := s.SetTickInterval(50 * time.Millisecond); err != nil {
+		t.Fatalf("expected 50ms interval to be accepted, got %v", err)
+	}
+
+	err := s.SetTickInterval(MinTickInterval / 10)
+	if !errors.Is(err, ErrTickIntervalTooShort) {
+		t.Fatalf("expected ErrTickIntervalTooShort, got %v", err)
+	}
+	if got := s.TickInterval(); got != 50*time.Millisecond {
+		t.Fatalf("expected interval to stay at 50ms, got %v", got)
+	}
+}
+
+func TestRunClampsIntervalToMinimum(t *testing.T) {
+	s := New(0.2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reported := make(chan Snapshot, 1)
+
+	go s.Run(ctx, 0, func(state Snapshot) {
+		select {
+		case reported <- state:
+		default:
+		}
+		cancel()
+	})
+
+	select {
+	case <-reported:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timed out waiting for report")
+	}
+	if got := s.TickInterval(); got != MinTickInterval {
+		t.Fatalf("expected interval clamped to %v, got %v", MinTickInterval, got)
+	}
+}
+
+func TestSetLoggerRedirectsRunOutput(t *testing.T) {
+	var defaultOutput, customOutput bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&defaultOutput)
+	t.Cleanup(func() {
+		log.SetOutput(previous)
+	})
+
+	s := New(0.2)
+	s.SetLogger(log.New(&customOutput, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, 5*time.Millisecond, func(Snapshot) { cancel() })
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timed out waiting for run to stop")
+	}
+
+	if defaultOutput.Len() != 0 {
+		t.Fatalf("expected no output on the default logger, got %q", defaultOutput.String())
+	}
+	if customOutput.Len() == 0 {
+		t.Fatal("expected the custom logger to receive the step log")
+	}
+}
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...any) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestSetLoggerAcceptsLoggerInterface(t *testing.T) {
+	s := New(0.2)
+	logger := &recordingLogger{}
+	s.SetLogger(logger)
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(context.Background(), 5*time.Millisecond, func(Snapshot) {
+			s.SetReportPolicy(ReportStop)
+			panic("buggy callback")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("expected Run to return after the callback panicked")
+	}
+
+	if len(logger.messages) == 0 || !strings.Contains(logger.messages[len(logger.messages)-1], "buggy callback") {
+		t.Fatalf("expected the recovered panic to reach the logger, got %q", logger.messages)
+	}
+	if !s.ActiveHooks().CustomLogger {
+		t.Fatal("expected the recording logger to count as a custom logger")
+	}
+}
+
+func TestPausedRunReportsWithoutStepping(t *testing.T) {
+	s := New(0.2)
+	s.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reported := make(chan Snapshot, 1)
+
+	go s.Run(ctx, 5*time.Millisecond, func(state Snapshot) {
+		select {
+		case reported <- state:
+		default:
+		}
+		cancel()
+	})
+
+	select {
+	case state := <-reported:
+		if state.Tick != 0 {
+			t.Fatalf("expected paused run to stay at tick 0, got %d", state.Tick)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timed out waiting for heartbeat report")
+	}
+
+	if got := s.Step().Tick; got != 1 {
+		t.Fatalf("expected manual step to reach tick 1, got %d", got)
+	}
+}
+
+func TestStepNMatchesRepeatedStep(t *testing.T) {
+	stepped := NewWithSeed(0.3, 7)
+	batched := NewWithSeed(0.3, 7)
+
+	var want Snapshot
+	for i := 0; i < 25; i++ {
+		want = stepped.Step()
+	}
+	if got := batched.StepN(25); !reflect.DeepEqual(want, got) {
+		t.Fatalf("StepN(25) = %+v, want %+v", got, want)
+	}
+	if got := len(batched.History()); got != 25 {
+		t.Fatalf("expected 25 history entries, got %d", got)
+	}
+
+	if got := batched.StepN(0); got.Tick != 25 {
+		t.Fatalf("expected StepN(0) to stay at tick 25, got %d", got.Tick)
+	}
+}
+
+func TestRunUntilStopsWhenConditionHolds(t *testing.T) {
+	s := NewWithSeed(0.3, 7)
+
+	state, reason := s.RunUntil(context.Background(), func(state Snapshot) bool {
+		return state.Tick >= 40
+	})
+	if reason != StopConditionMet {
+		t.Fatalf("expected %v, got %v", StopConditionMet, reason)
+	}
+	if state.Tick != 40 {
+		t.Fatalf("expected to stop at tick 40, got %d", state.Tick)
+	}
+
+	state, reason = s.RunUntil(context.Background(), func(Snapshot) bool { return true })
+	if reason != StopConditionMet || state.Tick != 40 {
+		t.Fatalf("expected a condition that already holds not to step, got tick %d and %v", state.Tick, reason)
+	}
+}
+
+func TestRunUntilStopsOnCancel(t *testing.T) {
+	s := NewWithSeed(0.3, 7)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	state, reason := s.RunUntil(ctx, func(state Snapshot) bool {
+		if state.Tick == 10 {
+			cancel()
+		}
+		return false
+	})
+	if reason != StopCancelled {
+		t.Fatalf("expected %v, got %v", StopCancelled, reason)
+	}
+	if state.Tick != 10 {
+		t.Fatalf("expected to stop at tick 10, got %d", state.Tick)
+	}
+}
+
+func TestRunSurvivesPanickingReportByDefault(t *testing.T) {
+	s := New(0.2)
+	s.SetLogger(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := make(chan int, 3)
+	count := 0
+	go s.Run(ctx, 5*time.Millisecond, func(Snapshot) {
+		count++
+		calls <- count
+		if count >= 3 {
+			cancel()
+		}
+		panic("buggy callback")
+	})
+
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-calls:
+			if got != want {
+				t.Fatalf("expected call %d, got %d", want, got)
+			}
+		case <-time.After(200 * time.Millisecond):
+			t.Fatalf("loop stopped after %d panicking callbacks", want-1)
+		}
+	}
+}
+
+func TestRunStopsOnPanickingReportWithStopPolicy(t *testing.T) {
+	s := New(0.2)
+	s.SetLogger(nil)
+	s.SetReportPolicy(ReportStop)
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(context.Background(), 5*time.Millisecond, func(Snapshot) {
+			panic("buggy callback")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("expected Run to return after the callback panicked")
+	}
+	if got := s.Snapshot().Tick; got != 1 {
+		t.Fatalf("expected the loop to stop after one tick, got %d", got)
+	}
+}
+
+func TestPerTickInfectionHazard(t *testing.T) {
+	s := New(0.2)
+
+	if got := s.PerContactProbability(); got != 0.2 {
+		t.Fatalf("expected per-contact probability 0.2, got %v", got)
+	}
+	cases := []struct {
+		contacts int
+		want     float64
+	}{
+		{contacts: 0, want: 0},
+		{contacts: 1, want: 0.2},
+		{contacts: 2, want: 0.36},
+		{contacts: 5, want: 1 - math.Pow(0.8, 5)},
+	}
+	for _, tc := range cases {
+		if got := s.PerTickInfectionHazard(tc.contacts); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("hazard with %d contacts = %v, want %v", tc.contacts, got, tc.want)
+		}
+	}
+}
+
+// newBenchmarkSimulation returns a seeded simulation with a large infected
+// pool so each step exercises the contact and outcome loops.
+func newBenchmarkSimulation() *Simulation {
+	s := New(0.25)
+	s.SetLogger(nil)
+	s.SetSeed(1)
+	s.SetInteractionVariance(0.5)
+	s.currentInfected = 10000
+	return s
+}
+
+func BenchmarkStepEpidemic(b *testing.B) {
+	s := newBenchmarkSimulation()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.Step()
+		// Hold the pool steady so later iterations cost the same as early ones.
+		s.currentInfected = 10000
+	}
+}
+
+func BenchmarkSnapshot(b *testing.B) {
+	s := newBenchmarkSimulation()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.Snapshot()
+	}
+}
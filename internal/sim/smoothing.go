@@ -0,0 +1,48 @@
+package sim
+
+// SetProbabilitySmoothing eases changes in the infection probability in over
+// several ticks, modelling behaviour that adapts gradually after a policy
+// announcement. Each tick the probability actually used moves alpha of the
+// way towards the target set by the transmission modifier and pathogen.
+// Snapshots report the target as InfectionProbability and the value in use as
+// SmoothedProbability. Values of alpha outside (0, 1) disable smoothing,
+// which is the default.
+func (s *Simulation) SetProbabilitySmoothing(alpha float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0
+	}
+	if s.probabilitySmoothing == 0 {
+		s.smoothedProbability = s.infectionProbabilityLocked()
+	}
+	s.probabilitySmoothing = alpha
+}
+
+// ProbabilitySmoothing returns the smoothing factor, or 0 when disabled.
+func (s *Simulation) ProbabilitySmoothing() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.probabilitySmoothing
+}
+
+// advanceSmoothedProbabilityLocked moves the smoothed probability one tick
+// towards the current target and returns the value to use for this tick.
+func (s *Simulation) advanceSmoothedProbabilityLocked() float64 {
+	target := s.infectionProbabilityLocked()
+	if s.probabilitySmoothing == 0 {
+		s.smoothedProbability = target
+	} else {
+		s.smoothedProbability += s.probabilitySmoothing * (target - s.smoothedProbability)
+	}
+	return s.smoothedProbability
+}
+
+func (s *Simulation) smoothedProbabilityLocked() float64 {
+	if s.probabilitySmoothing == 0 {
+		return s.infectionProbabilityLocked()
+	}
+	return s.smoothedProbability
+}
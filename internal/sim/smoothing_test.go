@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSmoothedProbabilityConvergesToNewTarget(t *testing.T) {
+	s := New(0.4)
+	s.SetProbabilitySmoothing(0.5)
+	s.UpdateTransmissionModifier(0.25)
+
+	state := s.Snapshot()
+	if state.InfectionProbability != 0.1 {
+		t.Fatalf("expected target probability 0.1, got %v", state.InfectionProbability)
+	}
+	if state.SmoothedProbability != 0.4 {
+		t.Fatalf("expected smoothed probability to start at the old value 0.4, got %v", state.SmoothedProbability)
+	}
+
+	previous := state.SmoothedProbability
+	for i := 0; i < 10; i++ {
+		got := s.Step().SmoothedProbability
+		if got >= previous || got < 0.1 {
+			t.Fatalf("tick %d: expected smoothed probability to fall from %v towards 0.1, got %v", i+1, previous, got)
+		}
+		previous = got
+	}
+	if math.Abs(previous-0.1) > 1e-3 {
+		t.Fatalf("expected smoothed probability to converge on 0.1, got %v", previous)
+	}
+}
+
+func TestSmoothingDisabledTracksTargetImmediately(t *testing.T) {
+	s := New(0.4)
+	s.UpdateTransmissionModifier(0.5)
+
+	if got := s.Step().SmoothedProbability; got != 0.2 {
+		t.Fatalf("expected unsmoothed probability 0.2, got %v", got)
+	}
+	s.SetProbabilitySmoothing(1.5)
+	if got := s.ProbabilitySmoothing(); got != 0 {
+		t.Fatalf("expected out-of-range alpha to disable smoothing, got %v", got)
+	}
+}
@@ -0,0 +1,228 @@
+package sim
+
+import (
+	"math"
+	"slices"
+)
+
+// agentStepSeconds is how far, in seconds of movement, agents travel each
+// tick in spatial mode.
+const agentStepSeconds = 1.0
+
+// SetInfectionRadius turns on spatial mode once agents have been added: each
+// tick the agents move, and every living, susceptible agent within radius of
+// an infected agent is infected with the per-contact InfectionProbability,
+// once per infected neighbour. Lockdown slows the agents and so cuts the
+// contacts they make. Zero, negative, or non-finite radii turn spatial mode
+// off.
+//
+// In spatial mode the agents are the population: the top-level Snapshot
+// counts are taken from them, and infected agents die or recover on their
+// own with the memoryless outcome probabilities. Neighbours are found with a
+// grid rebuilt every tick, so a tick costs time linear in the agents rather
+// than quadratic. Incubation, contact
+// tracing, a transition matrix, and dispersion do not apply. Regions take
+// precedence over spatial mode.
+func (s *Simulation) SetInfectionRadius(radius float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applyInfectionRadiusLocked(radius)
+}
+
+func (s *Simulation) applyInfectionRadiusLocked(radius float64) {
+	s.infectionRadius = sanitizeRadius(radius)
+	if s.spatialLocked() {
+		s.syncAgentsLocked()
+	}
+}
+
+// sanitizeRadius turns non-positive and non-finite radii into 0, which turns
+// spatial mode off.
+func sanitizeRadius(radius float64) float64 {
+	if radius <= 0 || math.IsNaN(radius) || math.IsInf(radius, 0) {
+		return 0
+	}
+	return radius
+}
+
+// SetWorldBounds confines spatial-mode agents to a width by height world with
+// its corner at the origin; agents bounce off the edges. Agents outside the
+// world are pulled onto its edge on their next move. A zero, negative, or
+// non-finite dimension removes the bounds, letting agents wander freely.
+func (s *Simulation) SetWorldBounds(width, height float64) {
+	if !(width > 0 && height > 0) || math.IsInf(width, 0) || math.IsInf(height, 0) {
+		width, height = 0, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.worldWidth, s.worldHeight = width, height
+}
+
+// WorldBounds returns the world's width and height, or zeros when agents are
+// unbounded.
+func (s *Simulation) WorldBounds() (width, height float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.worldWidth, s.worldHeight
+}
+
+// InfectionRadius returns the spatial infection radius, or 0 when spatial
+// mode is off.
+func (s *Simulation) InfectionRadius() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.infectionRadius
+}
+
+// AddAgent adds an agent to the simulation's space. Mark it Infected to seed
+// an outbreak. Reset and ApplyConfig remove every agent.
+func (s *Simulation) AddAgent(a Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.agents = append(s.agents, a)
+	if s.spatialLocked() {
+		s.syncAgentsLocked()
+	}
+}
+
+// Agents returns a copy of the simulation's agents.
+func (s *Simulation) Agents() []Agent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.agents)
+}
+
+func (s *Simulation) spatialLocked() bool {
+	return s.infectionRadius > 0 && len(s.agents) > 0
+}
+
+// stepAgentsLocked advances spatial mode by one tick. Imported infections
+// have already been added to the top-level counts; they infect susceptible
+// agents picked by importTargetsLocked.
+func (s *Simulation) stepAgentsLocked(imported int) {
+	targets := s.importTargetsLocked(imported)
+	for _, i := range targets {
+		s.agents[i].Infected = true
+	}
+	unplaced := imported - len(targets)
+	for i := range s.agents {
+		a := &s.agents[i]
+		if s.worldWidth > 0 {
+			a.MoveBounded(agentStepSeconds, s.speedModifier, s.worldWidth, s.worldHeight)
+		} else {
+			a.Move(agentStepSeconds, s.speedModifier)
+		}
+	}
+
+	probability := s.advanceSmoothedProbabilityLocked()
+	infectedBefore, deathsBefore := s.currentInfected, s.totalDeaths
+	// Infections take effect after every contact is drawn, so a newly
+	// infected agent does not pass the infection on in the same tick.
+	var sources []int
+	for i := range s.agents {
+		if s.agents[i].Infected && !s.agents[i].Dead {
+			sources = append(sources, i)
+		}
+	}
+	grid := newSpatialGrid(s.agents, s.infectionRadius, (*Agent).susceptible)
+	infections, contacts := s.drawAgentContactsLocked(grid, sources, probability)
+	newInfections := 0
+	for _, j := range infections {
+		if !s.agents[j].Infected {
+			s.agents[j].Infected = true
+			newInfections++
+		}
+	}
+	s.contacts = contacts
+	s.syncAgentsLocked()
+
+	deathProbability, _ := s.deathProbabilityLocked()
+	recoveryProbability := min(deathProbability+s.recoveryProbabilityLocked(), 1)
+	for i := range s.agents {
+		a := &s.agents[i]
+		if !a.Infected || a.Dead {
+			continue
+		}
+		switch draw := s.rng.Float64(); {
+		case draw < deathProbability:
+			a.Infected = false
+			a.Dead = true
+			s.totalDeaths++
+		case draw < recoveryProbability:
+			a.Infected = false
+			a.Recovered = true
+			s.totalRecoveries++
+		}
+	}
+
+	s.updateEffectiveRLocked(infectedBefore, newInfections)
+	s.totalInfections += newInfections + imported - unplaced
+	s.syncAgentsLocked()
+	s.accrueBurdenLocked(s.totalDeaths - deathsBefore)
+}
+
+// importTargetsLocked picks up to count susceptible agents for imported
+// infections, uniformly at random so that no agent index is favoured. It is
+// a partial Fisher-Yates shuffle of the susceptible indices, taking one draw
+// from the simulation's random stream per target; ticks without imports take
+// none.
+func (s *Simulation) importTargetsLocked(count int) []int {
+	if count <= 0 {
+		return nil
+	}
+	var susceptible []int
+	for i := range s.agents {
+		if s.agents[i].susceptible() {
+			susceptible = append(susceptible, i)
+		}
+	}
+	count = min(count, len(susceptible))
+	for i := 0; i < count; i++ {
+		j := i + s.rng.Intn(len(susceptible)-i)
+		susceptible[i], susceptible[j] = susceptible[j], susceptible[i]
+	}
+	return susceptible[:count]
+}
+
+// candidatesLocked appends to buf the agents that could be within the
+// infection radius of agent i, in ascending order. With bruteForceNeighbours
+// set it lists every agent; tests use that as the reference the grid must
+// match.
+func (s *Simulation) candidatesLocked(grid *spatialGrid, i int, buf []int) []int {
+	if s.bruteForceNeighbours {
+		for j := range s.agents {
+			buf = append(buf, j)
+		}
+		return buf
+	}
+	return grid.neighbours(s.agents[i].X, s.agents[i].Y, buf)
+}
+
+// syncAgentsLocked sets the top-level compartments from the agents. Like the
+// single pool, the population still counts the dead.
+func (s *Simulation) syncAgentsLocked() {
+	s.currentExposed, s.currentInfected, s.currentRecovered = 0, 0, 0
+	s.currentImmune, s.currentSusceptible, s.currentVaccinated = 0, 0, 0
+	s.clearWaningLocked()
+	s.population = len(s.agents)
+	for _, a := range s.agents {
+		switch {
+		case a.Dead:
+		case a.Infected:
+			s.currentInfected++
+		case a.Recovered:
+			s.currentRecovered++
+		case a.Immune:
+			s.currentImmune++
+		default:
+			s.currentSusceptible++
+		}
+	}
+}
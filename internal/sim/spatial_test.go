@@ -0,0 +1,203 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func newSpatialSimulation(t *testing.T, seed int64) *Simulation {
+	t.Helper()
+	s := NewWithSeed(0.5, seed)
+	s.SetInfectionRadius(1)
+	return s
+}
+
+func TestSpatialInfectionOnlyReachesNearbyAgents(t *testing.T) {
+	s := newSpatialSimulation(t, 3)
+	s.AddAgent(Agent{X: 0, Y: 0, Infected: true})
+	for i := 0; i < 20; i++ {
+		s.AddAgent(Agent{X: 0.5, Y: 0})
+	}
+	s.AddAgent(Agent{X: 50, Y: 50})
+	s.AddAgent(Agent{X: 0.5, Y: 0.5, Immune: true})
+	s.AddAgent(Agent{X: 0, Y: 0.5, Dead: true})
+
+	if state := s.Snapshot(); state.CurrentInfected != 1 || state.Population != 24 || state.CurrentSusceptible != 21 {
+		t.Fatalf("expected the counts to come from the agents, got %+v", state)
+	}
+
+	state := s.Step()
+	agents := s.Agents()
+	if state.Contacts != 20 {
+		t.Fatalf("expected 20 contacts within the radius, got %d", state.Contacts)
+	}
+	if agents[21].Infected || agents[22].Infected || agents[23].Infected {
+		t.Fatal("expected distant, immune, and dead agents to stay uninfected")
+	}
+	infected := 0
+	for _, a := range agents {
+		if a.Infected {
+			infected++
+		}
+	}
+	if infected != state.CurrentInfected || state.TotalInfections == 0 {
+		t.Fatalf("expected the snapshot to count %d infected agents, got %+v", infected, state)
+	}
+}
+
+func TestLockdownSlowsSpatialSpread(t *testing.T) {
+	run := func(lockdown bool) int {
+		s := newSpatialSimulation(t, 11)
+		s.SetLockdown(lockdown)
+		// Two lines of agents walking towards each other.
+		s.AddAgent(Agent{X: 0, Y: 0, DirectionX: 1, BaseSpeed: 1, Infected: true})
+		for i := 0; i < 30; i++ {
+			s.AddAgent(Agent{X: 20 + float64(i)*0.2, Y: 0, DirectionX: -1, BaseSpeed: 1})
+		}
+		for i := 0; i < 10; i++ {
+			s.Step()
+		}
+		return s.Snapshot().TotalInfections
+	}
+
+	if free, locked := run(false), run(true); locked >= free {
+		t.Fatalf("expected lockdown to cut spatial infections, got %d locked against %d free", locked, free)
+	}
+}
+
+func TestSmallerContactRadiusCutsSpatialInfections(t *testing.T) {
+	run := func(radius float64) (int, Snapshot) {
+		s := newCrowdSimulation(2000, false)
+		state, _, err := s.ApplyControlSettings(ControlSettings{
+			TransmissionModifier:        1,
+			HospitalCapacity:            50,
+			DeathRateOverloadMultiplier: 2,
+			InfectionRadius:             &radius,
+		})
+		if err != nil {
+			t.Fatalf("apply radius %v: %v", radius, err)
+		}
+		return s.StepN(15).TotalInfections, state
+	}
+
+	wide, state := run(1.5)
+	if state.InfectionRadius != 1.5 {
+		t.Fatalf("expected the snapshot to report radius 1.5, got %v", state.InfectionRadius)
+	}
+	if narrow, _ := run(0.5); narrow >= wide {
+		t.Fatalf("expected a smaller radius to cut infections, got %d at 0.5 against %d at 1.5", narrow, wide)
+	}
+}
+
+// newCrowdSimulation scatters n moving agents at a constant density, one in
+// a hundred of them infected.
+func newCrowdSimulation(n int, bruteForce bool) *Simulation {
+	s := NewWithSeed(0.3, 21)
+	s.bruteForceNeighbours = bruteForce
+	s.SetRecoveryRate(0.05)
+
+	layout := rand.New(rand.NewSource(int64(n)))
+	side := math.Sqrt(float64(n)) * 2
+	for i := 0; i < n; i++ {
+		angle := layout.Float64() * 2 * math.Pi
+		s.AddAgent(Agent{
+			X:          layout.Float64() * side,
+			Y:          layout.Float64() * side,
+			DirectionX: math.Cos(angle),
+			DirectionY: math.Sin(angle),
+			BaseSpeed:  0.5,
+			Infected:   i%100 == 0,
+		})
+	}
+	s.SetInfectionRadius(1)
+	return s
+}
+
+func TestImportedInfectionsIgnoreAgentIndex(t *testing.T) {
+	const agents, runs = 20, 4000
+	hits := make([]int, agents)
+	for seed := int64(0); seed < runs; seed++ {
+		s := NewWithSeed(0, seed)
+		for i := 0; i < agents; i++ {
+			s.AddAgent(Agent{X: float64(i) * 10})
+		}
+		s.SetInfectionRadius(1)
+		if err := s.ScheduleImport(1, 1); err != nil {
+			t.Fatalf("schedule import: %v", err)
+		}
+		s.Step()
+		for i, a := range s.Agents() {
+			if a.Infected || a.Recovered || a.Dead {
+				hits[i]++
+			}
+		}
+	}
+
+	// Each agent should be the import about runs/agents = 200 times; a
+	// standard deviation is about 14.
+	for i, n := range hits {
+		if n < 140 || n > 260 {
+			t.Fatalf("expected agent %d to be infected about 200 times, got %d: %v", i, n, hits)
+		}
+	}
+	var meanIndex float64
+	for i, n := range hits {
+		meanIndex += float64(i*n) / runs
+	}
+	if math.Abs(meanIndex-9.5) > 0.5 {
+		t.Fatalf("expected the infected index to average 9.5, got %v", meanIndex)
+	}
+}
+
+func TestSpatialGridMatchesBruteForce(t *testing.T) {
+	grid, bruteForce := newCrowdSimulation(2000, false), newCrowdSimulation(2000, true)
+	for i := 0; i < 20; i++ {
+		if got, want := grid.Step(), bruteForce.Step(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("tick %d: grid diverged from brute force:\nwant %+v\ngot  %+v", i+1, want, got)
+		}
+	}
+	if !reflect.DeepEqual(grid.Agents(), bruteForce.Agents()) {
+		t.Fatal("expected the grid to leave every agent in the same state as brute force")
+	}
+	if grid.Snapshot().TotalInfections == 0 {
+		t.Fatal("expected the crowd to spread the infection")
+	}
+}
+
+func BenchmarkSpatialStep(b *testing.B) {
+	for _, n := range []int{10000, 50000} {
+		for _, bruteForce := range []bool{false, true} {
+			name := fmt.Sprintf("agents=%d/grid", n)
+			if bruteForce {
+				name = fmt.Sprintf("agents=%d/bruteforce", n)
+			}
+			b.Run(name, func(b *testing.B) {
+				s := newCrowdSimulation(n, bruteForce)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					s.Step()
+				}
+			})
+		}
+	}
+}
+
+func TestWorldBoundsKeepAgentsInside(t *testing.T) {
+	s := newSpatialSimulation(t, 4)
+	s.SetWorldBounds(20, 10)
+	for i := 0; i < 10; i++ {
+		s.AddAgent(Agent{X: 10, Y: 5, DirectionX: 0.6, DirectionY: 0.8, BaseSpeed: 3})
+	}
+	for i := 0; i < 50; i++ {
+		s.Step()
+	}
+
+	for _, a := range s.Agents() {
+		if a.X < 0 || a.X > 20 || a.Y < 0 || a.Y > 10 {
+			t.Fatalf("expected every agent inside the 20x10 world, got (%v, %v)", a.X, a.Y)
+		}
+	}
+}
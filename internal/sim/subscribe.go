@@ -0,0 +1,70 @@
+package sim
+
+import "log"
+
+// Subscribe registers a listener that receives every snapshot produced by Run
+// or Step. Sends never block the simulation: when the buffer is full the
+// snapshot is dropped for that subscriber. The returned function unsubscribes
+// and closes the channel; it is safe to call more than once.
+func (s *Simulation) Subscribe(buffer int) (<-chan Snapshot, func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan Snapshot, buffer)
+
+	s.subMu.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan Snapshot]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	unsubscribe := func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// HookStatus summarizes the callbacks currently registered on a simulation.
+// Counts that never return to zero point at listeners that were not cleaned
+// up.
+type HookStatus struct {
+	Subscribers  int  `json:"subscribers"`
+	RunReporters int  `json:"run_reporters"`
+	CustomLogger bool `json:"custom_logger"`
+}
+
+// ActiveHooks reports how many subscribers and Run report callbacks are
+// registered and whether logging was redirected with SetLogger.
+func (s *Simulation) ActiveHooks() HookStatus {
+	s.subMu.Lock()
+	subscribers := len(s.subscribers)
+	s.subMu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return HookStatus{
+		Subscribers:  subscribers,
+		RunReporters: s.runReporters,
+		CustomLogger: s.logger != log.Default(),
+	}
+}
+
+func (s *Simulation) publish(state Snapshot) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
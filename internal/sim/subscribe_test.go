@@ -0,0 +1,74 @@
+package sim
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscribeReceivesStepsUntilUnsubscribed(t *testing.T) {
+	s := New(0.2)
+	updates, unsubscribe := s.Subscribe(4)
+
+	s.Step()
+	if state := <-updates; state.Tick != 1 {
+		t.Fatalf("expected snapshot for tick 1, got %d", state.Tick)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	s.Step()
+	if _, ok := <-updates; ok {
+		t.Fatal("expected the channel to be closed after unsubscribing")
+	}
+}
+
+func TestActiveHooksTracksRegistrations(t *testing.T) {
+	s := New(0.2)
+	if hooks := s.ActiveHooks(); hooks != (HookStatus{}) {
+		t.Fatalf("expected no hooks on a fresh simulation, got %+v", hooks)
+	}
+
+	_, unsubscribeFirst := s.Subscribe(1)
+	_, unsubscribeSecond := s.Subscribe(1)
+	if got := s.ActiveHooks().Subscribers; got != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", got)
+	}
+
+	unsubscribeFirst()
+	if got := s.ActiveHooks().Subscribers; got != 1 {
+		t.Fatalf("expected 1 subscriber after unsubscribing, got %d", got)
+	}
+	unsubscribeSecond()
+	if got := s.ActiveHooks().Subscribers; got != 0 {
+		t.Fatalf("expected 0 subscribers, got %d", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reported := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, 5*time.Millisecond, func(Snapshot) {
+			select {
+			case reported <- struct{}{}:
+			default:
+			}
+		})
+		close(done)
+	}()
+
+	<-reported
+	if got := s.ActiveHooks().RunReporters; got != 1 {
+		t.Fatalf("expected 1 run reporter while running, got %d", got)
+	}
+	cancel()
+	<-done
+	if got := s.ActiveHooks().RunReporters; got != 0 {
+		t.Fatalf("expected 0 run reporters after Run returns, got %d", got)
+	}
+
+	s.SetLogger(nil)
+	if !s.ActiveHooks().CustomLogger {
+		t.Fatal("expected SetLogger to register as a custom logger")
+	}
+}
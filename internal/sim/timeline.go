@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EventTimeline marks a control change made by a timeline entry.
+const EventTimeline EventKind = "timeline"
+
+// TimelineEvent changes the controls when a run reaches Tick, such as
+// enabling lockdown at tick 100. Unset fields are left as they are.
+// VaccinationDoses starts, re-paces, or with 0 stops a vaccination campaign.
+type TimelineEvent struct {
+	Tick                        int      `json:"tick"`
+	TransmissionModifier        *float64 `json:"transmission_modifier,omitempty"`
+	LockdownEnabled             *bool    `json:"lockdown_enabled,omitempty"`
+	HospitalCapacity            *int     `json:"hospital_capacity,omitempty"`
+	DeathRateOverloadMultiplier *float64 `json:"death_rate_overload_multiplier,omitempty"`
+	InteractionVariance         *float64 `json:"interaction_variance,omitempty"`
+	ImportRate                  *float64 `json:"import_rate,omitempty"`
+	VaccinationDoses            *int     `json:"vaccination_doses,omitempty"`
+}
+
+// ScheduleTimelineEvent adds e to the timeline, so Run or Step applies it
+// once the run reaches e.Tick and the snapshot for that tick reflects it.
+// Scripted policy changes need nobody at the control socket. The tick must
+// be after the current one.
+func (s *Simulation) ScheduleTimelineEvent(e TimelineEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := e.validate(len(s.timeline)); err != nil {
+		return err
+	}
+	if e.Tick <= s.tick {
+		return fmt.Errorf("timeline tick %d is not after the current tick %d", e.Tick, s.tick)
+	}
+	s.setTimelineLocked(append(s.timeline, e))
+	return nil
+}
+
+// Timeline returns the scheduled control changes in tick order, including
+// those already applied.
+func (s *Simulation) Timeline() []TimelineEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.timeline)
+}
+
+// validate reports the first field of the event that falls outside its
+// valid range, naming it by its position in the timeline.
+func (e TimelineEvent) validate(index int) error {
+	switch {
+	case e.Tick < 1:
+		return fmt.Errorf("timeline[%d].tick %d is not positive", index, e.Tick)
+	case e.TransmissionModifier == nil && e.LockdownEnabled == nil && e.HospitalCapacity == nil &&
+		e.DeathRateOverloadMultiplier == nil && e.InteractionVariance == nil && e.ImportRate == nil &&
+		e.VaccinationDoses == nil:
+		return fmt.Errorf("timeline[%d] at tick %d changes nothing", index, e.Tick)
+	case e.TransmissionModifier != nil && (*e.TransmissionModifier < 0 || *e.TransmissionModifier > 1):
+		return fmt.Errorf("timeline[%d].transmission_modifier %v not in [0, 1]", index, *e.TransmissionModifier)
+	case e.HospitalCapacity != nil && *e.HospitalCapacity < 0:
+		return fmt.Errorf("timeline[%d].hospital_capacity %d is negative", index, *e.HospitalCapacity)
+	case e.DeathRateOverloadMultiplier != nil && *e.DeathRateOverloadMultiplier < 1:
+		return fmt.Errorf("timeline[%d].death_rate_overload_multiplier %v is below 1", index, *e.DeathRateOverloadMultiplier)
+	case e.InteractionVariance != nil && sanitizeInteractionVariance(*e.InteractionVariance) != *e.InteractionVariance:
+		return fmt.Errorf("timeline[%d].interaction_variance %v is not a finite, non-negative number",
+			index, *e.InteractionVariance)
+	case e.ImportRate != nil && sanitizeImportRate(*e.ImportRate) != *e.ImportRate:
+		return fmt.Errorf("timeline[%d].import_rate %v is not a finite, non-negative number", index, *e.ImportRate)
+	case e.VaccinationDoses != nil && *e.VaccinationDoses < 0:
+		return fmt.Errorf("timeline[%d].vaccination_doses %d is negative", index, *e.VaccinationDoses)
+	}
+	return nil
+}
+
+// setTimelineLocked replaces the timeline with a copy of events in tick
+// order; entries for the same tick keep their order.
+func (s *Simulation) setTimelineLocked(events []TimelineEvent) {
+	s.timeline = slices.Clone(events)
+	slices.SortStableFunc(s.timeline, func(a, b TimelineEvent) int { return a.Tick - b.Tick })
+}
+
+// applyTimelineLocked applies the timeline entries due at the current tick,
+// recording an EventTimeline for each. The timeline is kept, so a reset run
+// replays it.
+func (s *Simulation) applyTimelineLocked() {
+	for _, e := range s.timeline {
+		if e.Tick != s.tick {
+			continue
+		}
+
+		var changes []string
+		if e.TransmissionModifier != nil {
+			s.applyTransmissionModifierLocked(*e.TransmissionModifier)
+			changes = append(changes, fmt.Sprintf("transmission modifier %v", s.transmissionMod))
+		}
+		if e.LockdownEnabled != nil {
+			s.applyLockdownLocked(*e.LockdownEnabled)
+			changes = append(changes, fmt.Sprintf("lockdown %v", *e.LockdownEnabled))
+		}
+		if e.HospitalCapacity != nil {
+			s.hospitalCapacity = sanitizeCapacity(*e.HospitalCapacity)
+			changes = append(changes, fmt.Sprintf("hospital capacity %d", s.hospitalCapacity))
+		}
+		if e.DeathRateOverloadMultiplier != nil {
+			s.deathRateOverloadMultiplier = sanitizeOverloadMultiplier(*e.DeathRateOverloadMultiplier)
+			changes = append(changes, fmt.Sprintf("overload multiplier %v", s.deathRateOverloadMultiplier))
+		}
+		if e.InteractionVariance != nil {
+			s.interactionVariance = sanitizeInteractionVariance(*e.InteractionVariance)
+			changes = append(changes, fmt.Sprintf("interaction variance %v", s.interactionVariance))
+		}
+		if e.ImportRate != nil {
+			s.importRate = sanitizeImportRate(*e.ImportRate)
+			changes = append(changes, fmt.Sprintf("import rate %v", s.importRate))
+		}
+		if e.VaccinationDoses != nil {
+			s.vaccinationDoses = *e.VaccinationDoses
+			changes = append(changes, fmt.Sprintf("vaccination doses %d", s.vaccinationDoses))
+		}
+		s.version++
+		s.recordEventLocked(Event{
+			Tick:    s.tick,
+			Kind:    EventTimeline,
+			Message: "timeline: " + strings.Join(changes, ", "),
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package sim
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScheduledTimelineEventFiresOnceDuringRun(t *testing.T) {
+	s := NewWithSeed(0.2, 6)
+	s.SetLogger(nil)
+	lockdown, doses := true, 5
+	if err := s.ScheduleTimelineEvent(TimelineEvent{Tick: 3, LockdownEnabled: &lockdown, VaccinationDoses: &doses}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reported := make(chan Snapshot, 10)
+	go s.Run(ctx, time.Millisecond, func(state Snapshot) {
+		if state.Tick <= 6 {
+			reported <- state
+		}
+		if state.Tick == 6 {
+			cancel()
+		}
+	})
+
+	for state := range reported {
+		if want := state.Tick >= 3; state.LockdownEnabled != want {
+			t.Fatalf("tick %d: expected lockdown %v in the reported snapshot", state.Tick, want)
+		}
+		if state.Tick == 6 {
+			break
+		}
+	}
+
+	fired := 0
+	for _, e := range s.Events() {
+		if e.Kind == EventTimeline {
+			fired++
+		}
+	}
+	if fired != 1 {
+		t.Fatalf("expected the event to fire exactly once, got %d", fired)
+	}
+	if got := s.VaccinationDoses(); got != 5 {
+		t.Fatalf("expected the campaign to start with 5 doses, got %d", got)
+	}
+}
+
+func TestScheduleTimelineEventRejectsBadEvents(t *testing.T) {
+	s := NewWithSeed(0.2, 6)
+	s.Step()
+	lockdown, doses := true, -1
+
+	if err := s.ScheduleTimelineEvent(TimelineEvent{Tick: 1, LockdownEnabled: &lockdown}); err == nil {
+		t.Fatal("expected an error for a tick that already passed")
+	}
+	if err := s.ScheduleTimelineEvent(TimelineEvent{Tick: 5}); err == nil {
+		t.Fatal("expected an error for an event that changes nothing")
+	}
+	if err := s.ScheduleTimelineEvent(TimelineEvent{Tick: 5, VaccinationDoses: &doses}); err == nil {
+		t.Fatal("expected an error for negative doses")
+	}
+	if got := s.Timeline(); len(got) != 0 {
+		t.Fatalf("expected rejected events to stay off the timeline, got %+v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package sim
+
+import "math"
+
+// defaultTracingWindow is how many ticks traced contacts stay in quarantine.
+const defaultTracingWindow = 5
+
+// SetTracingEffectiveness turns on test-trace-quarantine. Each tick,
+// fraction f of the contacts made by infectious people are traced: traced
+// contacts who were infected are isolated and stop spreading until they
+// recover or die, and traced susceptible contacts are quarantined, out of
+// reach of infection, for the tracing window. Values are clamped to [0, 1];
+// the default of 0 disables tracing.
+func (s *Simulation) SetTracingEffectiveness(f float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tracingEffectiveness = math.Min(math.Max(f, 0), 1)
+}
+
+// TracingEffectiveness returns the fraction of contacts traced.
+func (s *Simulation) TracingEffectiveness() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tracingEffectiveness
+}
+
+// SetTracingWindow sets how many ticks traced susceptible contacts stay in
+// quarantine. Anyone currently quarantined is released. Non-positive values
+// restore the default of 5.
+func (s *Simulation) SetTracingWindow(ticks int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ticks <= 0 {
+		ticks = defaultTracingWindow
+	}
+	s.releaseQuarantineLocked()
+	s.tracingWindow = ticks
+}
+
+func (s *Simulation) tracingWindowLocked() int {
+	if s.tracingWindow <= 0 {
+		return defaultTracingWindow
+	}
+	return s.tracingWindow
+}
+
+// transmittingLocked is the number of infectious people not in isolation,
+// with quarantined people counted only as far as quarantine leaks.
+func (s *Simulation) transmittingLocked() int {
+	return s.currentInfected - s.isolated - s.currentQuarantined + s.quarantineLeakLocked()
+}
+
+// traceContactsLocked traces this tick's contacts. infected is how many of
+// the contacts became infectious this tick; the rest of the contacts that
+// reached susceptible people are candidates for quarantine. Quarantines that
+// have run for the whole window are released first.
+func (s *Simulation) traceContactsLocked(contacts, infected int) {
+	window := s.tracingWindowLocked()
+	if len(s.quarantine) != window {
+		s.releaseQuarantineLocked()
+		s.quarantine = make([]int, window)
+	}
+	slot := s.tick % window
+	s.currentSusceptible += s.quarantine[slot]
+	s.quarantine[slot] = 0
+
+	s.traced = 0
+	if s.tracingEffectiveness == 0 {
+		return
+	}
+
+	isolated := s.binomialLocked(infected, s.tracingEffectiveness)
+	s.isolated = min(s.isolated+isolated, s.currentInfected-s.currentQuarantined)
+
+	exposedSusceptibles := int(math.Round(float64(max(contacts-infected, 0)) * s.susceptibleFractionLocked()))
+	quarantined := min(s.binomialLocked(exposedSusceptibles, s.tracingEffectiveness), s.currentSusceptible)
+	s.currentSusceptible -= quarantined
+	s.quarantine[slot] = quarantined
+
+	s.traced = isolated + quarantined
+}
+
+// releaseQuarantineLocked returns everyone in quarantine to the susceptible
+// pool.
+func (s *Simulation) releaseQuarantineLocked() {
+	for i, count := range s.quarantine {
+		s.currentSusceptible += count
+		s.quarantine[i] = 0
+	}
+}
+
+func (s *Simulation) quarantinedLocked() int {
+	total := 0
+	for _, count := range s.quarantine {
+		total += count
+	}
+	return total
+}
+
+// settleIsolationLocked shrinks the isolated count in proportion when
+// infectious people leave the pool, since isolated cases resolve at the same
+// rate as everyone else.
+func (s *Simulation) settleIsolationLocked(infectedBefore int) {
+	if s.isolated == 0 || infectedBefore == 0 {
+		return
+	}
+	s.isolated = min(int(math.Round(float64(s.isolated)*float64(s.currentInfected)/float64(infectedBefore))), s.currentInfected)
+}
@@ -0,0 +1,58 @@
+package sim
+
+import "testing"
+
+func TestTracingReducesOnwardTransmission(t *testing.T) {
+	run := func(effectiveness float64) (infections, traced int) {
+		for seed := int64(1); seed <= 5; seed++ {
+			s := NewWithSeed(0.3, seed)
+			s.SetRecoveryRate(0.1)
+			s.SetTracingEffectiveness(effectiveness)
+			for i := 0; i < 40; i++ {
+				state := s.Step()
+				traced += state.Traced
+				if state.Isolated > state.CurrentInfected {
+					t.Fatalf("tick %d: %d isolated out of %d infected", state.Tick, state.Isolated, state.CurrentInfected)
+				}
+			}
+			infections += s.Snapshot().TotalInfections
+		}
+		return infections, traced
+	}
+
+	untraced, none := run(0)
+	traced, some := run(0.8)
+	if none != 0 {
+		t.Fatalf("expected nobody traced with tracing off, got %d", none)
+	}
+	if some == 0 {
+		t.Fatal("expected contacts to be traced")
+	}
+	if traced*3 > untraced*2 {
+		t.Fatalf("expected 80%% tracing to cut infections by at least a third, got %d vs %d", traced, untraced)
+	}
+}
+
+func TestQuarantineReleasesAfterWindow(t *testing.T) {
+	s := NewWithSeed(0.3, 3)
+	s.SetTracingEffectiveness(1)
+	s.SetTracingWindow(2)
+
+	s.Step()
+	quarantined := s.Snapshot().Quarantined
+	if quarantined == 0 {
+		t.Fatal("expected susceptible contacts to be quarantined")
+	}
+
+	s.SetTracingEffectiveness(0)
+	s.Step()
+	s.Step()
+	state := s.Snapshot()
+	if state.Quarantined != 0 {
+		t.Fatalf("expected the quarantine to end after 2 ticks, %d still quarantined", state.Quarantined)
+	}
+	accounted := state.CurrentSusceptible + state.CurrentInfected + state.CurrentRecovered + state.TotalDeaths
+	if accounted != state.Population {
+		t.Fatalf("expected released contacts back in the susceptible pool, compartments add to %d of %d", accounted, state.Population)
+	}
+}
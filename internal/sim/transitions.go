@@ -0,0 +1,174 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Compartment identifies one state in the transition matrix.
+type Compartment int
+
+const (
+	Susceptible Compartment = iota
+	Exposed
+	Infectious
+	Recovered
+	Dead
+	numCompartments
+)
+
+// String returns the compartment's name.
+func (c Compartment) String() string {
+	switch c {
+	case Susceptible:
+		return "susceptible"
+	case Exposed:
+		return "exposed"
+	case Infectious:
+		return "infectious"
+	case Recovered:
+		return "recovered"
+	case Dead:
+		return "dead"
+	default:
+		return fmt.Sprintf("Compartment(%d)", int(c))
+	}
+}
+
+// TransitionMatrix holds per-tick transition probabilities: m[from][to] is
+// the chance that one person in from moves to to during a tick. Whatever is
+// left of each row is the chance of staying put.
+type TransitionMatrix [numCompartments][numCompartments]float64
+
+// Validate reports the first entry or row that can't be a probability
+// distribution. Infection itself is driven by contacts, so the susceptible
+// row may not move anyone to exposed or infectious; the dead never leave.
+func (m TransitionMatrix) Validate() error {
+	for from := Susceptible; from < numCompartments; from++ {
+		sum := 0.0
+		for to := Susceptible; to < numCompartments; to++ {
+			p := m[from][to]
+			if p < 0 || p > 1 || math.IsNaN(p) {
+				return fmt.Errorf("%s to %s probability %v not in [0, 1]", from, to, p)
+			}
+			if p > 0 && from == to {
+				return fmt.Errorf("%s to itself must be 0; staying put is whatever the row leaves over", from)
+			}
+			sum += p
+		}
+		if sum > 1 {
+			return fmt.Errorf("%s row sums to %v, more than 1", from, sum)
+		}
+	}
+	if m[Susceptible][Exposed] > 0 || m[Susceptible][Infectious] > 0 {
+		return fmt.Errorf("susceptible to exposed or infectious must be 0; infections come from contacts")
+	}
+	for to := Susceptible; to < numCompartments; to++ {
+		if m[Dead][to] > 0 {
+			return fmt.Errorf("dead to %s must be 0", to)
+		}
+	}
+	return nil
+}
+
+// SetTransitionMatrix replaces the built-in exposed, recovery, and death
+// transitions with m. Each tick, new infections still come from contacts
+// with the susceptible pool; they enter the exposed compartment when m moves
+// anyone from exposed to infectious, and the infectious compartment
+// otherwise. Everyone in every compartment then moves independently
+// according to their row. Hospital overload still multiplies the infectious
+// to dead probability, up to what the row leaves over. The outcome model and
+// incubation period are ignored while a matrix is set.
+func (s *Simulation) SetTransitionMatrix(m TransitionMatrix) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transitions = &m
+	return nil
+}
+
+// ClearTransitionMatrix restores the built-in transitions.
+func (s *Simulation) ClearTransitionMatrix() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transitions = nil
+}
+
+// TransitionMatrix returns the active matrix and whether one is set.
+func (s *Simulation) TransitionMatrix() (TransitionMatrix, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.transitions == nil {
+		return TransitionMatrix{}, false
+	}
+	return *s.transitions, true
+}
+
+// matrixExposesLocked reports whether new infections pass through the exposed
+// compartment under the transition matrix.
+func (s *Simulation) matrixExposesLocked() bool {
+	return s.transitions[Exposed][Infectious] > 0
+}
+
+// applyTransitionsLocked moves everyone according to the transition matrix,
+// drawing every move from the counts at the start of the call so nobody moves
+// twice in one tick.
+func (s *Simulation) applyTransitionsLocked() {
+	m := *s.transitions
+	deathProbability, _ := s.deathProbabilityLocked()
+	others := 0.0
+	for to := Susceptible; to < numCompartments; to++ {
+		if to != Dead {
+			others += m[Infectious][to]
+		}
+	}
+	m[Infectious][Dead] = min(deathProbability, 1-others)
+
+	counts := [numCompartments]int{
+		Susceptible: s.currentSusceptible,
+		Exposed:     s.currentExposed,
+		Infectious:  s.currentInfected,
+		Recovered:   s.currentRecovered,
+	}
+	if s.population <= 0 {
+		counts[Susceptible] = 0
+	}
+
+	var moves [numCompartments][numCompartments]int
+	for from := Susceptible; from < Dead; from++ {
+		for i := 0; i < counts[from]; i++ {
+			u := s.rng.Float64()
+			for to := Susceptible; to < numCompartments; to++ {
+				if u < m[from][to] {
+					moves[from][to]++
+					break
+				}
+				u -= m[from][to]
+			}
+		}
+	}
+
+	compartments := [numCompartments]*int{
+		Susceptible: &s.currentSusceptible,
+		Exposed:     &s.currentExposed,
+		Infectious:  &s.currentInfected,
+		Recovered:   &s.currentRecovered,
+		Dead:        &s.totalDeaths,
+	}
+	for from := Susceptible; from < numCompartments; from++ {
+		for to := Susceptible; to < numCompartments; to++ {
+			n := moves[from][to]
+			*compartments[from] -= n
+			if to != Susceptible || s.population > 0 {
+				*compartments[to] += n
+			}
+		}
+	}
+	s.totalRecoveries += moves[Infectious][Recovered]
+}
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransitionMatrixReproducesDefaultSIR(t *testing.T) {
+	const (
+		seeds    = 40
+		ticks    = 40
+		recovery = 0.1
+	)
+	run := func(matrix bool) (infections, deaths, recoveries float64) {
+		for seed := int64(1); seed <= seeds; seed++ {
+			s := NewWithSeed(0.3, seed)
+			s.SetHospitalCapacity(0)
+			s.SetRecoveryRate(recovery)
+			if matrix {
+				var m TransitionMatrix
+				m[Infectious][Recovered] = recovery
+				m[Infectious][Dead] = defaultBaseDeathRate
+				if err := s.SetTransitionMatrix(m); err != nil {
+					t.Fatalf("set matrix: %v", err)
+				}
+			}
+			var state Snapshot
+			for i := 0; i < ticks; i++ {
+				state = s.Step()
+			}
+			infections += float64(state.TotalInfections) / seeds
+			deaths += float64(state.TotalDeaths) / seeds
+			recoveries += float64(state.TotalRecoveries) / seeds
+		}
+		return infections, deaths, recoveries
+	}
+
+	wantInfections, wantDeaths, wantRecoveries := run(false)
+	gotInfections, gotDeaths, gotRecoveries := run(true)
+	near := func(got, want, tolerance float64) bool {
+		return math.Abs(got-want) <= tolerance*want
+	}
+	if !near(gotInfections, wantInfections, 0.1) || !near(gotRecoveries, wantRecoveries, 0.1) || !near(gotDeaths, wantDeaths, 0.3) {
+		t.Fatalf("expected the matrix to match built-in SIR (%.1f infections, %.1f recoveries, %.1f deaths), got %.1f, %.1f, %.1f",
+			wantInfections, wantRecoveries, wantDeaths, gotInfections, gotRecoveries, gotDeaths)
+	}
+}
+
+func TestTransitionMatrixMovesEveryCompartment(t *testing.T) {
+	s := NewWithSeed(0.3, 2)
+	s.UpdateTransmissionModifier(0)
+	s.SetHospitalCapacity(0)
+	s.SetPopulation(1000)
+	var m TransitionMatrix
+	m[Susceptible][Recovered] = 0.01
+	m[Exposed][Infectious] = 0.5
+	m[Infectious][Recovered] = 0.2
+	m[Recovered][Susceptible] = 0.05
+	if err := s.SetTransitionMatrix(m); err != nil {
+		t.Fatalf("set matrix: %v", err)
+	}
+	s.currentExposed = 40
+	s.currentSusceptible -= 40
+
+	state := s.Step()
+	if state.CurrentExposed >= 40 || state.CurrentRecovered == 0 {
+		t.Fatalf("expected exposed to progress and people to recover, got %+v", state)
+	}
+	for i := 0; i < 50; i++ {
+		state = s.Step()
+		total := state.CurrentSusceptible + state.CurrentExposed + state.CurrentInfected + state.CurrentRecovered + state.TotalDeaths
+		if total != state.Population {
+			t.Fatalf("tick %d: compartments add to %d of %d", state.Tick, total, state.Population)
+		}
+	}
+}
+
+func TestTransitionMatrixValidation(t *testing.T) {
+	var overfull TransitionMatrix
+	overfull[Infectious][Recovered] = 0.7
+	overfull[Infectious][Dead] = 0.4
+
+	var infecting TransitionMatrix
+	infecting[Susceptible][Infectious] = 0.1
+
+	var resurrecting TransitionMatrix
+	resurrecting[Dead][Susceptible] = 0.1
+
+	s := New(0.25)
+	for name, m := range map[string]TransitionMatrix{"overfull row": overfull, "direct infection": infecting, "leaving dead": resurrecting} {
+		if err := s.SetTransitionMatrix(m); err == nil {
+			t.Errorf("%s: expected the matrix to be rejected", name)
+		}
+	}
+	if _, ok := s.TransitionMatrix(); ok {
+		t.Fatal("expected rejected matrices to leave the built-in transitions in place")
+	}
+}
@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math"
+)
+
+// SetTravelRate sets the fraction of each region's infected people who travel
+// to another region every tick, carrying the infection with them. Rates are
+// clamped to [0, 1]; NaN disables travel. Travellers pick a destination from
+// the travel matrix, which is uniform across the other regions unless
+// SetTravelMatrix replaced it. A lockdown, global or in the origin region,
+// scales outbound travel by the lockdown speed modifier.
+func (s *Simulation) SetTravelRate(rate float64) {
+	if math.IsNaN(rate) {
+		rate = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.travelRate = min(max(rate, 0), 1)
+}
+
+// TravelRate returns the per-tick fraction of infected people who travel.
+func (s *Simulation) TravelRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.travelRate
+}
+
+// SetTravelMatrix sets how travellers choose their destination:
+// m[origin][destination] is the relative weight of each destination for
+// people leaving origin. Rows are normalised, so only the ratios matter, and
+// an origin without a row, or with only zero weights, sends nobody. Every
+// name must be an existing region, weights must be finite and non-negative,
+// and a region cannot list itself. A nil matrix restores uniform travel.
+func (s *Simulation) SetTravelMatrix(m map[string]map[string]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for origin, row := range m {
+		if s.regionLocked(origin) == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownRegion, origin)
+		}
+		for destination, weight := range row {
+			if s.regionLocked(destination) == nil {
+				return fmt.Errorf("%w: %q", ErrUnknownRegion, destination)
+			}
+			if destination == origin {
+				return fmt.Errorf("region %q cannot travel to itself", origin)
+			}
+			if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+				return fmt.Errorf("travel weight from %q to %q must be finite and non-negative, got %v",
+					origin, destination, weight)
+			}
+		}
+	}
+
+	if m == nil {
+		s.travelMatrix = nil
+		return nil
+	}
+	s.travelMatrix = make(map[string]map[string]float64, len(m))
+	for origin, row := range m {
+		s.travelMatrix[origin] = maps.Clone(row)
+	}
+	return nil
+}
+
+// SetRegionLockdown locks a single region down, cutting its outbound travel
+// by the lockdown speed modifier.
+func (s *Simulation) SetRegionLockdown(name string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.regionLocked(name)
+	if r == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownRegion, name)
+	}
+	r.lockdown = enabled
+	return nil
+}
+
+// travelLocked moves infected travellers between regions and records each
+// region's outbound counts for this tick.
+func (s *Simulation) travelLocked() {
+	moves := make([]map[string]int, len(s.regions))
+	for i := range s.regions {
+		origin := &s.regions[i]
+		origin.travelled = nil
+		rate := s.travelRate * s.speedModifier
+		if origin.lockdown {
+			rate = min(rate, s.travelRate*lockdownSpeedModifier)
+		}
+		travellers := s.binomialLocked(origin.infected, rate)
+		if travellers == 0 {
+			continue
+		}
+
+		names, weights := s.destinationsLocked(origin.name)
+		if len(names) == 0 {
+			continue
+		}
+		moves[i] = make(map[string]int)
+		for t := 0; t < travellers; t++ {
+			moves[i][names[s.pickWeightedLocked(weights)]]++
+		}
+	}
+
+	// Travellers leave before anyone arrives, so nobody moves twice a tick.
+	for i, counts := range moves {
+		for name, count := range counts {
+			s.regions[i].infected -= count
+			s.regions[i].population -= count
+			destination := s.regionLocked(name)
+			destination.infected += count
+			destination.population += count
+		}
+		s.regions[i].travelled = counts
+	}
+}
+
+// destinationsLocked lists the regions reachable from origin with their
+// weights, in region order so draws are reproducible.
+func (s *Simulation) destinationsLocked(origin string) ([]string, []float64) {
+	var names []string
+	var weights []float64
+	for _, r := range s.regions {
+		if r.name == origin {
+			continue
+		}
+		weight := 1.0
+		if s.travelMatrix != nil {
+			weight = s.travelMatrix[origin][r.name]
+		}
+		if weight > 0 {
+			names = append(names, r.name)
+			weights = append(weights, weight)
+		}
+	}
+	return names, weights
+}
+
+// pickWeightedLocked draws an index with probability proportional to its
+// weight. weights must have a positive sum.
+func (s *Simulation) pickWeightedLocked(weights []float64) int {
+	draw := s.rng.Float64() * sumWeights(weights)
+	for i, w := range weights {
+		if draw < w {
+			return i
+		}
+		draw -= w
+	}
+	return len(weights) - 1
+}
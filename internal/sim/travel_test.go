@@ -0,0 +1,84 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTravelSimulation(t *testing.T, names ...string) *Simulation {
+	t.Helper()
+	s := NewWithSeed(0.3, 9)
+	// Without transmission, only travel can infect people in other regions.
+	if _, _, err := s.ApplyControlSettings(ControlSettings{DeathRateOverloadMultiplier: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range names {
+		if err := s.AddRegion(name, 10000); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := s.SeedRegion(names[0], 1000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestTravelSeedsOtherRegions(t *testing.T) {
+	s := newTravelSimulation(t, "home", "east", "west")
+	s.SetTravelRate(0.1)
+
+	state := s.Step()
+	home := state.Regions[0]
+	sent := home.Travel["east"] + home.Travel["west"]
+	if home.Travel["east"] == 0 || home.Travel["west"] == 0 {
+		t.Fatalf("expected uniform travel to reach both regions, got %v", home.Travel)
+	}
+	if sent < 70 || sent > 140 {
+		t.Fatalf("expected about 100 travellers at rate 0.1, got %d", sent)
+	}
+	// Arrivals can die or recover within the tick, but nobody else is infected there.
+	for _, r := range state.Regions[1:] {
+		if arrived := home.Travel[r.Name]; r.CurrentInfected+r.CurrentRecovered+r.TotalDeaths != arrived {
+			t.Fatalf("expected %d arrivals in %s, got %+v", arrived, r.Name, r)
+		}
+	}
+}
+
+func TestTravelMatrixAndLockdown(t *testing.T) {
+	s := newTravelSimulation(t, "home", "east", "west")
+	s.SetTravelRate(0.1)
+	if err := s.SetTravelMatrix(map[string]map[string]float64{"home": {"east": 1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	open := s.Step().Regions[0].Travel
+	if open["east"] == 0 || open["west"] != 0 {
+		t.Fatalf("expected travel to east only, got %v", open)
+	}
+
+	if err := s.SetRegionLockdown("home", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	locked := 0
+	for i := 0; i < 10; i++ {
+		locked += s.Step().Regions[0].Travel["east"]
+	}
+	// Ten locked-down ticks move about as many people as one open tick.
+	if locked > open["east"]*2 {
+		t.Fatalf("expected lockdown to cut travel tenfold, got %d over ten ticks against %d in one", locked, open["east"])
+	}
+}
+
+func TestSetTravelMatrixRejectsBadInput(t *testing.T) {
+	s := newTravelSimulation(t, "home", "east")
+
+	if err := s.SetTravelMatrix(map[string]map[string]float64{"home": {"nowhere": 1}}); !errors.Is(err, ErrUnknownRegion) {
+		t.Fatalf("expected ErrUnknownRegion, got %v", err)
+	}
+	if err := s.SetTravelMatrix(map[string]map[string]float64{"home": {"home": 1}}); err == nil {
+		t.Fatal("expected a self-edge to be rejected")
+	}
+	if err := s.SetTravelMatrix(map[string]map[string]float64{"home": {"east": -1}}); err == nil {
+		t.Fatal("expected a negative weight to be rejected")
+	}
+}
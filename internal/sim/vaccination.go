@@ -0,0 +1,88 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// EventVaccinationComplete marks a vaccination campaign that ran out of
+// susceptible people to vaccinate.
+const EventVaccinationComplete EventKind = "vaccination_complete"
+
+// StartVaccination starts, or changes the pace of, a vaccination campaign
+// that moves up to dosesPerTick susceptible people into the vaccinated
+// compartment every tick, before transmission. Vaccinated people can still
+// be infected at 1 - VaccineEfficacy of the usual rate. The campaign stops
+// on its own once nobody susceptible is left. Zero or negative doses stop
+// it. Vaccination needs a bounded population and applies to the single pool,
+// not to regions or spatial mode.
+func (s *Simulation) StartVaccination(dosesPerTick int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vaccinationDoses = max(dosesPerTick, 0)
+}
+
+// StopVaccination stops the vaccination campaign. People already vaccinated
+// stay vaccinated.
+func (s *Simulation) StopVaccination() {
+	s.StartVaccination(0)
+}
+
+// VaccinationDoses returns the campaign's doses per tick, or 0 when no
+// campaign is running.
+func (s *Simulation) VaccinationDoses() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.vaccinationDoses
+}
+
+// SetVaccineEfficacy sets the fraction of infections the vaccine prevents,
+// clamped to [0, 1]; NaN is treated as 1. The default of 1 makes vaccinated
+// people fully immune.
+func (s *Simulation) SetVaccineEfficacy(efficacy float64) {
+	if math.IsNaN(efficacy) {
+		efficacy = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vaccineEfficacy = min(max(efficacy, 0), 1)
+}
+
+// VaccineEfficacy returns the fraction of infections the vaccine prevents.
+func (s *Simulation) VaccineEfficacy() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.vaccineEfficacy
+}
+
+// vaccinateLocked gives this tick's doses and ends the campaign once the
+// susceptible pool is empty.
+func (s *Simulation) vaccinateLocked() {
+	if s.vaccinationDoses == 0 || s.population <= 0 {
+		return
+	}
+
+	doses := min(s.vaccinationDoses, s.currentSusceptible)
+	s.currentSusceptible -= doses
+	s.currentVaccinated += doses
+	if s.currentSusceptible == 0 {
+		s.vaccinationDoses = 0
+		s.recordEventLocked(Event{
+			Tick:    s.tick,
+			Kind:    EventVaccinationComplete,
+			Count:   s.currentVaccinated,
+			Message: fmt.Sprintf("vaccination campaign complete: %d vaccinated", s.currentVaccinated),
+		})
+	}
+}
+
+// breakthroughWeightLocked is how many susceptible people the vaccinated
+// compartment counts as when drawing infections.
+func (s *Simulation) breakthroughWeightLocked() float64 {
+	return float64(s.currentVaccinated) * (1 - s.vaccineEfficacy)
+}
@@ -0,0 +1,52 @@
+package sim
+
+import "testing"
+
+func TestVaccinationDrainsTheSusceptiblePool(t *testing.T) {
+	s := NewWithSeed(0.3, 8)
+	s.SetPopulation(200)
+	s.StartVaccination(50)
+
+	state := s.Step()
+	if state.CurrentVaccinated != 50 {
+		t.Fatalf("expected 50 vaccinated after one tick, got %d", state.CurrentVaccinated)
+	}
+	accounted := state.CurrentSusceptible + state.CurrentInfected + state.CurrentRecovered + state.CurrentVaccinated +
+		state.TotalDeaths
+	if accounted != state.Population {
+		t.Fatalf("expected the compartments to add up to %d, got %d", state.Population, accounted)
+	}
+
+	for i := 0; i < 10; i++ {
+		state = s.Step()
+	}
+	if state.CurrentSusceptible != 0 || s.VaccinationDoses() != 0 {
+		t.Fatalf("expected the campaign to stop with nobody left susceptible, got %d susceptible and %d doses",
+			state.CurrentSusceptible, s.VaccinationDoses())
+	}
+	events := s.Events()
+	if len(events) == 0 || events[len(events)-1].Kind != EventVaccinationComplete {
+		t.Fatalf("expected a vaccination_complete event, got %+v", events)
+	}
+}
+
+func TestVaccineEfficacyControlsBreakthroughs(t *testing.T) {
+	run := func(efficacy float64) int {
+		s := NewWithSeed(0.5, 8)
+		s.SetPopulation(2000)
+		s.SetVaccineEfficacy(efficacy)
+		s.StartVaccination(2000)
+		vaccinated := s.Step().CurrentVaccinated
+		for i := 0; i < 20; i++ {
+			s.Step()
+		}
+		return vaccinated - s.Snapshot().CurrentVaccinated
+	}
+
+	if got := run(1); got != 0 {
+		t.Fatalf("expected a fully effective vaccine to prevent every infection, got %d", got)
+	}
+	if got := run(0.2); got == 0 {
+		t.Fatal("expected breakthrough infections with a 20% effective vaccine")
+	}
+}
@@ -0,0 +1,220 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// EventVariant marks infections of a variant introduced with IntroduceVariant.
+const EventVariant EventKind = "variant"
+
+// ErrUnknownVariant is returned when a variant name has not been added.
+var ErrUnknownVariant = errors.New("unknown variant")
+
+// variant is one strain of the active pathogen.
+type variant struct {
+	name                   string
+	transmissionMultiplier float64
+	deathMultiplier        float64
+	infected               int
+	infections             int
+}
+
+// VariantSnapshot captures one variant's state. TotalInfections counts every
+// infection attributed to the variant since it was added.
+type VariantSnapshot struct {
+	Name                   string  `json:"name"`
+	TransmissionMultiplier float64 `json:"transmission_multiplier"`
+	DeathMultiplier        float64 `json:"death_multiplier"`
+	CurrentInfected        int     `json:"current_infected"`
+	TotalInfections        int     `json:"total_infections"`
+}
+
+// AddVariant registers a variant whose infections transmit at
+// transmissionMultiplier times, and kill at deathMultiplier times, the
+// pathogen's base rates. The first variant added takes over every current
+// infection; later variants start with none until IntroduceVariant seeds
+// them.
+//
+// Once variants exist, the infection and death probabilities use the mean
+// multipliers of the people currently infected, and each new case is
+// attributed to a variant in proportion to its infected count times its
+// transmission multiplier, so more transmissible variants grow their share.
+// Deaths are attributed in proportion to each variant's death multiplier.
+// Variants apply to the single pool, not to regions or spatial mode.
+func (s *Simulation) AddVariant(name string, transmissionMultiplier, deathMultiplier float64) error {
+	if name == "" {
+		return errors.New("variant name must not be empty")
+	}
+	if !validMultiplier(transmissionMultiplier) || !validMultiplier(deathMultiplier) {
+		return fmt.Errorf("variant %q multipliers must be finite and non-negative, got %v and %v",
+			name, transmissionMultiplier, deathMultiplier)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.variantLocked(name) != nil {
+		return fmt.Errorf("variant %q already exists", name)
+	}
+	v := variant{name: name, transmissionMultiplier: transmissionMultiplier, deathMultiplier: deathMultiplier}
+	if len(s.variants) == 0 {
+		v.infected = s.currentInfected
+	}
+	s.variants = append(s.variants, v)
+	return nil
+}
+
+// IntroduceVariant infects up to count susceptible people with the named
+// variant, recording an EventVariant, and returns how many were infected.
+// They count towards TotalInfections.
+func (s *Simulation) IntroduceVariant(name string, count int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.variantLocked(name)
+	if v == nil {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownVariant, name)
+	}
+	count = s.infectSusceptiblesLocked(max(count, 0))
+	s.currentInfected += count
+	s.totalInfections += count
+	v.infected += count
+	v.infections += count
+	s.recordEventLocked(Event{
+		Tick:    s.tick,
+		Kind:    EventVariant,
+		Count:   count,
+		Message: fmt.Sprintf("variant %s introduced with %d infections", name, count),
+	})
+	return count, nil
+}
+
+// Variants returns every variant in the order they were added, or nil when
+// none have been registered.
+func (s *Simulation) Variants() []VariantSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.variantSnapshotsLocked()
+}
+
+func validMultiplier(m float64) bool {
+	return m >= 0 && !math.IsInf(m, 0)
+}
+
+func (s *Simulation) variantLocked(name string) *variant {
+	for i := range s.variants {
+		if s.variants[i].name == name {
+			return &s.variants[i]
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) variantSnapshotsLocked() []VariantSnapshot {
+	if len(s.variants) == 0 {
+		return nil
+	}
+
+	snapshots := make([]VariantSnapshot, len(s.variants))
+	for i, v := range s.variants {
+		snapshots[i] = VariantSnapshot{
+			Name:                   v.name,
+			TransmissionMultiplier: v.transmissionMultiplier,
+			DeathMultiplier:        v.deathMultiplier,
+			CurrentInfected:        v.infected,
+			TotalInfections:        v.infections,
+		}
+	}
+	return snapshots
+}
+
+// variantMultiplierLocked is the infected-weighted mean of a variant
+// multiplier, or 1 when there are no variants or nobody is infected.
+func (s *Simulation) variantMultiplierLocked(multiplier func(v *variant) float64) float64 {
+	weighted, infected := 0.0, 0
+	for i := range s.variants {
+		weighted += float64(s.variants[i].infected) * multiplier(&s.variants[i])
+		infected += s.variants[i].infected
+	}
+	if infected == 0 {
+		return 1
+	}
+	return weighted / float64(infected)
+}
+
+func transmissionMultiplier(v *variant) float64 { return v.transmissionMultiplier }
+
+func deathMultiplier(v *variant) float64 { return v.deathMultiplier }
+
+// reconcileVariantsLocked brings the variant counts in line with the infected
+// count after it changed. New infections are attributed by infected count
+// times transmission multiplier; people leaving are drawn by infected count,
+// with the first deaths of them weighted by the death multiplier.
+func (s *Simulation) reconcileVariantsLocked(deaths int) {
+	if len(s.variants) == 0 {
+		return
+	}
+
+	total := 0
+	for _, v := range s.variants {
+		total += v.infected
+	}
+	weights := make([]float64, len(s.variants))
+	for ; total < s.currentInfected; total++ {
+		for i, v := range s.variants {
+			weights[i] = float64(v.infected) * v.transmissionMultiplier
+		}
+		// With nobody infected yet, a case comes from outside: pick by
+		// transmissibility alone.
+		if sumWeights(weights) == 0 {
+			for i, v := range s.variants {
+				weights[i] = v.transmissionMultiplier
+			}
+		}
+		if sumWeights(weights) == 0 {
+			for i := range weights {
+				weights[i] = 1
+			}
+		}
+		picked := &s.variants[s.pickWeightedLocked(weights)]
+		picked.infected++
+		picked.infections++
+	}
+	for ; total > s.currentInfected; total-- {
+		for i, v := range s.variants {
+			weights[i] = float64(v.infected)
+			if deaths > 0 {
+				weights[i] *= v.deathMultiplier
+			}
+		}
+		if sumWeights(weights) == 0 {
+			for i, v := range s.variants {
+				weights[i] = float64(v.infected)
+			}
+		}
+		s.variants[s.pickWeightedLocked(weights)].infected--
+		deaths--
+	}
+}
+
+// resetVariantsLocked hands every current infection back to the first
+// variant, for a run that starts over.
+func (s *Simulation) resetVariantsLocked() {
+	for i := range s.variants {
+		s.variants[i].infected, s.variants[i].infections = 0, 0
+	}
+	if len(s.variants) > 0 {
+		s.variants[0].infected = s.currentInfected
+	}
+}
+
+func sumWeights(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
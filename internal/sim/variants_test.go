@@ -0,0 +1,55 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFasterVariantTakesOver(t *testing.T) {
+	s := NewWithSeed(0.2, 12)
+	s.SetPopulation(0)
+	s.SetRecoveryRate(0.1)
+	if err := s.AddVariant("original", 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.AddVariant("delta", 2, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := s.IntroduceVariant("delta", 10); err != nil || got != 10 {
+		t.Fatalf("expected 10 delta infections, got %d (%v)", got, err)
+	}
+
+	state := s.Snapshot()
+	if len(state.Variants) != 2 || state.Variants[0].CurrentInfected != 10 || state.Variants[1].CurrentInfected != 10 {
+		t.Fatalf("expected an even split to start, got %+v", state.Variants)
+	}
+
+	for i := 0; i < 30; i++ {
+		state = s.Step()
+		if got := state.Variants[0].CurrentInfected + state.Variants[1].CurrentInfected; got != state.CurrentInfected {
+			t.Fatalf("tick %d: variant counts add up to %d, want %d", state.Tick, got, state.CurrentInfected)
+		}
+	}
+	if original, delta := state.Variants[0].CurrentInfected, state.Variants[1].CurrentInfected; delta <= original*2 {
+		t.Fatalf("expected the more transmissible variant to dominate, got %d original and %d delta", original, delta)
+	}
+}
+
+func TestAddVariantRejectsBadInput(t *testing.T) {
+	s := NewWithSeed(0.2, 12)
+	if err := s.AddVariant("", 1, 1); err == nil {
+		t.Fatal("expected an empty name to be rejected")
+	}
+	if err := s.AddVariant("bad", -1, 1); err == nil {
+		t.Fatal("expected a negative multiplier to be rejected")
+	}
+	if err := s.AddVariant("original", 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.AddVariant("original", 1, 1); err == nil {
+		t.Fatal("expected a duplicate name to be rejected")
+	}
+	if _, err := s.IntroduceVariant("omicron", 1); !errors.Is(err, ErrUnknownVariant) {
+		t.Fatalf("expected ErrUnknownVariant, got %v", err)
+	}
+}
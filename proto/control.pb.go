@@ -82,7 +82,23 @@ type ControlUpdate struct {
 	// lockdown_enabled toggles reduced movement speed for agents.
 	LockdownEnabled bool `protobuf:"varint,2,opt,name=lockdown_enabled,json=lockdownEnabled,proto3" json:"lockdown_enabled,omitempty"`
 	// hospital encapsulates capacity and overload parameters.
-	Hospital      *HospitalParameters `protobuf:"bytes,3,opt,name=hospital,proto3" json:"hospital,omitempty"`
+	Hospital *HospitalParameters `protobuf:"bytes,3,opt,name=hospital,proto3" json:"hospital,omitempty"`
+	// interaction_variance scales the randomness of per-tick contact counts; unset keeps the current value.
+	InteractionVariance *float64 `protobuf:"fixed64,4,opt,name=interaction_variance,json=interactionVariance,proto3,oneof" json:"interaction_variance,omitempty"`
+	// expected_version is the ControlState.state_version this update was based on. When set and stale,
+	// the server rejects the update instead of overwriting another operator's change.
+	ExpectedVersion *uint64 `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3,oneof" json:"expected_version,omitempty"`
+	// tick_interval_ms is the wall-clock time between ticks, at least 10ms; unset keeps the current pace.
+	TickIntervalMs *int64 `protobuf:"varint,6,opt,name=tick_interval_ms,json=tickIntervalMs,proto3,oneof" json:"tick_interval_ms,omitempty"`
+	// import_rate is the mean number of outside infections arriving each tick, at least 0; unset keeps the current rate.
+	ImportRate *float64 `protobuf:"fixed64,7,opt,name=import_rate,json=importRate,proto3,oneof" json:"import_rate,omitempty"`
+	// infection_radius is the distance within which agents infect each other in spatial mode, a mechanistic
+	// distancing control; 0 turns spatial mode off and unset keeps the current radius.
+	InfectionRadius *float64 `protobuf:"fixed64,8,opt,name=infection_radius,json=infectionRadius,proto3,oneof" json:"infection_radius,omitempty"`
+	// population is the total number of people. Growing it adds susceptible people and shrinking it removes
+	// them; the server rejects a population too small for everyone who is not susceptible. Unset keeps the
+	// current population and 0 removes the limit.
+	Population    *int32 `protobuf:"varint,9,opt,name=population,proto3,oneof" json:"population,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -138,6 +154,48 @@ func (x *ControlUpdate) GetHospital() *HospitalParameters {
 	return nil
 }
 
+func (x *ControlUpdate) GetInteractionVariance() float64 {
+	if x != nil && x.InteractionVariance != nil {
+		return *x.InteractionVariance
+	}
+	return 0
+}
+
+func (x *ControlUpdate) GetExpectedVersion() uint64 {
+	if x != nil && x.ExpectedVersion != nil {
+		return *x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *ControlUpdate) GetTickIntervalMs() int64 {
+	if x != nil && x.TickIntervalMs != nil {
+		return *x.TickIntervalMs
+	}
+	return 0
+}
+
+func (x *ControlUpdate) GetImportRate() float64 {
+	if x != nil && x.ImportRate != nil {
+		return *x.ImportRate
+	}
+	return 0
+}
+
+func (x *ControlUpdate) GetInfectionRadius() float64 {
+	if x != nil && x.InfectionRadius != nil {
+		return *x.InfectionRadius
+	}
+	return 0
+}
+
+func (x *ControlUpdate) GetPopulation() int32 {
+	if x != nil && x.Population != nil {
+		return *x.Population
+	}
+	return 0
+}
+
 type ControlState struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// settings reflects the current control values applied on the server.
@@ -154,8 +212,32 @@ type ControlState struct {
 	SpeedModifier float64 `protobuf:"fixed64,6,opt,name=speed_modifier,json=speedModifier,proto3" json:"speed_modifier,omitempty"`
 	// capacity_utilization expresses how much of the configured capacity is currently used.
 	CapacityUtilization float64 `protobuf:"fixed64,7,opt,name=capacity_utilization,json=capacityUtilization,proto3" json:"capacity_utilization,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+	// active_pathogen names the disease profile currently driving the model.
+	ActivePathogen string `protobuf:"bytes,8,opt,name=active_pathogen,json=activePathogen,proto3" json:"active_pathogen,omitempty"`
+	// state_version increases every time the controls change; echo it as ControlUpdate.expected_version.
+	StateVersion uint64 `protobuf:"varint,9,opt,name=state_version,json=stateVersion,proto3" json:"state_version,omitempty"`
+	// tick is the simulation tick this state describes; broadcasts never go backwards.
+	Tick int64 `protobuf:"varint,10,opt,name=tick,proto3" json:"tick,omitempty"`
+	// current_recovered counts people who have recovered and are still immune.
+	CurrentRecovered int32 `protobuf:"varint,11,opt,name=current_recovered,json=currentRecovered,proto3" json:"current_recovered,omitempty"`
+	// rt is the effective reproduction number estimated on the last tick.
+	Rt float64 `protobuf:"fixed64,12,opt,name=rt,proto3" json:"rt,omitempty"`
+	// paused is true while the server holds the epidemic still.
+	Paused bool `protobuf:"varint,13,opt,name=paused,proto3" json:"paused,omitempty"`
+	// current_vaccinated counts people vaccinated and not (yet) infected.
+	CurrentVaccinated int32 `protobuf:"varint,14,opt,name=current_vaccinated,json=currentVaccinated,proto3" json:"current_vaccinated,omitempty"`
+	// variants lists each registered variant; empty until one is introduced.
+	Variants []*VariantState `protobuf:"bytes,15,rep,name=variants,proto3" json:"variants,omitempty"`
+	// imported counts the outside infections that arrived on the last tick.
+	Imported int32 `protobuf:"varint,16,opt,name=imported,proto3" json:"imported,omitempty"`
+	// current_exposed counts people infected but not yet infectious.
+	CurrentExposed int32 `protobuf:"varint,17,opt,name=current_exposed,json=currentExposed,proto3" json:"current_exposed,omitempty"`
+	// current_susceptible counts people who can still be infected. With current_exposed, current_infected,
+	// current_recovered, and the other compartments it adds up to settings.population, which is reported
+	// there because operators also set it through ControlUpdate.
+	CurrentSusceptible int32 `protobuf:"varint,18,opt,name=current_susceptible,json=currentSusceptible,proto3" json:"current_susceptible,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *ControlState) Reset() {
@@ -237,30 +319,109 @@ func (x *ControlState) GetCapacityUtilization() float64 {
 	return 0
 }
 
-type ControlAck struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Optional informational text returned after applying a client update.
-	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	// state contains the server's current control state for synchronization.
-	State         *ControlState `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ControlState) GetActivePathogen() string {
+	if x != nil {
+		return x.ActivePathogen
+	}
+	return ""
 }
 
-func (x *ControlAck) Reset() {
-	*x = ControlAck{}
+func (x *ControlState) GetStateVersion() uint64 {
+	if x != nil {
+		return x.StateVersion
+	}
+	return 0
+}
+
+func (x *ControlState) GetTick() int64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *ControlState) GetCurrentRecovered() int32 {
+	if x != nil {
+		return x.CurrentRecovered
+	}
+	return 0
+}
+
+func (x *ControlState) GetRt() float64 {
+	if x != nil {
+		return x.Rt
+	}
+	return 0
+}
+
+func (x *ControlState) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *ControlState) GetCurrentVaccinated() int32 {
+	if x != nil {
+		return x.CurrentVaccinated
+	}
+	return 0
+}
+
+func (x *ControlState) GetVariants() []*VariantState {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *ControlState) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ControlState) GetCurrentExposed() int32 {
+	if x != nil {
+		return x.CurrentExposed
+	}
+	return 0
+}
+
+func (x *ControlState) GetCurrentSusceptible() int32 {
+	if x != nil {
+		return x.CurrentSusceptible
+	}
+	return 0
+}
+
+// VariantState reports one pathogen variant.
+type VariantState struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Name                   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	TransmissionMultiplier float64                `protobuf:"fixed64,2,opt,name=transmission_multiplier,json=transmissionMultiplier,proto3" json:"transmission_multiplier,omitempty"`
+	DeathMultiplier        float64                `protobuf:"fixed64,3,opt,name=death_multiplier,json=deathMultiplier,proto3" json:"death_multiplier,omitempty"`
+	CurrentInfected        int32                  `protobuf:"varint,4,opt,name=current_infected,json=currentInfected,proto3" json:"current_infected,omitempty"`
+	TotalInfections        int32                  `protobuf:"varint,5,opt,name=total_infections,json=totalInfections,proto3" json:"total_infections,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *VariantState) Reset() {
+	*x = VariantState{}
 	mi := &file_proto_control_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ControlAck) String() string {
+func (x *VariantState) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ControlAck) ProtoMessage() {}
+func (*VariantState) ProtoMessage() {}
 
-func (x *ControlAck) ProtoReflect() protoreflect.Message {
+func (x *VariantState) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_control_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -272,47 +433,73 @@ func (x *ControlAck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ControlAck.ProtoReflect.Descriptor instead.
-func (*ControlAck) Descriptor() ([]byte, []int) {
+// Deprecated: Use VariantState.ProtoReflect.Descriptor instead.
+func (*VariantState) Descriptor() ([]byte, []int) {
 	return file_proto_control_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ControlAck) GetMessage() string {
+func (x *VariantState) GetName() string {
 	if x != nil {
-		return x.Message
+		return x.Name
 	}
 	return ""
 }
 
-func (x *ControlAck) GetState() *ControlState {
+func (x *VariantState) GetTransmissionMultiplier() float64 {
 	if x != nil {
-		return x.State
+		return x.TransmissionMultiplier
 	}
-	return nil
+	return 0
 }
 
-type ControlError struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Human-friendly description of why an update failed.
-	Message       string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+func (x *VariantState) GetDeathMultiplier() float64 {
+	if x != nil {
+		return x.DeathMultiplier
+	}
+	return 0
+}
+
+func (x *VariantState) GetCurrentInfected() int32 {
+	if x != nil {
+		return x.CurrentInfected
+	}
+	return 0
+}
+
+func (x *VariantState) GetTotalInfections() int32 {
+	if x != nil {
+		return x.TotalInfections
+	}
+	return 0
+}
+
+// ControlDelta is a broadcast carrying only what changed since the state the
+// client last received. Apply it to that state: each top-level ControlState
+// field named in changed_fields takes its value from values, which is the
+// default when the field is unset there. Every other field keeps its value.
+// Clients receive a full ControlState on connect and periodically after that.
+type ControlDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        *ControlState          `protobuf:"bytes,1,opt,name=values,proto3" json:"values,omitempty"`
+	ChangedFields []string               `protobuf:"bytes,2,rep,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ControlError) Reset() {
-	*x = ControlError{}
+func (x *ControlDelta) Reset() {
+	*x = ControlDelta{}
 	mi := &file_proto_control_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ControlError) String() string {
+func (x *ControlDelta) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ControlError) ProtoMessage() {}
+func (*ControlDelta) ProtoMessage() {}
 
-func (x *ControlError) ProtoReflect() protoreflect.Message {
+func (x *ControlDelta) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_control_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -324,45 +511,51 @@ func (x *ControlError) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ControlError.ProtoReflect.Descriptor instead.
-func (*ControlError) Descriptor() ([]byte, []int) {
+// Deprecated: Use ControlDelta.ProtoReflect.Descriptor instead.
+func (*ControlDelta) Descriptor() ([]byte, []int) {
 	return file_proto_control_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ControlError) GetMessage() string {
+func (x *ControlDelta) GetValues() *ControlState {
 	if x != nil {
-		return x.Message
+		return x.Values
 	}
-	return ""
+	return nil
 }
 
-type ControlMessage struct {
+func (x *ControlDelta) GetChangedFields() []string {
+	if x != nil {
+		return x.ChangedFields
+	}
+	return nil
+}
+
+type ControlAck struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Control:
-	//
-	//	*ControlMessage_Update
-	//	*ControlMessage_State
-	//	*ControlMessage_Ack
-	//	*ControlMessage_Error
-	Control       isControlMessage_Control `protobuf_oneof:"control"`
+	// Optional informational text returned after applying a client update.
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// state contains the server's current control state for synchronization.
+	State *ControlState `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	// warnings lists the out-of-range update values that were clamped rather than rejected.
+	Warnings      []*ControlClampWarning `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ControlMessage) Reset() {
-	*x = ControlMessage{}
+func (x *ControlAck) Reset() {
+	*x = ControlAck{}
 	mi := &file_proto_control_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ControlMessage) String() string {
+func (x *ControlAck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ControlMessage) ProtoMessage() {}
+func (*ControlAck) ProtoMessage() {}
 
-func (x *ControlMessage) ProtoReflect() protoreflect.Message {
+func (x *ControlAck) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_control_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -374,94 +567,1328 @@ func (x *ControlMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ControlMessage.ProtoReflect.Descriptor instead.
-func (*ControlMessage) Descriptor() ([]byte, []int) {
+// Deprecated: Use ControlAck.ProtoReflect.Descriptor instead.
+func (*ControlAck) Descriptor() ([]byte, []int) {
 	return file_proto_control_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *ControlMessage) GetControl() isControlMessage_Control {
+func (x *ControlAck) GetMessage() string {
 	if x != nil {
-		return x.Control
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-func (x *ControlMessage) GetUpdate() *ControlUpdate {
+func (x *ControlAck) GetState() *ControlState {
 	if x != nil {
-		if x, ok := x.Control.(*ControlMessage_Update); ok {
-			return x.Update
-		}
+		return x.State
 	}
 	return nil
 }
 
-func (x *ControlMessage) GetState() *ControlState {
+func (x *ControlAck) GetWarnings() []*ControlClampWarning {
 	if x != nil {
-		if x, ok := x.Control.(*ControlMessage_State); ok {
-			return x.State
-		}
+		return x.Warnings
 	}
 	return nil
 }
 
-func (x *ControlMessage) GetAck() *ControlAck {
+type ControlClampWarning struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// field is the path of the clamped ControlUpdate field, e.g. "hospital.capacity".
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// requested is the value the client sent.
+	Requested float64 `protobuf:"fixed64,2,opt,name=requested,proto3" json:"requested,omitempty"`
+	// applied is the value the server used instead.
+	Applied       float64 `protobuf:"fixed64,3,opt,name=applied,proto3" json:"applied,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlClampWarning) Reset() {
+	*x = ControlClampWarning{}
+	mi := &file_proto_control_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlClampWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlClampWarning) ProtoMessage() {}
+
+func (x *ControlClampWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[6]
 	if x != nil {
-		if x, ok := x.Control.(*ControlMessage_Ack); ok {
-			return x.Ack
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *ControlMessage) GetError() *ControlError {
+// Deprecated: Use ControlClampWarning.ProtoReflect.Descriptor instead.
+func (*ControlClampWarning) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ControlClampWarning) GetField() string {
 	if x != nil {
-		if x, ok := x.Control.(*ControlMessage_Error); ok {
-			return x.Error
-		}
+		return x.Field
 	}
-	return nil
+	return ""
 }
 
-type isControlMessage_Control interface {
-	isControlMessage_Control()
+func (x *ControlClampWarning) GetRequested() float64 {
+	if x != nil {
+		return x.Requested
+	}
+	return 0
 }
 
-type ControlMessage_Update struct {
-	Update *ControlUpdate `protobuf:"bytes,1,opt,name=update,proto3,oneof"`
+func (x *ControlClampWarning) GetApplied() float64 {
+	if x != nil {
+		return x.Applied
+	}
+	return 0
 }
 
-type ControlMessage_State struct {
-	State *ControlState `protobuf:"bytes,2,opt,name=state,proto3,oneof"`
+type ControlFieldError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// field is the path of the offending ControlUpdate field, e.g. "hospital.capacity".
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// reason explains why the value was rejected.
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type ControlMessage_Ack struct {
-	Ack *ControlAck `protobuf:"bytes,3,opt,name=ack,proto3,oneof"`
+func (x *ControlFieldError) Reset() {
+	*x = ControlFieldError{}
+	mi := &file_proto_control_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type ControlMessage_Error struct {
-	Error *ControlError `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+func (x *ControlFieldError) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ControlMessage_Update) isControlMessage_Control() {}
+func (*ControlFieldError) ProtoMessage() {}
 
-func (*ControlMessage_State) isControlMessage_Control() {}
+func (x *ControlFieldError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-func (*ControlMessage_Ack) isControlMessage_Control() {}
+// Deprecated: Use ControlFieldError.ProtoReflect.Descriptor instead.
+func (*ControlFieldError) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{7}
+}
 
-func (*ControlMessage_Error) isControlMessage_Control() {}
+func (x *ControlFieldError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
 
-var File_proto_control_proto protoreflect.FileDescriptor
+func (x *ControlFieldError) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
 
-const file_proto_control_proto_rawDesc = "" +
-	"\n" +
-	"\x13proto/control.proto\x12\tpandemica\"u\n" +
-	"\x12HospitalParameters\x12\x1a\n" +
-	"\bcapacity\x18\x01 \x01(\x05R\bcapacity\x12C\n" +
-	"\x1edeath_rate_overload_multiplier\x18\x02 \x01(\x01R\x1bdeathRateOverloadMultiplier\"\xa2\x01\n" +
+type ControlError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Human-friendly description of why an update failed.
+	Message string `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// fields lists every invalid value when an update fails validation.
+	Fields        []*ControlFieldError `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlError) Reset() {
+	*x = ControlError{}
+	mi := &file_proto_control_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlError) ProtoMessage() {}
+
+func (x *ControlError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlError.ProtoReflect.Descriptor instead.
+func (*ControlError) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ControlError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ControlError) GetFields() []*ControlFieldError {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type ControlSelectPathogen struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name of a pathogen profile registered on the server.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlSelectPathogen) Reset() {
+	*x = ControlSelectPathogen{}
+	mi := &file_proto_control_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlSelectPathogen) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlSelectPathogen) ProtoMessage() {}
+
+func (x *ControlSelectPathogen) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlSelectPathogen.ProtoReflect.Descriptor instead.
+func (*ControlSelectPathogen) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ControlSelectPathogen) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ControlEventsSince struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tick requests every buffered event recorded after this tick.
+	Tick          int64 `protobuf:"varint,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlEventsSince) Reset() {
+	*x = ControlEventsSince{}
+	mi := &file_proto_control_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlEventsSince) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlEventsSince) ProtoMessage() {}
+
+func (x *ControlEventsSince) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlEventsSince.ProtoReflect.Descriptor instead.
+func (*ControlEventsSince) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ControlEventsSince) GetTick() int64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+type ControlEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tick  int64                  `protobuf:"varint,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	// kind is a short machine-readable label such as "import".
+	Kind          string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Count         int32  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlEvent) Reset() {
+	*x = ControlEvent{}
+	mi := &file_proto_control_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlEvent) ProtoMessage() {}
+
+func (x *ControlEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlEvent.ProtoReflect.Descriptor instead.
+func (*ControlEvent) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ControlEvent) GetTick() int64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *ControlEvent) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ControlEvent) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ControlEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ControlEvents struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// events lists the requested events in chronological order.
+	Events        []*ControlEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlEvents) Reset() {
+	*x = ControlEvents{}
+	mi := &file_proto_control_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlEvents) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlEvents) ProtoMessage() {}
+
+func (x *ControlEvents) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlEvents.ProtoReflect.Descriptor instead.
+func (*ControlEvents) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ControlEvents) GetEvents() []*ControlEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ControlLoadScenario struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name of a built-in scenario such as "flu-season".
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlLoadScenario) Reset() {
+	*x = ControlLoadScenario{}
+	mi := &file_proto_control_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlLoadScenario) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlLoadScenario) ProtoMessage() {}
+
+func (x *ControlLoadScenario) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlLoadScenario.ProtoReflect.Descriptor instead.
+func (*ControlLoadScenario) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ControlLoadScenario) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ControlLoadConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// config_json is a complete scenario config in the JSON form served by
+	// GET /api/config.
+	ConfigJson    string `protobuf:"bytes,1,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlLoadConfig) Reset() {
+	*x = ControlLoadConfig{}
+	mi := &file_proto_control_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlLoadConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlLoadConfig) ProtoMessage() {}
+
+func (x *ControlLoadConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlLoadConfig.ProtoReflect.Descriptor instead.
+func (*ControlLoadConfig) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ControlLoadConfig) GetConfigJson() string {
+	if x != nil {
+		return x.ConfigJson
+	}
+	return ""
+}
+
+type ControlRandState struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// seed the random stream was last started from. Requests leave this empty.
+	Seed int64 `protobuf:"varint,1,opt,name=seed,proto3" json:"seed,omitempty"`
+	// draws counts the values taken from the stream since it was seeded.
+	Draws uint64 `protobuf:"varint,2,opt,name=draws,proto3" json:"draws,omitempty"`
+	// tracked is false while draws are replayed from a recording.
+	Tracked       bool `protobuf:"varint,3,opt,name=tracked,proto3" json:"tracked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlRandState) Reset() {
+	*x = ControlRandState{}
+	mi := &file_proto_control_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlRandState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlRandState) ProtoMessage() {}
+
+func (x *ControlRandState) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlRandState.ProtoReflect.Descriptor instead.
+func (*ControlRandState) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ControlRandState) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *ControlRandState) GetDraws() uint64 {
+	if x != nil {
+		return x.Draws
+	}
+	return 0
+}
+
+func (x *ControlRandState) GetTracked() bool {
+	if x != nil {
+		return x.Tracked
+	}
+	return false
+}
+
+type ControlClearHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlClearHistory) Reset() {
+	*x = ControlClearHistory{}
+	mi := &file_proto_control_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlClearHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlClearHistory) ProtoMessage() {}
+
+func (x *ControlClearHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlClearHistory.ProtoReflect.Descriptor instead.
+func (*ControlClearHistory) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{16}
+}
+
+// ControlVaccination starts a vaccination campaign at doses_per_tick, or stops it when doses_per_tick
+// is zero. efficacy, when set, is the fraction of infections the vaccine prevents (0.0 - 1.0).
+type ControlVaccination struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DosesPerTick  int32                  `protobuf:"varint,1,opt,name=doses_per_tick,json=dosesPerTick,proto3" json:"doses_per_tick,omitempty"`
+	Efficacy      *float64               `protobuf:"fixed64,2,opt,name=efficacy,proto3,oneof" json:"efficacy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlVaccination) Reset() {
+	*x = ControlVaccination{}
+	mi := &file_proto_control_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlVaccination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlVaccination) ProtoMessage() {}
+
+func (x *ControlVaccination) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlVaccination.ProtoReflect.Descriptor instead.
+func (*ControlVaccination) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ControlVaccination) GetDosesPerTick() int32 {
+	if x != nil {
+		return x.DosesPerTick
+	}
+	return 0
+}
+
+func (x *ControlVaccination) GetEfficacy() float64 {
+	if x != nil && x.Efficacy != nil {
+		return *x.Efficacy
+	}
+	return 0
+}
+
+// ControlIntroduceVariant infects count susceptible people with the named variant, registering it first
+// with the given multipliers (1.0 when unset) if it is new. The infections already circulating become the
+// original variant, named after the active pathogen, the first time a variant is introduced.
+type ControlIntroduceVariant struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Name                   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	TransmissionMultiplier *float64               `protobuf:"fixed64,2,opt,name=transmission_multiplier,json=transmissionMultiplier,proto3,oneof" json:"transmission_multiplier,omitempty"`
+	DeathMultiplier        *float64               `protobuf:"fixed64,3,opt,name=death_multiplier,json=deathMultiplier,proto3,oneof" json:"death_multiplier,omitempty"`
+	Count                  int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ControlIntroduceVariant) Reset() {
+	*x = ControlIntroduceVariant{}
+	mi := &file_proto_control_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlIntroduceVariant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlIntroduceVariant) ProtoMessage() {}
+
+func (x *ControlIntroduceVariant) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlIntroduceVariant.ProtoReflect.Descriptor instead.
+func (*ControlIntroduceVariant) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ControlIntroduceVariant) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ControlIntroduceVariant) GetTransmissionMultiplier() float64 {
+	if x != nil && x.TransmissionMultiplier != nil {
+		return *x.TransmissionMultiplier
+	}
+	return 0
+}
+
+func (x *ControlIntroduceVariant) GetDeathMultiplier() float64 {
+	if x != nil && x.DeathMultiplier != nil {
+		return *x.DeathMultiplier
+	}
+	return 0
+}
+
+func (x *ControlIntroduceVariant) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// ControlPause pauses the run when paused is true and resumes it otherwise.
+type ControlPause struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paused        bool                   `protobuf:"varint,1,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlPause) Reset() {
+	*x = ControlPause{}
+	mi := &file_proto_control_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlPause) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlPause) ProtoMessage() {}
+
+func (x *ControlPause) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlPause.ProtoReflect.Descriptor instead.
+func (*ControlPause) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ControlPause) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+// ControlReset restarts the simulation from its starting state, keeping its
+// settings.
+type ControlReset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlReset) Reset() {
+	*x = ControlReset{}
+	mi := &file_proto_control_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlReset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlReset) ProtoMessage() {}
+
+func (x *ControlReset) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlReset.ProtoReflect.Descriptor instead.
+func (*ControlReset) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{20}
+}
+
+type ControlAggregate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// window is how many of the most recent ticks to summarise; zero or more
+	// than the recorded history covers all of it.
+	Window        int32 `protobuf:"varint,1,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlAggregate) Reset() {
+	*x = ControlAggregate{}
+	mi := &file_proto_control_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlAggregate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlAggregate) ProtoMessage() {}
+
+func (x *ControlAggregate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlAggregate.ProtoReflect.Descriptor instead.
+func (*ControlAggregate) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ControlAggregate) GetWindow() int32 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+type ControlAggregates struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ticks is the number of recorded ticks summarised; zero without history.
+	Ticks        int32   `protobuf:"varint,1,opt,name=ticks,proto3" json:"ticks,omitempty"`
+	FromTick     int64   `protobuf:"varint,2,opt,name=from_tick,json=fromTick,proto3" json:"from_tick,omitempty"`
+	ToTick       int64   `protobuf:"varint,3,opt,name=to_tick,json=toTick,proto3" json:"to_tick,omitempty"`
+	MeanInfected float64 `protobuf:"fixed64,4,opt,name=mean_infected,json=meanInfected,proto3" json:"mean_infected,omitempty"`
+	PeakInfected int32   `protobuf:"varint,5,opt,name=peak_infected,json=peakInfected,proto3" json:"peak_infected,omitempty"`
+	PeakTick     int64   `protobuf:"varint,6,opt,name=peak_tick,json=peakTick,proto3" json:"peak_tick,omitempty"`
+	// infections and deaths are the increases across the window.
+	Infections        int32 `protobuf:"varint,7,opt,name=infections,proto3" json:"infections,omitempty"`
+	Deaths            int32 `protobuf:"varint,8,opt,name=deaths,proto3" json:"deaths,omitempty"`
+	TicksOverCapacity int32 `protobuf:"varint,9,opt,name=ticks_over_capacity,json=ticksOverCapacity,proto3" json:"ticks_over_capacity,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ControlAggregates) Reset() {
+	*x = ControlAggregates{}
+	mi := &file_proto_control_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlAggregates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlAggregates) ProtoMessage() {}
+
+func (x *ControlAggregates) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlAggregates.ProtoReflect.Descriptor instead.
+func (*ControlAggregates) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ControlAggregates) GetTicks() int32 {
+	if x != nil {
+		return x.Ticks
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetFromTick() int64 {
+	if x != nil {
+		return x.FromTick
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetToTick() int64 {
+	if x != nil {
+		return x.ToTick
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetMeanInfected() float64 {
+	if x != nil {
+		return x.MeanInfected
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetPeakInfected() int32 {
+	if x != nil {
+		return x.PeakInfected
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetPeakTick() int64 {
+	if x != nil {
+		return x.PeakTick
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetInfections() int32 {
+	if x != nil {
+		return x.Infections
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetDeaths() int32 {
+	if x != nil {
+		return x.Deaths
+	}
+	return 0
+}
+
+func (x *ControlAggregates) GetTicksOverCapacity() int32 {
+	if x != nil {
+		return x.TicksOverCapacity
+	}
+	return 0
+}
+
+type ControlMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Control:
+	//
+	//	*ControlMessage_Update
+	//	*ControlMessage_State
+	//	*ControlMessage_Ack
+	//	*ControlMessage_Error
+	//	*ControlMessage_SelectPathogen
+	//	*ControlMessage_EventsSince
+	//	*ControlMessage_Events
+	//	*ControlMessage_LoadScenario
+	//	*ControlMessage_RandState
+	//	*ControlMessage_ClearHistory
+	//	*ControlMessage_Aggregate
+	//	*ControlMessage_Aggregates
+	//	*ControlMessage_Reset_
+	//	*ControlMessage_LoadConfig
+	//	*ControlMessage_Pause
+	//	*ControlMessage_Vaccination
+	//	*ControlMessage_IntroduceVariant
+	//	*ControlMessage_Delta
+	Control       isControlMessage_Control `protobuf_oneof:"control"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlMessage) Reset() {
+	*x = ControlMessage{}
+	mi := &file_proto_control_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlMessage) ProtoMessage() {}
+
+func (x *ControlMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_control_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlMessage.ProtoReflect.Descriptor instead.
+func (*ControlMessage) Descriptor() ([]byte, []int) {
+	return file_proto_control_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ControlMessage) GetControl() isControlMessage_Control {
+	if x != nil {
+		return x.Control
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetUpdate() *ControlUpdate {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Update); ok {
+			return x.Update
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetState() *ControlState {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_State); ok {
+			return x.State
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetAck() *ControlAck {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetError() *ControlError {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetSelectPathogen() *ControlSelectPathogen {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_SelectPathogen); ok {
+			return x.SelectPathogen
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetEventsSince() *ControlEventsSince {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_EventsSince); ok {
+			return x.EventsSince
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetEvents() *ControlEvents {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Events); ok {
+			return x.Events
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetLoadScenario() *ControlLoadScenario {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_LoadScenario); ok {
+			return x.LoadScenario
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetRandState() *ControlRandState {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_RandState); ok {
+			return x.RandState
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetClearHistory() *ControlClearHistory {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_ClearHistory); ok {
+			return x.ClearHistory
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetAggregate() *ControlAggregate {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Aggregate); ok {
+			return x.Aggregate
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetAggregates() *ControlAggregates {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Aggregates); ok {
+			return x.Aggregates
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetReset_() *ControlReset {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Reset_); ok {
+			return x.Reset_
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetLoadConfig() *ControlLoadConfig {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_LoadConfig); ok {
+			return x.LoadConfig
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetPause() *ControlPause {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Pause); ok {
+			return x.Pause
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetVaccination() *ControlVaccination {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Vaccination); ok {
+			return x.Vaccination
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetIntroduceVariant() *ControlIntroduceVariant {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_IntroduceVariant); ok {
+			return x.IntroduceVariant
+		}
+	}
+	return nil
+}
+
+func (x *ControlMessage) GetDelta() *ControlDelta {
+	if x != nil {
+		if x, ok := x.Control.(*ControlMessage_Delta); ok {
+			return x.Delta
+		}
+	}
+	return nil
+}
+
+type isControlMessage_Control interface {
+	isControlMessage_Control()
+}
+
+type ControlMessage_Update struct {
+	Update *ControlUpdate `protobuf:"bytes,1,opt,name=update,proto3,oneof"`
+}
+
+type ControlMessage_State struct {
+	State *ControlState `protobuf:"bytes,2,opt,name=state,proto3,oneof"`
+}
+
+type ControlMessage_Ack struct {
+	Ack *ControlAck `protobuf:"bytes,3,opt,name=ack,proto3,oneof"`
+}
+
+type ControlMessage_Error struct {
+	Error *ControlError `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+type ControlMessage_SelectPathogen struct {
+	SelectPathogen *ControlSelectPathogen `protobuf:"bytes,5,opt,name=select_pathogen,json=selectPathogen,proto3,oneof"`
+}
+
+type ControlMessage_EventsSince struct {
+	EventsSince *ControlEventsSince `protobuf:"bytes,6,opt,name=events_since,json=eventsSince,proto3,oneof"`
+}
+
+type ControlMessage_Events struct {
+	Events *ControlEvents `protobuf:"bytes,7,opt,name=events,proto3,oneof"`
+}
+
+type ControlMessage_LoadScenario struct {
+	LoadScenario *ControlLoadScenario `protobuf:"bytes,8,opt,name=load_scenario,json=loadScenario,proto3,oneof"`
+}
+
+type ControlMessage_RandState struct {
+	// rand_state is sent empty to ask for the server's random state and echoed back filled in.
+	RandState *ControlRandState `protobuf:"bytes,9,opt,name=rand_state,json=randState,proto3,oneof"`
+}
+
+type ControlMessage_ClearHistory struct {
+	// clear_history empties the server's recorded history; the model keeps running unchanged.
+	ClearHistory *ControlClearHistory `protobuf:"bytes,10,opt,name=clear_history,json=clearHistory,proto3,oneof"`
+}
+
+type ControlMessage_Aggregate struct {
+	Aggregate *ControlAggregate `protobuf:"bytes,11,opt,name=aggregate,proto3,oneof"`
+}
+
+type ControlMessage_Aggregates struct {
+	Aggregates *ControlAggregates `protobuf:"bytes,12,opt,name=aggregates,proto3,oneof"`
+}
+
+type ControlMessage_Reset_ struct {
+	Reset_ *ControlReset `protobuf:"bytes,13,opt,name=reset,proto3,oneof"`
+}
+
+type ControlMessage_LoadConfig struct {
+	LoadConfig *ControlLoadConfig `protobuf:"bytes,14,opt,name=load_config,json=loadConfig,proto3,oneof"`
+}
+
+type ControlMessage_Pause struct {
+	Pause *ControlPause `protobuf:"bytes,15,opt,name=pause,proto3,oneof"`
+}
+
+type ControlMessage_Vaccination struct {
+	Vaccination *ControlVaccination `protobuf:"bytes,16,opt,name=vaccination,proto3,oneof"`
+}
+
+type ControlMessage_IntroduceVariant struct {
+	IntroduceVariant *ControlIntroduceVariant `protobuf:"bytes,17,opt,name=introduce_variant,json=introduceVariant,proto3,oneof"`
+}
+
+type ControlMessage_Delta struct {
+	Delta *ControlDelta `protobuf:"bytes,18,opt,name=delta,proto3,oneof"`
+}
+
+func (*ControlMessage_Update) isControlMessage_Control() {}
+
+func (*ControlMessage_State) isControlMessage_Control() {}
+
+func (*ControlMessage_Ack) isControlMessage_Control() {}
+
+func (*ControlMessage_Error) isControlMessage_Control() {}
+
+func (*ControlMessage_SelectPathogen) isControlMessage_Control() {}
+
+func (*ControlMessage_EventsSince) isControlMessage_Control() {}
+
+func (*ControlMessage_Events) isControlMessage_Control() {}
+
+func (*ControlMessage_LoadScenario) isControlMessage_Control() {}
+
+func (*ControlMessage_RandState) isControlMessage_Control() {}
+
+func (*ControlMessage_ClearHistory) isControlMessage_Control() {}
+
+func (*ControlMessage_Aggregate) isControlMessage_Control() {}
+
+func (*ControlMessage_Aggregates) isControlMessage_Control() {}
+
+func (*ControlMessage_Reset_) isControlMessage_Control() {}
+
+func (*ControlMessage_LoadConfig) isControlMessage_Control() {}
+
+func (*ControlMessage_Pause) isControlMessage_Control() {}
+
+func (*ControlMessage_Vaccination) isControlMessage_Control() {}
+
+func (*ControlMessage_IntroduceVariant) isControlMessage_Control() {}
+
+func (*ControlMessage_Delta) isControlMessage_Control() {}
+
+var File_proto_control_proto protoreflect.FileDescriptor
+
+const file_proto_control_proto_rawDesc = "" +
+	"\n" +
+	"\x13proto/control.proto\x12\tpandemica\"u\n" +
+	"\x12HospitalParameters\x12\x1a\n" +
+	"\bcapacity\x18\x01 \x01(\x05R\bcapacity\x12C\n" +
+	"\x1edeath_rate_overload_multiplier\x18\x02 \x01(\x01R\x1bdeathRateOverloadMultiplier\"\xab\x04\n" +
 	"\rControlUpdate\x12+\n" +
 	"\x11transmission_rate\x18\x01 \x01(\x01R\x10transmissionRate\x12)\n" +
 	"\x10lockdown_enabled\x18\x02 \x01(\bR\x0flockdownEnabled\x129\n" +
-	"\bhospital\x18\x03 \x01(\v2\x1d.pandemica.HospitalParametersR\bhospital\"\xde\x02\n" +
+	"\bhospital\x18\x03 \x01(\v2\x1d.pandemica.HospitalParametersR\bhospital\x126\n" +
+	"\x14interaction_variance\x18\x04 \x01(\x01H\x00R\x13interactionVariance\x88\x01\x01\x12.\n" +
+	"\x10expected_version\x18\x05 \x01(\x04H\x01R\x0fexpectedVersion\x88\x01\x01\x12-\n" +
+	"\x10tick_interval_ms\x18\x06 \x01(\x03H\x02R\x0etickIntervalMs\x88\x01\x01\x12$\n" +
+	"\vimport_rate\x18\a \x01(\x01H\x03R\n" +
+	"importRate\x88\x01\x01\x12.\n" +
+	"\x10infection_radius\x18\b \x01(\x01H\x04R\x0finfectionRadius\x88\x01\x01\x12#\n" +
+	"\n" +
+	"population\x18\t \x01(\x05H\x05R\n" +
+	"population\x88\x01\x01B\x17\n" +
+	"\x15_interaction_varianceB\x13\n" +
+	"\x11_expected_versionB\x13\n" +
+	"\x11_tick_interval_msB\x0e\n" +
+	"\f_import_rateB\x13\n" +
+	"\x11_infection_radiusB\r\n" +
+	"\v_population\"\xef\x05\n" +
 	"\fControlState\x124\n" +
 	"\bsettings\x18\x01 \x01(\v2\x18.pandemica.ControlUpdateR\bsettings\x12)\n" +
 	"\x10current_infected\x18\x02 \x01(\x05R\x0fcurrentInfected\x12>\n" +
@@ -471,18 +1898,116 @@ const file_proto_control_proto_rawDesc = "" +
 	"overloaded\x123\n" +
 	"\x15infection_probability\x18\x05 \x01(\x01R\x14infectionProbability\x12%\n" +
 	"\x0espeed_modifier\x18\x06 \x01(\x01R\rspeedModifier\x121\n" +
-	"\x14capacity_utilization\x18\a \x01(\x01R\x13capacityUtilization\"U\n" +
+	"\x14capacity_utilization\x18\a \x01(\x01R\x13capacityUtilization\x12'\n" +
+	"\x0factive_pathogen\x18\b \x01(\tR\x0eactivePathogen\x12#\n" +
+	"\rstate_version\x18\t \x01(\x04R\fstateVersion\x12\x12\n" +
+	"\x04tick\x18\n" +
+	" \x01(\x03R\x04tick\x12+\n" +
+	"\x11current_recovered\x18\v \x01(\x05R\x10currentRecovered\x12\x0e\n" +
+	"\x02rt\x18\f \x01(\x01R\x02rt\x12\x16\n" +
+	"\x06paused\x18\r \x01(\bR\x06paused\x12-\n" +
+	"\x12current_vaccinated\x18\x0e \x01(\x05R\x11currentVaccinated\x123\n" +
+	"\bvariants\x18\x0f \x03(\v2\x17.pandemica.VariantStateR\bvariants\x12\x1a\n" +
+	"\bimported\x18\x10 \x01(\x05R\bimported\x12'\n" +
+	"\x0fcurrent_exposed\x18\x11 \x01(\x05R\x0ecurrentExposed\x12/\n" +
+	"\x13current_susceptible\x18\x12 \x01(\x05R\x12currentSusceptible\"\xdc\x01\n" +
+	"\fVariantState\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x127\n" +
+	"\x17transmission_multiplier\x18\x02 \x01(\x01R\x16transmissionMultiplier\x12)\n" +
+	"\x10death_multiplier\x18\x03 \x01(\x01R\x0fdeathMultiplier\x12)\n" +
+	"\x10current_infected\x18\x04 \x01(\x05R\x0fcurrentInfected\x12)\n" +
+	"\x10total_infections\x18\x05 \x01(\x05R\x0ftotalInfections\"f\n" +
+	"\fControlDelta\x12/\n" +
+	"\x06values\x18\x01 \x01(\v2\x17.pandemica.ControlStateR\x06values\x12%\n" +
+	"\x0echanged_fields\x18\x02 \x03(\tR\rchangedFields\"\x91\x01\n" +
 	"\n" +
 	"ControlAck\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x12-\n" +
-	"\x05state\x18\x02 \x01(\v2\x17.pandemica.ControlStateR\x05state\"(\n" +
+	"\x05state\x18\x02 \x01(\v2\x17.pandemica.ControlStateR\x05state\x12:\n" +
+	"\bwarnings\x18\x03 \x03(\v2\x1e.pandemica.ControlClampWarningR\bwarnings\"c\n" +
+	"\x13ControlClampWarning\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1c\n" +
+	"\trequested\x18\x02 \x01(\x01R\trequested\x12\x18\n" +
+	"\aapplied\x18\x03 \x01(\x01R\aapplied\"A\n" +
+	"\x11ControlFieldError\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"^\n" +
 	"\fControlError\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"\xdc\x01\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x124\n" +
+	"\x06fields\x18\x02 \x03(\v2\x1c.pandemica.ControlFieldErrorR\x06fields\"+\n" +
+	"\x15ControlSelectPathogen\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"(\n" +
+	"\x12ControlEventsSince\x12\x12\n" +
+	"\x04tick\x18\x01 \x01(\x03R\x04tick\"f\n" +
+	"\fControlEvent\x12\x12\n" +
+	"\x04tick\x18\x01 \x01(\x03R\x04tick\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"@\n" +
+	"\rControlEvents\x12/\n" +
+	"\x06events\x18\x01 \x03(\v2\x17.pandemica.ControlEventR\x06events\")\n" +
+	"\x13ControlLoadScenario\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"4\n" +
+	"\x11ControlLoadConfig\x12\x1f\n" +
+	"\vconfig_json\x18\x01 \x01(\tR\n" +
+	"configJson\"V\n" +
+	"\x10ControlRandState\x12\x12\n" +
+	"\x04seed\x18\x01 \x01(\x03R\x04seed\x12\x14\n" +
+	"\x05draws\x18\x02 \x01(\x04R\x05draws\x12\x18\n" +
+	"\atracked\x18\x03 \x01(\bR\atracked\"\x15\n" +
+	"\x13ControlClearHistory\"h\n" +
+	"\x12ControlVaccination\x12$\n" +
+	"\x0edoses_per_tick\x18\x01 \x01(\x05R\fdosesPerTick\x12\x1f\n" +
+	"\befficacy\x18\x02 \x01(\x01H\x00R\befficacy\x88\x01\x01B\v\n" +
+	"\t_efficacy\"\xe2\x01\n" +
+	"\x17ControlIntroduceVariant\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12<\n" +
+	"\x17transmission_multiplier\x18\x02 \x01(\x01H\x00R\x16transmissionMultiplier\x88\x01\x01\x12.\n" +
+	"\x10death_multiplier\x18\x03 \x01(\x01H\x01R\x0fdeathMultiplier\x88\x01\x01\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05countB\x1a\n" +
+	"\x18_transmission_multiplierB\x13\n" +
+	"\x11_death_multiplier\"&\n" +
+	"\fControlPause\x12\x16\n" +
+	"\x06paused\x18\x01 \x01(\bR\x06paused\"\x0e\n" +
+	"\fControlReset\"*\n" +
+	"\x10ControlAggregate\x12\x16\n" +
+	"\x06window\x18\x01 \x01(\x05R\x06window\"\xae\x02\n" +
+	"\x11ControlAggregates\x12\x14\n" +
+	"\x05ticks\x18\x01 \x01(\x05R\x05ticks\x12\x1b\n" +
+	"\tfrom_tick\x18\x02 \x01(\x03R\bfromTick\x12\x17\n" +
+	"\ato_tick\x18\x03 \x01(\x03R\x06toTick\x12#\n" +
+	"\rmean_infected\x18\x04 \x01(\x01R\fmeanInfected\x12#\n" +
+	"\rpeak_infected\x18\x05 \x01(\x05R\fpeakInfected\x12\x1b\n" +
+	"\tpeak_tick\x18\x06 \x01(\x03R\bpeakTick\x12\x1e\n" +
+	"\n" +
+	"infections\x18\a \x01(\x05R\n" +
+	"infections\x12\x16\n" +
+	"\x06deaths\x18\b \x01(\x05R\x06deaths\x12.\n" +
+	"\x13ticks_over_capacity\x18\t \x01(\x05R\x11ticksOverCapacity\"\xd4\b\n" +
 	"\x0eControlMessage\x122\n" +
 	"\x06update\x18\x01 \x01(\v2\x18.pandemica.ControlUpdateH\x00R\x06update\x12/\n" +
 	"\x05state\x18\x02 \x01(\v2\x17.pandemica.ControlStateH\x00R\x05state\x12)\n" +
 	"\x03ack\x18\x03 \x01(\v2\x15.pandemica.ControlAckH\x00R\x03ack\x12/\n" +
-	"\x05error\x18\x04 \x01(\v2\x17.pandemica.ControlErrorH\x00R\x05errorB\t\n" +
+	"\x05error\x18\x04 \x01(\v2\x17.pandemica.ControlErrorH\x00R\x05error\x12K\n" +
+	"\x0fselect_pathogen\x18\x05 \x01(\v2 .pandemica.ControlSelectPathogenH\x00R\x0eselectPathogen\x12B\n" +
+	"\fevents_since\x18\x06 \x01(\v2\x1d.pandemica.ControlEventsSinceH\x00R\veventsSince\x122\n" +
+	"\x06events\x18\a \x01(\v2\x18.pandemica.ControlEventsH\x00R\x06events\x12E\n" +
+	"\rload_scenario\x18\b \x01(\v2\x1e.pandemica.ControlLoadScenarioH\x00R\floadScenario\x12<\n" +
+	"\n" +
+	"rand_state\x18\t \x01(\v2\x1b.pandemica.ControlRandStateH\x00R\trandState\x12E\n" +
+	"\rclear_history\x18\n" +
+	" \x01(\v2\x1e.pandemica.ControlClearHistoryH\x00R\fclearHistory\x12;\n" +
+	"\taggregate\x18\v \x01(\v2\x1b.pandemica.ControlAggregateH\x00R\taggregate\x12>\n" +
+	"\n" +
+	"aggregates\x18\f \x01(\v2\x1c.pandemica.ControlAggregatesH\x00R\n" +
+	"aggregates\x12/\n" +
+	"\x05reset\x18\r \x01(\v2\x17.pandemica.ControlResetH\x00R\x05reset\x12?\n" +
+	"\vload_config\x18\x0e \x01(\v2\x1c.pandemica.ControlLoadConfigH\x00R\n" +
+	"loadConfig\x12/\n" +
+	"\x05pause\x18\x0f \x01(\v2\x17.pandemica.ControlPauseH\x00R\x05pause\x12A\n" +
+	"\vvaccination\x18\x10 \x01(\v2\x1d.pandemica.ControlVaccinationH\x00R\vvaccination\x12Q\n" +
+	"\x11introduce_variant\x18\x11 \x01(\v2\".pandemica.ControlIntroduceVariantH\x00R\x10introduceVariant\x12/\n" +
+	"\x05delta\x18\x12 \x01(\v2\x17.pandemica.ControlDeltaH\x00R\x05deltaB\t\n" +
 	"\acontrolB\x11Z\x0fpandemica/protob\x06proto3"
 
 var (
@@ -497,28 +2022,65 @@ func file_proto_control_proto_rawDescGZIP() []byte {
 	return file_proto_control_proto_rawDescData
 }
 
-var file_proto_control_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_control_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_proto_control_proto_goTypes = []any{
-	(*HospitalParameters)(nil), // 0: pandemica.HospitalParameters
-	(*ControlUpdate)(nil),      // 1: pandemica.ControlUpdate
-	(*ControlState)(nil),       // 2: pandemica.ControlState
-	(*ControlAck)(nil),         // 3: pandemica.ControlAck
-	(*ControlError)(nil),       // 4: pandemica.ControlError
-	(*ControlMessage)(nil),     // 5: pandemica.ControlMessage
+	(*HospitalParameters)(nil),      // 0: pandemica.HospitalParameters
+	(*ControlUpdate)(nil),           // 1: pandemica.ControlUpdate
+	(*ControlState)(nil),            // 2: pandemica.ControlState
+	(*VariantState)(nil),            // 3: pandemica.VariantState
+	(*ControlDelta)(nil),            // 4: pandemica.ControlDelta
+	(*ControlAck)(nil),              // 5: pandemica.ControlAck
+	(*ControlClampWarning)(nil),     // 6: pandemica.ControlClampWarning
+	(*ControlFieldError)(nil),       // 7: pandemica.ControlFieldError
+	(*ControlError)(nil),            // 8: pandemica.ControlError
+	(*ControlSelectPathogen)(nil),   // 9: pandemica.ControlSelectPathogen
+	(*ControlEventsSince)(nil),      // 10: pandemica.ControlEventsSince
+	(*ControlEvent)(nil),            // 11: pandemica.ControlEvent
+	(*ControlEvents)(nil),           // 12: pandemica.ControlEvents
+	(*ControlLoadScenario)(nil),     // 13: pandemica.ControlLoadScenario
+	(*ControlLoadConfig)(nil),       // 14: pandemica.ControlLoadConfig
+	(*ControlRandState)(nil),        // 15: pandemica.ControlRandState
+	(*ControlClearHistory)(nil),     // 16: pandemica.ControlClearHistory
+	(*ControlVaccination)(nil),      // 17: pandemica.ControlVaccination
+	(*ControlIntroduceVariant)(nil), // 18: pandemica.ControlIntroduceVariant
+	(*ControlPause)(nil),            // 19: pandemica.ControlPause
+	(*ControlReset)(nil),            // 20: pandemica.ControlReset
+	(*ControlAggregate)(nil),        // 21: pandemica.ControlAggregate
+	(*ControlAggregates)(nil),       // 22: pandemica.ControlAggregates
+	(*ControlMessage)(nil),          // 23: pandemica.ControlMessage
 }
 var file_proto_control_proto_depIdxs = []int32{
-	0, // 0: pandemica.ControlUpdate.hospital:type_name -> pandemica.HospitalParameters
-	1, // 1: pandemica.ControlState.settings:type_name -> pandemica.ControlUpdate
-	2, // 2: pandemica.ControlAck.state:type_name -> pandemica.ControlState
-	1, // 3: pandemica.ControlMessage.update:type_name -> pandemica.ControlUpdate
-	2, // 4: pandemica.ControlMessage.state:type_name -> pandemica.ControlState
-	3, // 5: pandemica.ControlMessage.ack:type_name -> pandemica.ControlAck
-	4, // 6: pandemica.ControlMessage.error:type_name -> pandemica.ControlError
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	0,  // 0: pandemica.ControlUpdate.hospital:type_name -> pandemica.HospitalParameters
+	1,  // 1: pandemica.ControlState.settings:type_name -> pandemica.ControlUpdate
+	3,  // 2: pandemica.ControlState.variants:type_name -> pandemica.VariantState
+	2,  // 3: pandemica.ControlDelta.values:type_name -> pandemica.ControlState
+	2,  // 4: pandemica.ControlAck.state:type_name -> pandemica.ControlState
+	6,  // 5: pandemica.ControlAck.warnings:type_name -> pandemica.ControlClampWarning
+	7,  // 6: pandemica.ControlError.fields:type_name -> pandemica.ControlFieldError
+	11, // 7: pandemica.ControlEvents.events:type_name -> pandemica.ControlEvent
+	1,  // 8: pandemica.ControlMessage.update:type_name -> pandemica.ControlUpdate
+	2,  // 9: pandemica.ControlMessage.state:type_name -> pandemica.ControlState
+	5,  // 10: pandemica.ControlMessage.ack:type_name -> pandemica.ControlAck
+	8,  // 11: pandemica.ControlMessage.error:type_name -> pandemica.ControlError
+	9,  // 12: pandemica.ControlMessage.select_pathogen:type_name -> pandemica.ControlSelectPathogen
+	10, // 13: pandemica.ControlMessage.events_since:type_name -> pandemica.ControlEventsSince
+	12, // 14: pandemica.ControlMessage.events:type_name -> pandemica.ControlEvents
+	13, // 15: pandemica.ControlMessage.load_scenario:type_name -> pandemica.ControlLoadScenario
+	15, // 16: pandemica.ControlMessage.rand_state:type_name -> pandemica.ControlRandState
+	16, // 17: pandemica.ControlMessage.clear_history:type_name -> pandemica.ControlClearHistory
+	21, // 18: pandemica.ControlMessage.aggregate:type_name -> pandemica.ControlAggregate
+	22, // 19: pandemica.ControlMessage.aggregates:type_name -> pandemica.ControlAggregates
+	20, // 20: pandemica.ControlMessage.reset:type_name -> pandemica.ControlReset
+	14, // 21: pandemica.ControlMessage.load_config:type_name -> pandemica.ControlLoadConfig
+	19, // 22: pandemica.ControlMessage.pause:type_name -> pandemica.ControlPause
+	17, // 23: pandemica.ControlMessage.vaccination:type_name -> pandemica.ControlVaccination
+	18, // 24: pandemica.ControlMessage.introduce_variant:type_name -> pandemica.ControlIntroduceVariant
+	4,  // 25: pandemica.ControlMessage.delta:type_name -> pandemica.ControlDelta
+	26, // [26:26] is the sub-list for method output_type
+	26, // [26:26] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_proto_control_proto_init() }
@@ -526,11 +2088,28 @@ func file_proto_control_proto_init() {
 	if File_proto_control_proto != nil {
 		return
 	}
-	file_proto_control_proto_msgTypes[5].OneofWrappers = []any{
+	file_proto_control_proto_msgTypes[1].OneofWrappers = []any{}
+	file_proto_control_proto_msgTypes[17].OneofWrappers = []any{}
+	file_proto_control_proto_msgTypes[18].OneofWrappers = []any{}
+	file_proto_control_proto_msgTypes[23].OneofWrappers = []any{
 		(*ControlMessage_Update)(nil),
 		(*ControlMessage_State)(nil),
 		(*ControlMessage_Ack)(nil),
 		(*ControlMessage_Error)(nil),
+		(*ControlMessage_SelectPathogen)(nil),
+		(*ControlMessage_EventsSince)(nil),
+		(*ControlMessage_Events)(nil),
+		(*ControlMessage_LoadScenario)(nil),
+		(*ControlMessage_RandState)(nil),
+		(*ControlMessage_ClearHistory)(nil),
+		(*ControlMessage_Aggregate)(nil),
+		(*ControlMessage_Aggregates)(nil),
+		(*ControlMessage_Reset_)(nil),
+		(*ControlMessage_LoadConfig)(nil),
+		(*ControlMessage_Pause)(nil),
+		(*ControlMessage_Vaccination)(nil),
+		(*ControlMessage_IntroduceVariant)(nil),
+		(*ControlMessage_Delta)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -538,7 +2117,7 @@ func file_proto_control_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_control_proto_rawDesc), len(file_proto_control_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
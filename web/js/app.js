@@ -23,9 +23,12 @@ const interventionMarkers = [];
 let lastPhaseLabel = 'Baseline (1.00x)';
 
 let ControlMessage;
+let ControlState;
 let ControlUpdate;
 let HospitalParameters;
 let socket;
+// lastState is the newest full state, patched by each delta broadcast.
+let lastState;
 let lockdownEnabled = false;
 let hospitalCapacity = Number(capacityInput.value) || 0;
 let overloadMultiplier = Number(overloadInput.value) || 1;
@@ -342,6 +345,22 @@ function applyState(state) {
   });
 }
 
+// applyDelta copies each changed field onto the last full state. Fields
+// absent from the delta's values were reset to their defaults.
+function applyDelta(state, delta) {
+  const next = ControlState.create(state);
+  const values = delta.values || {};
+  (delta.changedFields || []).forEach((name) => {
+    const key = protobuf.util.camelCase(name);
+    if (Object.prototype.hasOwnProperty.call(values, key)) {
+      next[key] = values[key];
+    } else {
+      delete next[key];
+    }
+  });
+  return next;
+}
+
 function connectWebSocket() {
   const protocol = window.location.protocol === 'https:' ? 'wss' : 'ws';
   socket = new WebSocket(`${protocol}://${window.location.host}/ws/control`);
@@ -354,13 +373,22 @@ function connectWebSocket() {
     const data = new Uint8Array(event.data);
     const message = ControlMessage.decode(data);
     if (message.state) {
-      applyState(message.state);
+      lastState = message.state;
+      applyState(lastState);
       setNetworkStatus('Live state synchronized.', false);
     }
+    if (message.delta && lastState) {
+      lastState = applyDelta(lastState, message.delta);
+      applyState(lastState);
+    }
     if (message.ack) {
-      setNetworkStatus(message.ack.message || 'Update acknowledged.', false);
+      const warnings = (message.ack.warnings || [])
+        .map((w) => `${w.field} clamped from ${w.requested} to ${w.applied}`);
+      const status = message.ack.message || 'Update acknowledged.';
+      setNetworkStatus(warnings.length ? `${status} (${warnings.join('; ')})` : status, false);
       if (message.ack.state) {
-        applyState(message.ack.state);
+        lastState = message.ack.state;
+        applyState(lastState);
       }
     }
     if (message.error) {
@@ -376,6 +404,7 @@ function connectWebSocket() {
 async function init() {
   const root = await protobuf.load('/proto/control.proto');
   ControlMessage = root.lookupType('pandemica.ControlMessage');
+  ControlState = root.lookupType('pandemica.ControlState');
   ControlUpdate = root.lookupType('pandemica.ControlUpdate');
   HospitalParameters = root.lookupType('pandemica.HospitalParameters');
   createChart();